@@ -2,96 +2,493 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/cascadeguard/cascadeguard/parser"
+	"github.com/cascadeguard/cascadeguard/progress"
+	"github.com/cascadeguard/cascadeguard/rules"
 )
 
 type CallEdge struct {
-	Source, Target string
-	Timeout        time.Duration
-	Retries        int
-	CircuitBreaker bool
-	Method         string
-	BackoffJitter  bool
+	Source, Target     string
+	Timeout            time.Duration
+	Retries            int
+	CircuitBreaker     bool
+	CBHalfOpenRequests int           // trial requests allowed while half-open; 0 if undeclared
+	CBOpenTimeout      time.Duration // how long the breaker stays open; 0 if undeclared
+	CBCounts           string        // what the breaker counts as a failure: "per-call", "per-attempt" or "" if undeclared
+	CBFailureThreshold int           // failures that open the breaker; 0 if undeclared
+	CBWindow           string        // where failures are counted: "per-request", "shared" or "" if undeclared
+	Method             string
+	Endpoint           string // request path, e.g. "/users/{id}"
+	Kind               string // call purpose, e.g. "health" for health/liveness checks
+	Idempotent         *bool  // explicit declaration; nil means derive from Method
+	BackoffJitter      bool
+	BackoffBase        time.Duration
+	ProcessingTime     time.Duration // target's typical handling time for this call; 0 if undeclared
+	P99                time.Duration // observed 99th percentile latency of this call; 0 if undeclared
+	ReadsFrom          []string
+	Secure             bool    // encrypted and authenticated (e.g. mTLS)
+	CrossesBoundary    bool    // leaves the trust/network boundary
+	MaxConcurrency     int     // in-flight request limit (bulkhead); 0 means unbounded
+	Async              bool    // fire-and-forget, e.g. published to a queue
+	InTransaction      bool    // made while the caller holds a database transaction open
+	Confidence         float64 // 0-1 certainty of this call's config; 0 means unset (fully confident)
+	Label              string  // edge label in rendered graphs; empty means the default
+	Criticality        string  // "low", "medium", "high" or "" if undeclared
+	HonorsRetryAfter   *bool   // waits as Retry-After/backpressure asks before retrying; nil if undeclared
+	Observable         *bool   // traced or metered; nil if undeclared
+	CallProbability    float64 // chance a request to Source makes the call; 0 means always
+	FailureProbability float64 // chance one attempt fails and is retried; 0 if undeclared
 }
 
 type Finding struct {
 	Rule, Severity, Message string
 	Path                    []string
+	// BlastRadius is the number of services at or below the offending call
+	// (the first hop of Path), used to rank findings by impact.
+	BlastRadius int
+	File        string // topology file the finding came from; set in -batch mode
+	Age         string // output.AgeNew or output.AgePreExisting with -compare; empty otherwise
+}
+
+// Service holds per-service attributes declared in the topology.
+type Service struct {
+	Tier                   string
+	Namespace              string // selects per-namespace rule config (Graph.NamespaceConfig)
+	Replicas               int
+	MinProcessingTime      time.Duration // known floor on request handling time
+	AggregateTimeout       time.Duration // overall deadline when fanning out; 0 if none
+	Sources                []string      // files, directories or globs holding its code, relative to the topology
+	Endpoints              []string      // exposed operations, "[METHOD ]/path"
+	Autoscaling            *bool         // nil if undeclared
+	Capacity               int           // requests per second it can serve; 0 if unknown
+	DeterministicErrors    bool          // errors are by design not transient
+	SupportsIdempotencyKey bool          // mutating endpoints deduplicate retries by idempotency key
 }
 
 type Graph struct {
-	Edges []CallEdge
-	Adj   map[string][]CallEdge
+	Edges      []CallEdge
+	Adj        map[string][]CallEdge
+	In         map[string][]CallEdge
+	Services   map[string]Service
+	Enabled    map[string]bool // opt-in rule IDs to run in addition to the defaults
+	TierPolicy rules.TierPolicy
+	Config     RuleConfig // zero fields select each rule's default
+	// NamespaceConfig replaces Config for calls made by services in the
+	// keyed namespace (see Graph.configFor).
+	NamespaceConfig map[string]RuleConfig
+	Progress        progress.Reporter      // nil means no progress reporting
+	Entry           string                 // entry service; empty means every root
+	Plugins         []string               // external checker commands
+	Scopes          map[string]rules.Scope // per-rule graph scope, keyed by rule ID; unset means all
+	Logger          *slog.Logger           // debug trace of rule decisions; nil disables it
+	// MinConfidence downgrades findings whose path uses a call with a lower
+	// Confidence; 0 disables it.
+	MinConfidence float64
+
+	view *ruleView // the calls as the rules see them; built on first use
 }
 
 func NewGraph(edges []CallEdge) *Graph {
 	adj := make(map[string][]CallEdge)
+	in := make(map[string][]CallEdge)
 	for _, e := range edges {
 		adj[e.Source] = append(adj[e.Source], e)
+		in[e.Target] = append(in[e.Target], e)
+	}
+	return &Graph{Edges: edges, Adj: adj, In: in}
+}
+
+// Limits bounds the size of graph the analyzer accepts. Zero means
+// unlimited.
+type Limits struct {
+	MaxNodes int `json:"max_nodes"`
+	MaxEdges int `json:"max_edges"`
+	// MaxPaths bounds the root-to-leaf paths the path rules walk. They grow
+	// exponentially with the depth of a densely connected graph, however
+	// few services and calls it has.
+	MaxPaths int `json:"max_paths"`
+}
+
+// DefaultLimits keep interactive and CI runs from hanging on an accidental
+// multi-thousand-service dump or a deep, densely connected graph.
+var DefaultLimits = Limits{MaxNodes: 2000, MaxEdges: 10000, MaxPaths: 1000000}
+
+// CheckLimits returns a descriptive error when the graph exceeds l.
+func (g *Graph) CheckLimits(l Limits) error {
+	if l.MaxEdges > 0 && len(g.Edges) > l.MaxEdges {
+		return fmt.Errorf("topology has %d calls, over the limit of %d; scope the analysis with -root <service> or raise -max-edges",
+			len(g.Edges), l.MaxEdges)
+	}
+	if n := len(g.nodeSet()); l.MaxNodes > 0 && n > l.MaxNodes {
+		return fmt.Errorf("topology has %d services, over the limit of %d; scope the analysis with -root <service> or raise -max-nodes",
+			n, l.MaxNodes)
+	}
+	if l.MaxPaths > 0 && g.countPaths(l.MaxPaths+1) > l.MaxPaths {
+		return fmt.Errorf("topology has more than %d call paths, over the limit; scope the analysis with -root <service> or raise -max-paths",
+			l.MaxPaths)
+	}
+	return nil
+}
+
+func (g *Graph) nodeSet() map[string]bool {
+	nodes := make(map[string]bool)
+	for _, e := range g.Edges {
+		nodes[e.Source] = true
+		nodes[e.Target] = true
+	}
+	return nodes
+}
+
+// ReachableFrom returns the edges reachable from root, in their original
+// order.
+func ReachableFrom(edges []CallEdge, root string) []CallEdge {
+	adj := make(map[string][]string)
+	for _, e := range edges {
+		adj[e.Source] = append(adj[e.Source], e.Target)
+	}
+	seen := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, t := range adj[n] {
+			if !seen[t] {
+				seen[t] = true
+				queue = append(queue, t)
+			}
+		}
+	}
+	var out []CallEdge
+	for _, e := range edges {
+		if seen[e.Source] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (g *Graph) progress() progress.Reporter {
+	if g.Progress == nil {
+		return progress.Nop
+	}
+	return g.Progress
+}
+
+// debug logs a rule-evaluation trace record when a Logger is set.
+func (g *Graph) debug(msg string, args ...any) {
+	if g.Logger != nil {
+		g.Logger.Debug(msg, args...)
 	}
-	return &Graph{Edges: edges, Adj: adj}
 }
 
 func (g *Graph) Analyze() []Finding {
+	if g.Progress != nil {
+		// Rules walk the paths as often as they need; count them once.
+		g.Progress.Add(progress.Paths, g.countPaths(0))
+	}
 	var f []Finding
 	f = append(f, g.edgeRules()...)
 	f = append(f, g.amplification()...)
+	f = append(f, g.ruleFindings()...)
+	f = g.applyConfidence(f)
+	g.annotateBlastRadius(f)
+	sortFindings(f)
 	return f
 }
 
-func (g *Graph) edgeRules() []Finding {
-	var f []Finding
-	nonIdem := map[string]bool{"POST": true, "PATCH": true, "DELETE": true}
+// downgrade maps a severity to the one below it; info findings are dropped.
+var downgrade = map[string]string{"error": "warning", "warning": "info"}
+
+// applyConfidence lowers each finding whose path crosses a call with
+// Confidence below MinConfidence by one severity level, dropping info
+// findings, so heuristic guesses from source extraction cannot fail a run
+// as easily as declared config.
+func (g *Graph) applyConfidence(findings []Finding) []Finding {
+	if g.MinConfidence <= 0 {
+		return findings
+	}
+	low := make(map[[2]string]bool)
 	for _, e := range g.Edges {
-		p := []string{e.Source, e.Target}
-		for _, d := range g.Adj[e.Target] {
-			if e.Timeout > 0 && d.Timeout > e.Timeout {
-				f = append(f, Finding{"timeout-inversion", "error", fmt.Sprintf(
-					"%s->%s timeout %v but %s->%s timeout %v (downstream > upstream)",
-					e.Source, e.Target, e.Timeout, e.Target, d.Target, d.Timeout),
-					[]string{e.Source, e.Target, d.Target}})
+		if e.Confidence > 0 && e.Confidence < g.MinConfidence {
+			low[[2]string{e.Source, e.Target}] = true
+		}
+	}
+	return g.downgradeUncertain(findings, low)
+}
+
+// downgradeUncertain applies the confidence downgrade to findings whose
+// path crosses one of the low-confidence calls, keyed by source and target.
+func (g *Graph) downgradeUncertain(findings []Finding, low map[[2]string]bool) []Finding {
+	if len(low) == 0 {
+		return findings
+	}
+	out := findings[:0]
+	for _, f := range findings {
+		uncertain := false
+		for i := 0; i+1 < len(f.Path); i++ {
+			if low[[2]string{f.Path[i], f.Path[i+1]}] {
+				uncertain = true
+				break
 			}
 		}
-		if e.Retries > 0 && !e.CircuitBreaker {
-			f = append(f, Finding{"retry-without-cb", "warning", fmt.Sprintf(
-				"%s->%s has %d retries but no circuit breaker", e.Source, e.Target, e.Retries), p})
+		if !uncertain {
+			out = append(out, f)
+			continue
+		}
+		sev, ok := downgrade[f.Severity]
+		g.debug("low confidence", "rule", f.Rule, "path", f.Path, "severity", f.Severity,
+			"downgraded_to", sev, "suppressed", !ok)
+		if ok {
+			f.Severity = sev
+			out = append(out, f)
 		}
-		if e.Retries > 0 && nonIdem[e.Method] {
-			f = append(f, Finding{"non-idempotent-retry", "error", fmt.Sprintf(
-				"%s->%s retries %s %d times (non-idempotent)", e.Source, e.Target, e.Method, e.Retries), p})
+	}
+	return out
+}
+
+// annotateBlastRadius sets each finding's BlastRadius: the number of
+// services reachable from the target of its first hop, the target included.
+// A finding about a single service counts that service and everything it
+// reaches; findings without a path have no blast radius.
+func (g *Graph) annotateBlastRadius(findings []Finding) {
+	reach := make(map[string]int)
+	for i, f := range findings {
+		var from string
+		switch {
+		case len(f.Path) >= 2:
+			from = f.Path[1]
+		case len(f.Path) == 1:
+			from = f.Path[0]
+		default:
+			continue
 		}
-		if e.Retries > 0 && !e.BackoffJitter {
-			f = append(f, Finding{"backoff-no-jitter", "warning", fmt.Sprintf(
-				"%s->%s retries without jitter (thundering herd risk)", e.Source, e.Target), p})
+		n, ok := reach[from]
+		if !ok {
+			n = g.reachableCount(from)
+			reach[from] = n
 		}
+		findings[i].BlastRadius = n
+	}
+}
+
+// reachableCount returns how many services can be reached from node by
+// following calls, node included.
+func (g *Graph) reachableCount(node string) int {
+	seen := map[string]bool{node: true}
+	queue := []string{node}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Adj[n] {
+			if !seen[e.Target] {
+				seen[e.Target] = true
+				queue = append(queue, e.Target)
+			}
+		}
+	}
+	return len(seen)
+}
+
+// sortByImpact orders findings by descending BlastRadius. The sort is
+// stable, so equal-impact findings keep their severity order.
+func sortByImpact(f []Finding) {
+	sort.SliceStable(f, func(i, j int) bool { return f[i].BlastRadius > f[j].BlastRadius })
+}
+
+// criticalityRank orders call criticalities; undeclared ranks as medium.
+var criticalityRank = map[string]int{"low": 1, "": 2, "medium": 2, "high": 3}
+
+// criticality is the rank of the most critical call on the finding's path,
+// or of an undeclared call when its path holds no call.
+func (g *Graph) criticality(f Finding) int {
+	rank, found := 0, false
+	for i := 1; i < len(f.Path); i++ {
+		for _, e := range g.Adj[f.Path[i-1]] {
+			if e.Target == f.Path[i] {
+				rank, found = max(rank, criticalityRank[e.Criticality]), true
+			}
+		}
+	}
+	if !found {
+		return criticalityRank[""]
+	}
+	return rank
+}
+
+// sortByCriticality orders findings by the criticality of the calls on
+// their paths, most critical first, regardless of severity. The sort is
+// stable, so findings of equal criticality keep their severity order.
+func (g *Graph) sortByCriticality(f []Finding) {
+	sort.SliceStable(f, func(i, j int) bool { return g.criticality(f[i]) > g.criticality(f[j]) })
+}
+
+// sortFindings orders findings by descending severity, then rule, path and
+// message, so identical topologies always number their findings the same way.
+func sortFindings(f []Finding) {
+	sort.SliceStable(f, func(i, j int) bool {
+		a, b := f[i], f[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] > severityRank[b.Severity]
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if pa, pb := strings.Join(a.Path, "\x00"), strings.Join(b.Path, "\x00"); pa != pb {
+			return pa < pb
+		}
+		return a.Message < b.Message
+	})
+}
+
+// idempotent reports whether the call is safe to retry (see
+// parser.Idempotent).
+func (e CallEdge) idempotent() bool {
+	return parser.Idempotent(e.Method, e.Idempotent)
+}
+
+func (g *Graph) edgeRules() []Finding {
+	var f []Finding
+	for _, e := range g.Edges {
+		for _, d := range g.Adj[e.Target] {
+			inverted := e.Timeout > 0 && d.Timeout > e.Timeout
+			g.debug("check", "rule", "timeout-inversion",
+				"edge", e.Source+"->"+e.Target, "timeout", e.Timeout.String(),
+				"downstream", d.Source+"->"+d.Target, "downstream_timeout", d.Timeout.String(),
+				"violation", inverted)
+			if inverted {
+				f = append(f, inversionFinding(e, d))
+			}
+		}
+		g.debug("check", "rule", "edge-retry-checks", "edge", e.Source+"->"+e.Target,
+			"retries", e.Retries, "circuit_breaker", e.CircuitBreaker, "idempotent", e.idempotent(),
+			"jitter", e.BackoffJitter)
+		f = append(f, retryFindings(e)...)
 	}
 	return f
 }
 
-func (g *Graph) amplification() []Finding {
+// inversionFinding reports that d, called by e's target, may run longer
+// than e allows.
+func inversionFinding(e, d CallEdge) Finding {
+	return Finding{Rule: "timeout-inversion", Severity: "error", Message: fmt.Sprintf(
+		"%s->%s timeout %v but %s->%s timeout %v (downstream > upstream)",
+		e.Source, e.Target, e.Timeout, e.Target, d.Target, d.Timeout),
+		Path: []string{e.Source, e.Target, d.Target}}
+}
+
+// retryFindings runs the built-in checks that judge a retried call on its
+// own: circuit breaker, idempotency and jitter.
+func retryFindings(e CallEdge) []Finding {
+	if e.Retries == 0 {
+		return nil
+	}
 	var f []Finding
+	p := []string{e.Source, e.Target}
+	if !e.CircuitBreaker {
+		f = append(f, Finding{Rule: "retry-without-cb", Severity: "warning", Message: fmt.Sprintf(
+			"%s->%s has %d retries but no circuit breaker", e.Source, e.Target, e.Retries), Path: p})
+	}
+	if !e.idempotent() {
+		f = append(f, Finding{Rule: "non-idempotent-retry", Severity: "error", Message: fmt.Sprintf(
+			"%s->%s retries %s %d times (non-idempotent)", e.Source, e.Target, e.Method, e.Retries), Path: p})
+	}
+	if !e.BackoffJitter {
+		f = append(f, Finding{Rule: "backoff-no-jitter", Severity: "warning", Message: fmt.Sprintf(
+			"%s->%s retries without jitter (thundering herd risk)", e.Source, e.Target), Path: p})
+	}
+	return f
+}
+
+// roots returns the services paths are walked from, in sorted order (see
+// graph.CallGraph.Roots): callers that no other service calls, plus the
+// callers none of those reaches, so a cycle is analyzed even when an
+// unrelated part of the graph has an entry point. ok is false when the
+// graph is fully cyclic, with no natural entry point at all, so the caller
+// can report it.
+func (g *Graph) roots() (roots []string, ok bool) {
 	incoming := map[string]bool{}
 	for _, e := range g.Edges {
 		incoming[e.Target] = true
 	}
+	ok = len(g.Adj) == 0
 	for src := range g.Adj {
 		if !incoming[src] {
-			g.dfs(src, []string{src}, 1, &f)
+			ok = true
+			break
 		}
 	}
-	return f
+	return rules.ToCallGraph(g).Roots(), ok
 }
 
-func (g *Graph) dfs(node string, path []string, factor int, f *[]Finding) {
-	for _, e := range g.Adj[node] {
-		af := factor * (1 + e.Retries)
-		np := append(append([]string{}, path...), e.Target)
-		if af > 10 {
-			*f = append(*f, Finding{"retry-amplification", "error", fmt.Sprintf(
-				"amplification factor %dx along path (threshold 10x)", af), np})
+func (g *Graph) amplification() []Finding {
+	var f []Finding
+	roots, ok := g.roots()
+	if !ok {
+		f = append(f, Finding{Rule: "no-entry-point", Severity: "info", Message: fmt.Sprintf(
+			"every service has an incoming call (fully cyclic topology); analyzed paths from all %d callers", len(roots))})
+	}
+	// WalkPaths reports only maximal paths, so check every prefix of each,
+	// skipping those shared with the previous path (already checked) and
+	// stopping at 9 calls.
+	var prev []rules.Edge
+	g.WalkPaths(func(path []rules.Edge) bool {
+		shared := 0
+		for shared < len(path) && shared < len(prev) && sameCall(path[shared], prev[shared]) {
+			shared++
 		}
-		if len(np) < 10 {
-			g.dfs(e.Target, np, af, f)
+		factor := 1
+		for i, e := range path {
+			if i == 9 {
+				break
+			}
+			factor *= 1 + e.MaxRetries
+			if i < shared {
+				continue
+			}
+			np := []string{path[0].Source}
+			for _, pe := range path[:i+1] {
+				np = append(np, pe.Target)
+			}
+			limit := g.amplificationThreshold(e.Source)
+			g.debug("check", "rule", "retry-amplification", "path", np, "factor", factor,
+				"threshold", limit, "violation", factor > limit)
+			if factor > limit {
+				f = append(f, Finding{Rule: "retry-amplification", Severity: "error", Message: fmt.Sprintf(
+					"amplification factor %dx along path (threshold %dx)", factor, limit), Path: np})
+			}
 		}
+		prev = append(prev[:0], path...)
+		return true
+	})
+	return f
+}
+
+// sameCall reports whether a and b are the same call for amplification:
+// one caller, callee, endpoint and retry count.
+func sameCall(a, b rules.Edge) bool {
+	return a.Source == b.Source && a.Target == b.Target && a.Method == b.Method &&
+		a.Endpoint == b.Endpoint && a.MaxRetries == b.MaxRetries
+}
+
+// amplificationThreshold is the limit for paths ending in a call made by
+// caller.
+func (g *Graph) amplificationThreshold(caller string) int {
+	if t := g.configFor(caller).AmplificationThreshold; t > 0 {
+		return t
+	}
+	return 10
+}
+
+// configFor returns the rule config for calls made by svc: its namespace's
+// entry in NamespaceConfig, or Config.
+func (g *Graph) configFor(svc string) RuleConfig {
+	if cfg, ok := g.NamespaceConfig[g.Services[svc].Namespace]; ok {
+		return cfg
 	}
+	return g.Config
 }
@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/cascadeguard/cascadeguard/parser"
+	"github.com/cascadeguard/cascadeguard/progress"
+	"github.com/cascadeguard/cascadeguard/rules"
 )
 
 func hasRule(findings []Finding, rule string) bool {
@@ -41,6 +51,40 @@ func TestRetryAmplification(t *testing.T) {
 	}
 }
 
+func TestRetryAmplificationChecksEachPathOnce(t *testing.T) {
+	g := NewGraph([]CallEdge{
+		edge("A", "B", 5*time.Second, 3, true, "GET", true),
+		edge("B", "C", 3*time.Second, 3, true, "GET", true),
+		edge("B", "D", 3*time.Second, 3, true, "GET", true),
+		edge("C", "E", 1*time.Second, 0, true, "GET", true),
+	})
+	var got []string
+	for _, f := range g.Analyze() {
+		if f.Rule == "retry-amplification" {
+			got = append(got, strings.Join(f.Path, "->"))
+		}
+	}
+	want := []string{"A->B->C", "A->B->C->E", "A->B->D"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("want one finding per path over the threshold %v, got %v", want, got)
+	}
+
+	// Paths are checked up to 9 calls deep: 2^4 through 2^9 exceed 10.
+	var chain []CallEdge
+	for i := 0; i < 12; i++ {
+		chain = append(chain, edge(fmt.Sprint("s", i), fmt.Sprint("s", i+1), time.Second, 1, true, "GET", true))
+	}
+	n := 0
+	for _, f := range NewGraph(chain).Analyze() {
+		if f.Rule == "retry-amplification" {
+			n++
+		}
+	}
+	if n != 6 {
+		t.Errorf("want 6 findings on a 12-call chain, got %d", n)
+	}
+}
+
 func TestRetryWithoutCircuitBreaker(t *testing.T) {
 	g := NewGraph([]CallEdge{
 		edge("A", "B", 3*time.Second, 2, false, "GET", true),
@@ -71,7 +115,7 @@ func TestBackoffNoJitter(t *testing.T) {
 func TestCleanTopology(t *testing.T) {
 	g := NewGraph([]CallEdge{
 		edge("gateway", "api", 5*time.Second, 1, true, "GET", true),
-		edge("api", "db", 3*time.Second, 1, true, "GET", true),
+		edge("api", "db", 2400*time.Millisecond, 1, true, "GET", true),
 	})
 	findings := g.Analyze()
 	if len(findings) != 0 {
@@ -112,3 +156,491 @@ func TestZeroTimeoutSkipsInversion(t *testing.T) {
 		t.Fatal("should not flag timeout-inversion when upstream timeout is 0 (unset)")
 	}
 }
+
+func TestFullyCyclicTopologyReportsNoEntryPoint(t *testing.T) {
+	g := NewGraph([]CallEdge{
+		edge("A", "B", 3*time.Second, 3, true, "GET", true),
+		edge("B", "C", 3*time.Second, 3, true, "GET", true),
+		edge("C", "A", 3*time.Second, 3, true, "GET", true),
+	})
+	findings := g.Analyze()
+	if !hasRule(findings, "no-entry-point") {
+		t.Fatal("expected no-entry-point for a topology where every service is called")
+	}
+	if !hasRule(findings, "retry-amplification") {
+		t.Fatal("expected paths to be analyzed from all callers: (1+3)*(1+3)=16 > 10")
+	}
+}
+
+func TestCycleBesideRootIsAnalyzed(t *testing.T) {
+	// C<->D is reached by no entry point; the unrelated gateway->A call
+	// must not hide it from the path rules.
+	g := NewGraph([]CallEdge{
+		edge("gateway", "A", 3*time.Second, 0, true, "GET", true),
+		edge("C", "D", 3*time.Second, 3, true, "GET", true),
+		edge("D", "C", 3*time.Second, 3, true, "GET", true),
+		edge("D", "E", 3*time.Second, 3, true, "GET", true),
+	})
+	findings := g.Analyze()
+	if !hasRule(findings, "retry-amplification") {
+		t.Fatal("expected retry-amplification on C->D->E: (1+3)*(1+3)=16 > 10")
+	}
+	if hasRule(findings, "no-entry-point") {
+		t.Fatal("gateway is an entry point; no-entry-point should not be reported")
+	}
+	if roots, ok := g.roots(); !ok || strings.Join(roots, ",") != "C,D,gateway" {
+		t.Errorf("want roots C,D,gateway with an entry point, got %v (ok=%v)", roots, ok)
+	}
+}
+
+func TestNoEntryPointNotReportedWithRoot(t *testing.T) {
+	g := NewGraph([]CallEdge{
+		edge("gateway", "A", 3*time.Second, 0, true, "GET", true),
+		edge("A", "B", 2*time.Second, 0, true, "GET", true),
+		edge("B", "A", 1*time.Second, 0, true, "GET", true),
+	})
+	if hasRule(g.Analyze(), "no-entry-point") {
+		t.Fatal("gateway is an entry point; no-entry-point should not be reported")
+	}
+}
+
+func TestReadAfterWriteRetry(t *testing.T) {
+	read := edge("orders", "orders-view", time.Second, 2, true, "GET", true)
+	read.ReadsFrom = []string{"orders"}
+	g := NewGraph([]CallEdge{
+		edge("gateway", "orders", 3*time.Second, 0, true, "POST", true),
+		read,
+	})
+	if !hasRule(g.Analyze(), "read-after-write-retry") {
+		t.Fatal("expected read-after-write-retry for retried read of data written upstream")
+	}
+}
+
+func TestOrphanedRetryOptIn(t *testing.T) {
+	db := edge("api", "db", 600*time.Millisecond, 2, true, "GET", true)
+	db.BackoffBase = 100 * time.Millisecond
+	edges := []CallEdge{
+		edge("gateway", "api", 2*time.Second, 0, true, "GET", true),
+		db,
+	}
+	if hasRule(NewGraph(edges).Analyze(), "orphaned-retry") {
+		t.Fatal("orphaned-retry is opt-in and should not run by default")
+	}
+	g := NewGraph(edges)
+	g.Enabled = map[string]bool{"orphaned-retry": true}
+	if !hasRule(g.Analyze(), "orphaned-retry") {
+		t.Fatal("expected orphaned-retry when enabled: api->db 600ms × 3 + 100ms + 200ms > gateway->api 2s")
+	}
+}
+
+func TestEffectiveTimeoutInversionByDefault(t *testing.T) {
+	edges := []CallEdge{
+		edge("gateway", "api", 5*time.Second, 1, true, "GET", true),
+		edge("api", "db", 3*time.Second, 1, true, "GET", true),
+	}
+	if !hasRule(NewGraph(edges).Analyze(), "effective-timeout-inversion") {
+		t.Fatal("expected effective-timeout-inversion by default: api->db 3s × 2 > gateway->api 5s")
+	}
+	g := NewGraph(edges)
+	g.Enabled = map[string]bool{"orphaned-retry": true}
+	if hasRule(g.Analyze(), "orphaned-retry") {
+		t.Fatal("orphaned-retry should leave api->db to effective-timeout-inversion")
+	}
+}
+
+func TestRuleScopeLimitsEdges(t *testing.T) {
+	db := edge("api", "db", 600*time.Millisecond, 2, true, "GET", true)
+	db.BackoffBase = 100 * time.Millisecond
+	edges := []CallEdge{
+		edge("gateway", "api", 2*time.Second, 0, true, "GET", true),
+		db,
+	}
+	g := NewGraph(edges)
+	g.Enabled = map[string]bool{"orphaned-retry": true}
+	g.Scopes = map[string]rules.Scope{"orphaned-retry": rules.ScopeEntryEdges}
+	if hasRule(g.Analyze(), "orphaned-retry") {
+		t.Fatal("entry-edges scope should hide the retried api->db call")
+	}
+	g.Scopes = map[string]rules.Scope{"orphaned-retry": rules.ScopeLeafEdges}
+	if !hasRule(g.Analyze(), "orphaned-retry") {
+		t.Fatal("leaf-edges scope should still see the retried api->db call")
+	}
+}
+
+func TestNamespaceConfigOverridesMatchingCalls(t *testing.T) {
+	opts, err := LoadOptions(strings.NewReader(
+		`{"namespaces": {"payments": {"amplification_threshold": 2, "backend_request_cap": 2}}}`), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opts.namespaceConfigs()["payments"]; got.GlobalRequestCap != 200 || got.AmplificationThreshold != 2 {
+		t.Fatalf("payments config should override one field and inherit the rest: %+v", got)
+	}
+	g := NewGraph([]CallEdge{
+		edge("web", "shop", 2*time.Second, 2, true, "GET", true),
+		edge("pay-gw", "pay", 2*time.Second, 2, true, "GET", true),
+	})
+	g.Services = map[string]Service{"pay-gw": {Namespace: "payments"}, "pay": {Namespace: "payments"}, "web": {Namespace: "retail"}}
+	g.Config = opts.Rules
+	g.NamespaceConfig = opts.namespaceConfigs()
+	for _, f := range g.Analyze() {
+		if f.Rule != "retry-amplification" && f.Rule != "backend-request-cap" {
+			continue
+		}
+		if !strings.HasPrefix(f.Path[0], "pay") {
+			t.Errorf("%s reported outside the payments namespace: %+v", f.Rule, f)
+		}
+	}
+	findings := g.Analyze()
+	if !hasRule(findings, "retry-amplification") || !hasRule(findings, "backend-request-cap") {
+		t.Errorf("3 attempts from pay-gw should exceed the payments limits: %+v", findings)
+	}
+}
+
+func TestLowConfidenceFindingsDowngraded(t *testing.T) {
+	severities := func(findings []Finding, rule string) []string {
+		var out []string
+		for _, f := range findings {
+			if f.Rule == rule {
+				out = append(out, f.Severity)
+			}
+		}
+		return out
+	}
+	guessed := edge("api", "db", time.Second, 2, true, "POST", false)
+	guessed.Confidence = 0.5
+	edges := []CallEdge{edge("gateway", "api", 2*time.Second, 0, true, "GET", true), guessed}
+
+	findings := NewGraph(edges).Analyze()
+	if got := severities(findings, "non-idempotent-retry"); len(got) != 1 || got[0] != "error" {
+		t.Fatalf("without a minimum confidence the finding keeps its severity, got %v", got)
+	}
+
+	g := NewGraph(edges)
+	g.MinConfidence = 0.8
+	findings = g.Analyze()
+	if got := severities(findings, "non-idempotent-retry"); len(got) != 1 || got[0] != "warning" {
+		t.Errorf("want non-idempotent-retry downgraded to warning, got %v", got)
+	}
+	if got := severities(findings, "backoff-no-jitter"); len(got) != 1 || got[0] != "info" {
+		t.Errorf("want backoff-no-jitter downgraded to info, got %v", got)
+	}
+
+	g = NewGraph(edges)
+	g.MinConfidence = 0.5
+	if got := severities(g.Analyze(), "non-idempotent-retry"); len(got) != 1 || got[0] != "error" {
+		t.Errorf("a call at the minimum confidence is not downgraded, got %v", got)
+	}
+}
+
+func TestDeclaredIdempotentPost(t *testing.T) {
+	idem := true
+	e := edge("A", "B", 3*time.Second, 2, true, "POST", true)
+	e.Idempotent = &idem
+	findings := NewGraph([]CallEdge{e}).Analyze()
+	if hasRule(findings, "non-idempotent-retry") {
+		t.Error("explicit idempotent: true should suppress non-idempotent-retry")
+	}
+	if !hasRule(findings, "idempotent-method-mismatch") {
+		t.Error("expected idempotent-method-mismatch for POST marked idempotent")
+	}
+}
+
+func chain(n int) []CallEdge {
+	var edges []CallEdge
+	for i := 0; i < n; i++ {
+		edges = append(edges, edge(fmt.Sprintf("s%d", i), fmt.Sprintf("s%d", i+1), time.Second, 0, true, "GET", true))
+	}
+	return edges
+}
+
+func TestCheckLimits(t *testing.T) {
+	g := NewGraph(chain(4)) // 5 services, 4 calls
+	if err := g.CheckLimits(Limits{MaxNodes: 5, MaxEdges: 4}); err != nil {
+		t.Errorf("graph at the limit should pass: %v", err)
+	}
+	if err := g.CheckLimits(Limits{MaxNodes: 5, MaxEdges: 3}); err == nil || !strings.Contains(err.Error(), "-root") {
+		t.Errorf("expected edge-limit error suggesting -root, got %v", err)
+	}
+	if err := g.CheckLimits(Limits{MaxNodes: 4, MaxEdges: 4}); err == nil || !strings.Contains(err.Error(), "-max-nodes") {
+		t.Errorf("expected node-limit error suggesting -max-nodes, got %v", err)
+	}
+	if err := NewGraph(chain(50)).CheckLimits(DefaultLimits); err != nil {
+		t.Errorf("modest graph should pass default limits: %v", err)
+	}
+}
+
+func TestCheckLimitsPaths(t *testing.T) {
+	// 48 calls but 3^6 = 729 paths.
+	g := NewGraph(wideDAG(6, 3))
+	if err := g.CheckLimits(Limits{MaxPaths: 729}); err != nil {
+		t.Errorf("graph at the path limit should pass: %v", err)
+	}
+	if err := g.CheckLimits(Limits{MaxPaths: 728}); err == nil || !strings.Contains(err.Error(), "-max-paths") {
+		t.Errorf("expected path-limit error suggesting -max-paths, got %v", err)
+	}
+}
+
+func TestReachableFrom(t *testing.T) {
+	edges := append(chain(3), edge("other", "s9", time.Second, 0, true, "GET", true))
+	got := ReachableFrom(edges, "s1")
+	if len(got) != 2 || got[0].Source != "s1" || got[1].Source != "s2" {
+		t.Errorf("want s1->s2, s2->s3; got %+v", got)
+	}
+}
+
+func TestAnalyzeReportsProgress(t *testing.T) {
+	var c progress.Counter
+	g := NewGraph([]CallEdge{
+		edge("gateway", "A", 3*time.Second, 0, true, "GET", true),
+		edge("gateway", "B", 3*time.Second, 0, true, "GET", true),
+		edge("A", "C", time.Second, 0, true, "GET", true),
+	})
+	g.Progress = &c
+	g.Analyze()
+	if c.Count(progress.Rules) != len(g.extraRules()) {
+		t.Errorf("rules: want %d, got %d", len(g.extraRules()), c.Count(progress.Rules))
+	}
+	if c.Count(progress.Paths) != 2 {
+		t.Errorf("paths: want the 2 paths counted once, got %d", c.Count(progress.Paths))
+	}
+}
+
+func TestZeroBackoffRetryOptIn(t *testing.T) {
+	edges := []CallEdge{edge("gateway", "api", 5*time.Second, 1, true, "GET", true)}
+	if hasRule(NewGraph(edges).Analyze(), "zero-backoff-retry") {
+		t.Fatal("zero-backoff-retry should only run when enabled")
+	}
+	g := NewGraph(edges)
+	g.Enabled = map[string]bool{"zero-backoff-retry": true}
+	if !hasRule(g.Analyze(), "zero-backoff-retry") {
+		t.Fatal("expected zero-backoff-retry when enabled")
+	}
+	edges[0].BackoffBase = 100 * time.Millisecond
+	g = NewGraph(edges)
+	g.Enabled = map[string]bool{"zero-backoff-retry": true}
+	if hasRule(g.Analyze(), "zero-backoff-retry") {
+		t.Fatal("backoff_base set: expected no zero-backoff-retry")
+	}
+}
+
+func TestBlastRadius(t *testing.T) {
+	// gateway → api → {orders, users}; orders → db; users → db; api → cache
+	g := NewGraph([]CallEdge{
+		edge("gateway", "api", 5*time.Second, 0, true, "GET", true),
+		edge("api", "orders", 3*time.Second, 0, true, "GET", true),
+		edge("api", "users", 3*time.Second, 0, true, "GET", true),
+		edge("api", "cache", 3*time.Second, 0, true, "GET", true),
+		edge("orders", "db", time.Second, 0, true, "GET", true),
+		edge("users", "db", time.Second, 0, true, "GET", true),
+	})
+	findings := []Finding{
+		{Rule: "a", Path: []string{"gateway", "api"}},      // api, orders, users, cache, db
+		{Rule: "b", Path: []string{"api", "orders", "db"}}, // orders, db
+		{Rule: "c", Path: []string{"api", "cache"}},        // cache
+		{Rule: "d", Path: []string{"users"}},               // users, db
+		{Rule: "e"},                                        // no path
+	}
+	g.annotateBlastRadius(findings)
+	want := map[string]int{"a": 5, "b": 2, "c": 1, "d": 2, "e": 0}
+	for _, f := range findings {
+		if f.BlastRadius != want[f.Rule] {
+			t.Errorf("%s %v: want blast radius %d, got %d", f.Rule, f.Path, want[f.Rule], f.BlastRadius)
+		}
+	}
+
+	sortByImpact(findings)
+	var order []string
+	for _, f := range findings {
+		order = append(order, f.Rule)
+	}
+	if got := strings.Join(order, ""); got != "abdce" {
+		t.Errorf("impact order: want abdce, got %s", got)
+	}
+}
+
+func TestSortByCriticality(t *testing.T) {
+	critical := func(e CallEdge, c string) CallEdge { e.Criticality = c; return e }
+	g := NewGraph([]CallEdge{
+		critical(edge("gateway", "checkout", 5*time.Second, 0, true, "GET", true), "high"),
+		critical(edge("checkout", "payments", 3*time.Second, 0, true, "GET", true), "high"),
+		edge("gateway", "search", 5*time.Second, 0, true, "GET", true),
+		critical(edge("gateway", "avatars", 5*time.Second, 0, true, "GET", true), "low"),
+	})
+	findings := []Finding{
+		{Rule: "a", Severity: "error", Path: []string{"gateway", "avatars"}},
+		{Rule: "b", Severity: "error", Path: []string{"gateway", "search"}},
+		{Rule: "c", Severity: "warning", Path: []string{"gateway", "checkout", "payments"}},
+		{Rule: "d", Severity: "warning", Path: []string{"gateway", "search"}},
+		{Rule: "e", Severity: "info", Path: []string{"checkout", "payments"}},
+		{Rule: "f", Severity: "info"},
+	}
+	g.sortByCriticality(findings)
+	var order []string
+	for _, f := range findings {
+		order = append(order, f.Rule)
+	}
+	// High-criticality warning and info first, then the undeclared (medium)
+	// calls in severity order, then the low-criticality error.
+	if got := strings.Join(order, ""); got != "cebdfa" {
+		t.Errorf("criticality order: want cebdfa, got %s", got)
+	}
+}
+
+func TestDebugTraceLogsDecisions(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGraph([]CallEdge{
+		edge("A", "B", 3*time.Second, 0, true, "GET", true),
+		edge("B", "C", 5*time.Second, 0, true, "GET", true),
+	})
+	g.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	g.Analyze()
+
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("trace line is not JSON: %q", line)
+		}
+		if rec["rule"] != "timeout-inversion" {
+			continue
+		}
+		found = true
+		if rec["edge"] != "A->B" || rec["timeout"] != "3s" || rec["downstream"] != "B->C" ||
+			rec["downstream_timeout"] != "5s" || rec["violation"] != true {
+			t.Errorf("unexpected timeout-inversion trace: %v", rec)
+		}
+	}
+	if !found {
+		t.Fatalf("no timeout-inversion decision logged:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"msg":"rule evaluated"`) {
+		t.Error("rules-package rules should log their evaluation")
+	}
+}
+
+// syntheticEdges builds a layered topology of n services, each calling up
+// to three services in the next layer, with a mix of timeouts, retries,
+// methods and resilience settings so every per-call rule has work to do.
+func syntheticEdges(n int) []CallEdge {
+	methods := []string{"GET", "POST", "PUT", "DELETE"}
+	var edges []CallEdge
+	for i := 0; i < n; i++ {
+		for k := 1; k <= 3 && i*3+k < n; k++ {
+			j := i*3 + k
+			edges = append(edges, CallEdge{
+				Source:          fmt.Sprintf("svc-%d", i),
+				Target:          fmt.Sprintf("svc-%d", j),
+				Timeout:         time.Duration(1+(i*7+k)%6) * time.Second,
+				Retries:         (i + k) % 4,
+				CircuitBreaker:  (i+k)%3 == 0,
+				Method:          methods[(i+k)%len(methods)],
+				BackoffJitter:   (i+k)%2 == 0,
+				Kind:            map[bool]string{true: "health"}[j%17 == 0],
+				CrossesBoundary: j%11 == 0,
+				InTransaction:   j%13 == 0,
+			})
+		}
+	}
+	return edges
+}
+
+func findingKeys(findings []Finding) []string {
+	keys := make([]string, 0, len(findings))
+	for _, f := range findings {
+		keys = append(keys, fmt.Sprintf("%s|%s|%v|%s", f.Rule, f.Severity, f.Path, f.Message))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestStreamMatchesAnalyzeOnPerCallRules(t *testing.T) {
+	edges := syntheticEdges(300)
+	opts := DefaultOptions()
+	opts.Enable = []string{"zero-backoff-retry", "orphaned-retry"}
+
+	g := NewGraph(edges)
+	g.configure(opts, nil, &parser.RawTopology{})
+	streamable := map[string]bool{"timeout-inversion": true, "retry-without-cb": true,
+		"non-idempotent-retry": true, "backoff-no-jitter": true}
+	for id := range streamRules {
+		streamable[id] = true
+	}
+	var want []Finding
+	for _, f := range g.Analyze() {
+		if streamable[f.Rule] {
+			want = append(want, f)
+		}
+	}
+
+	s := NewStreamAnalyzer(opts)
+	for _, e := range edges {
+		s.Add(e)
+	}
+	got := s.Findings()
+	if len(want) == 0 {
+		t.Fatal("synthetic topology should trigger per-call rules")
+	}
+	if w, g := findingKeys(want), findingKeys(got); strings.Join(w, "\n") != strings.Join(g, "\n") {
+		t.Errorf("stream findings differ from Analyze: want %d, got %d", len(w), len(g))
+	}
+	if hasRule(got, "orphaned-retry") || hasRule(got, "retry-amplification") {
+		t.Error("path-based rules must not run in stream mode")
+	}
+}
+
+func TestStreamTimeoutInversionAnyOrder(t *testing.T) {
+	// The downstream call arrives before the upstream one.
+	s := NewStreamAnalyzer(DefaultOptions())
+	s.Add(edge("B", "C", 5*time.Second, 0, true, "GET", true))
+	s.Add(edge("A", "B", 3*time.Second, 0, true, "GET", true))
+	if !hasRule(s.Findings(), "timeout-inversion") {
+		t.Fatal("expected timeout-inversion regardless of arrival order")
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	edges := syntheticEdges(5000)
+	for i := 0; i < b.N; i++ {
+		NewGraph(edges).Analyze()
+	}
+}
+
+// wideDAG returns a gateway calling every service of the first of layers
+// layers of width services each, with every service calling every service
+// of the next layer: width^layers paths over few calls.
+func wideDAG(layers, width int) []CallEdge {
+	var edges []CallEdge
+	for j := 0; j < width; j++ {
+		edges = append(edges, edge("gateway", fmt.Sprintf("l1s%d", j), 5*time.Second, 1, false, "GET", false))
+	}
+	for l := 1; l < layers; l++ {
+		for i := 0; i < width; i++ {
+			for j := 0; j < width; j++ {
+				edges = append(edges, edge(fmt.Sprintf("l%ds%d", l, i), fmt.Sprintf("l%ds%d", l+1, j), time.Second, 1, false, "GET", false))
+			}
+		}
+	}
+	return edges
+}
+
+// BenchmarkAnalyzeWideDAG analyzes 93 calls forming 3^11 = 177,147 paths.
+func BenchmarkAnalyzeWideDAG(b *testing.B) {
+	edges := wideDAG(11, 3)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewGraph(edges).Analyze()
+	}
+}
+
+func BenchmarkStreamAnalyzer(b *testing.B) {
+	edges := syntheticEdges(5000)
+	opts := DefaultOptions()
+	for i := 0; i < b.N; i++ {
+		s := NewStreamAnalyzer(opts)
+		for _, e := range edges {
+			s.Add(e)
+		}
+		s.Findings()
+	}
+}
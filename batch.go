@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cascadeguard/cascadeguard/graph"
+	"github.com/cascadeguard/cascadeguard/output"
+)
+
+// batchResult is the analysis of one topology file in -batch mode.
+type batchResult struct {
+	File     string
+	Edges    []CallEdge
+	Graph    *graph.CallGraph
+	Findings []Finding
+}
+
+// batchFiles lists the YAML files directly inside dir, in name order.
+func batchFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no .yaml or .yml topology files", dir)
+	}
+	return files, nil
+}
+
+// analyzeFile runs the full analysis on one topology file and tags each
+// finding with the file it came from.
+func analyzeFile(path, format string, opts Options) (batchResult, error) {
+	topo, err := loadTopology(path, format)
+	if err != nil {
+		return batchResult{}, err
+	}
+	edges, services, err := topologyEdges(topo)
+	if err != nil {
+		return batchResult{}, fmt.Errorf("%s: %w", path, err)
+	}
+	cg, err := graph.BuildCallGraph(topo)
+	if err != nil {
+		return batchResult{}, fmt.Errorf("%s: %w", path, err)
+	}
+	labels, _ := parseEdgeLabel(opts.EdgeLabel) // checked by Validate
+	if err := applyEdgeLabel(edges, labels); err != nil {
+		return batchResult{}, fmt.Errorf("%s: %w", path, err)
+	}
+	g := NewGraph(edges)
+	if err := g.CheckLimits(opts.Limits); err != nil {
+		return batchResult{}, fmt.Errorf("%s: %w", path, err)
+	}
+	g.configure(opts, services, topo)
+	findings := g.Analyze()
+	tmpls, _ := parseMessageTemplates(opts.Messages, opts.MessageVars) // checked by Validate
+	if err := applyMessageTemplates(findings, tmpls, opts.MessageVars); err != nil {
+		return batchResult{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range findings {
+		findings[i].File = path
+	}
+	return batchResult{File: path, Edges: edges, Graph: cg, Findings: findings}, nil
+}
+
+// sharedBackends reports services called from more than one topology file.
+// Each team sees only its own share of the load, so the finding adds up the
+// worst-case requests one root request of every file can send to the
+// service (as in backend-request-cap). It is a warning when that total
+// exceeds requestCap and informational otherwise.
+func sharedBackends(results []batchResult, requestCap int) []Finding {
+	callers := make(map[string][]string) // service -> files calling it
+	pressure := make(map[string]int)
+	for _, r := range results {
+		counts := r.Graph.MaxBackendRequests()
+		seen := make(map[string]bool)
+		for _, e := range r.Edges {
+			if seen[e.Target] {
+				continue
+			}
+			seen[e.Target] = true
+			callers[e.Target] = append(callers[e.Target], r.File)
+			pressure[e.Target] += counts[e.Target]
+		}
+	}
+	svcs := make([]string, 0, len(callers))
+	for svc, files := range callers {
+		if len(files) > 1 {
+			svcs = append(svcs, svc)
+		}
+	}
+	sort.Strings(svcs)
+
+	var f []Finding
+	for _, svc := range svcs {
+		sev := "info"
+		if pressure[svc] > requestCap {
+			sev = "warning"
+		}
+		f = append(f, Finding{Rule: "shared-backend", Severity: sev, Path: []string{svc}, Message: fmt.Sprintf(
+			"%s is called from %d topology files (%s); together they can send it up to %d requests (cap %d)",
+			svc, len(callers[svc]), strings.Join(callers[svc], ", "), pressure[svc], requestCap)})
+	}
+	return f
+}
+
+// runBatch analyzes every topology file in dir and writes the per-file
+// findings followed by the cross-file ones. Only text and github outputs
+// are supported, since they can attribute findings to files.
+func runBatch(dir, format string, opts Options, specs []outputSpec, stdout, stderr io.Writer) int {
+	for _, spec := range specs {
+		if spec.Format != "text" && spec.Format != "github" {
+			fmt.Fprintf(stderr, "error: -batch supports text and github output, not %q\n", spec.Format)
+			return 2
+		}
+	}
+	files, err := batchFiles(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	var results []batchResult
+	var all []Finding
+	for _, path := range files {
+		r, err := analyzeFile(path, format, opts)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		results = append(results, r)
+		all = append(all, r.Findings...)
+	}
+	capacity := opts.Rules.BackendRequestCap
+	if capacity == 0 {
+		capacity = DefaultOptions().Rules.BackendRequestCap
+	}
+	aggregate := sharedBackends(results, capacity)
+	all = append(all, aggregate...)
+
+	for _, spec := range specs {
+		w, closeFn := stdout, func() error { return nil }
+		if spec.Path != "" && spec.Path != "-" {
+			f, err := os.Create(spec.Path)
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				return 2
+			}
+			w, closeFn = f, f.Close
+		}
+		if spec.Format == "github" {
+			err = output.RenderGitHubActions(toViolations(all), w)
+		} else {
+			err = renderBatchText(results, aggregate, w)
+		}
+		if cerr := closeFn(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+	}
+	return opts.failPolicy().exitCode(all)
+}
+
+// renderBatchText writes one text report per file under a "=== file ==="
+// header, then the cross-file findings.
+func renderBatchText(results []batchResult, aggregate []Finding, w io.Writer) error {
+	for _, r := range results {
+		fmt.Fprintf(w, "=== %s ===\n", r.File)
+		if err := renderText(r.Edges, r.Graph, r.Findings, w); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "=== cross-file ===")
+	if len(aggregate) == 0 {
+		_, err := fmt.Fprintln(w, "No cross-file issues found.")
+		return err
+	}
+	for i, f := range aggregate {
+		if _, err := fmt.Fprintf(w, "%d. [%s][%s] %s\n", i+1, severityTag(f.Severity), f.Rule, f.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
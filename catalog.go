@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ruleDoc is the hand-written part of a rule's catalog entry. Params name
+// the RuleConfig fields (by JSON key) that tune the rule.
+type ruleDoc struct {
+	ID          string
+	Severity    string // "error/warning" when it depends on the finding
+	Description string
+	Help        string
+	Params      []string
+}
+
+// ruleCatalog documents every rule CascadeGuard can report, in the order of
+// the README table. Opt-in rules are those in optInRules.
+var ruleCatalog = []ruleDoc{
+	{ID: "timeout-inversion", Severity: "error",
+		Description: "Downstream timeout > upstream timeout",
+		Help:        "The caller gives up before its dependency does, so the downstream work is wasted. Lower the downstream timeout below the caller's, or raise the caller's."},
+	{ID: "effective-timeout-inversion", Severity: "warning",
+		Description: "Downstream timeout fits the caller's, but timeout × (1 + retries) does not",
+		Help:        "Counting retries, the downstream call outlasts the caller. Cut retries or the downstream timeout until every attempt fits."},
+	{ID: "non-monotonic-budget", Severity: "warning",
+		Description: "First hop on a path whose timeout is not below the previous hop's",
+		Help:        "Shrink timeouts hop by hop so each call finishes inside its caller's budget."},
+	{ID: "chatty-chain", Severity: "info",
+		Description: "Long path whose mean timeout is tiny (overhead-dominated, a \"distributed monolith\")",
+		Help:        "Network overhead dominates such chains. Consider merging services or batching calls.",
+		Params:      []string{"chatty_chain_min_hops", "chatty_chain_max_mean_timeout"}},
+	{ID: "uniform-path-timeout", Severity: "info",
+		Description: "Every hop of a path of 3+ calls has the same timeout",
+		Help:        "The budget was never allocated per hop. Give each hop a timeout below its caller's."},
+	{ID: "timeout-matches-proxy", Severity: "warning",
+		Description: "Timeout close to a proxy or load-balancer timeout",
+		Help:        "The app and the proxy race to time out first. Keep the app timeout clearly below the proxy's.",
+		Params:      []string{"proxy_timeouts", "proxy_timeout_epsilon"}},
+	{ID: "suspicious-timeout-magnitude", Severity: "warning",
+		Description: "Synchronous timeout implausibly long or short, likely a unit typo",
+		Help:        "Check the unit of the timeout (ms vs s).",
+		Params:      []string{"suspicious_timeout_max", "suspicious_timeout_min"}},
+	{ID: "backoff-latency-inflation", Severity: "warning",
+		Description: "First hop's p99 plus one slow attempt and backoff per retried hop exceeds the p99 budget",
+		Help:        "Retries with backoff push tail latency past the budget. Shorten backoffs or retry at fewer hops.",
+		Params:      []string{"p99_budget"}},
+	{ID: "retry-tail-amplification", Severity: "warning",
+		Description: "Path tail latency with every retry at each hop's p99 (plus backoff) exceeds the p99 budget",
+		Help:        "Retries stack up at every hop of the path. Retry at one layer only, or lower the retry counts.",
+		Params:      []string{"p99_budget"}},
+	{ID: "timeout-no-headroom", Severity: "warning",
+		Description: "Timeout barely above the call's declared processing_time",
+		Help:        "Ordinary slow requests time out. Leave headroom above the processing time.",
+		Params:      []string{"timeout_overhead_factor"}},
+	{ID: "timeout-below-min-processing", Severity: "error",
+		Description: "Call timeout below the target's min_processing_time",
+		Help:        "The call can never succeed. Raise the timeout above the target's minimum processing time."},
+	{ID: "retry-amplification", Severity: "error",
+		Description: "Multiplicative retry factor above the threshold along a path",
+		Help:        "Retries at several layers multiply. Retry at one layer only; cut retries on the primary contributor first.",
+		Params:      []string{"amplification_threshold"}},
+	{ID: "unbounded-retry", Severity: "error",
+		Description: "Retries on a call with no timeout",
+		Help:        "An attempt that hangs blocks every retry after it. Give the retried call a timeout."},
+	{ID: "first-retry-impossible", Severity: "warning",
+		Description: "First backoff_base wait exceeds what the caller's timeout leaves after one attempt",
+		Help:        "The caller gives up before the first retry starts. Shorten the backoff or drop the retries."},
+	{ID: "budget-allocation", Severity: "warning",
+		Description: "One hop of the critical path takes most of the entry budget (or of the path's worst case)",
+		Help:        "The other hops were squeezed to fit. Rebalance the timeouts along the path.",
+		Params:      []string{"entry_timeout", "budget_max_share"}},
+	{ID: "wasted-leaf-retry", Severity: "warning",
+		Description: "Leaf call retries that would start after the path's remaining deadline",
+		Help:        "Those retries run after the request has already failed upstream. Drop them or tighten the leaf timeout.",
+		Params:      []string{"entry_timeout"}},
+	{ID: "missing-bulkhead", Severity: "warning",
+		Description: "Retried call without max_concurrency",
+		Help:        "Retries against a slow dependency pile up in-flight requests. Cap them with max_concurrency."},
+	{ID: "retry-without-cb", Severity: "warning",
+		Description: "Retries configured without circuit breaker",
+		Help:        "Add a circuit breaker so retries stop when the dependency is down."},
+	{ID: "write-between-retried-reads", Severity: "warning",
+		Description: "Path shaped retried read → unretried non-idempotent write → retried read",
+		Help:        "A retried read can observe a half-applied write. Make the write idempotent or re-read after it succeeds."},
+	{ID: "non-idempotent-retry", Severity: "error",
+		Description: "Retrying POST/PATCH/DELETE requests",
+		Help:        "Stop retrying the call or make it idempotent (e.g. with an idempotency key)."},
+	{ID: "unkeyed-mutating-retry", Severity: "warning",
+		Description: "Retried POST/PUT/PATCH/DELETE endpoint on a service without supports_idempotency_key: true",
+		Help:        "A retry after a lost response repeats the write. Send an idempotency key the target deduplicates on, or stop retrying."},
+	{ID: "backoff-no-jitter", Severity: "warning",
+		Description: "Retries without jitter (thundering herd)",
+		Help:        "Add jitter to the retry backoff."},
+	{ID: "zero-backoff-retry", Severity: "warning",
+		Description: "Retries with no backoff_base (immediate retries)",
+		Help:        "Set a backoff_base so retries are not immediate."},
+	{ID: "async-retry-mismatch", Severity: "info",
+		Description: "Retries configured on an async (queued) call",
+		Help:        "The queue redelivers already. Let it handle retries instead of the caller."},
+	{ID: "jitter-no-backoff", Severity: "info",
+		Description: "Jitter configured on a call that never retries",
+		Help:        "The jitter setting has no effect. Remove it or configure retries."},
+	{ID: "entry-timeout-missing", Severity: "error",
+		Description: "A call out of the entry service (or -root) has no timeout",
+		Help:        "Set a timeout on the entry call.",
+		Params:      []string{"entry_timeout"}},
+	{ID: "in-transaction-retry", Severity: "error",
+		Description: "Retried call marked in_transaction: true (holds DB locks across attempts)",
+		Help:        "Move the call out of the transaction or stop retrying it."},
+	{ID: "cb-counts-per-call", Severity: "warning",
+		Description: "Breaker declared cb_counts: per-call on a heavily retried call (it may never trip)",
+		Help:        "Count failures per attempt, or retry less.",
+		Params:      []string{"cb_counts_per_call_min_retries"}},
+	{ID: "cb-threshold-unreachable", Severity: "warning",
+		Description: "Breaker with cb_window: per-request whose cb_failure_threshold exceeds the failures one request can produce",
+		Help:        "The breaker never opens. Lower the threshold, count per attempt, or share the breaker across requests."},
+	{ID: "cb-open-window-exceeds-entry", Severity: "warning",
+		Description: "Circuit breaker's cb_open_timeout is longer than the entry timeout",
+		Help:        "Requests fail fast for longer than any of them lives. Shorten the open window.",
+		Params:      []string{"entry_timeout"}},
+	{ID: "cb-half-open-probes", Severity: "warning",
+		Description: "Circuit breaker allows too many half-open probes",
+		Help:        "A recovering dependency is flooded by probes. Lower cb_half_open_requests.",
+		Params:      []string{"cb_half_open_max_requests", "cb_half_open_require_declared"}},
+	{ID: "insecure-boundary-call", Severity: "error",
+		Description: "Call with crosses_boundary: true but not secure: true",
+		Help:        "Encrypt and authenticate the call (e.g. mTLS)."},
+	{ID: "read-after-write-retry", Severity: "warning",
+		Description: "Retried read of data written earlier on the path",
+		Help:        "A retry may read stale data. Read from the writer or wait for replication."},
+	{ID: "uniform-config", Severity: "info",
+		Description: "Many circuit-breaker edges share identical resilience config (copy-paste smell)",
+		Help:        "Tune each call's timeout and retries to its dependency.",
+		Params:      []string{"uniform_config_min_cluster"}},
+	{ID: "self-call", Severity: "error/warning",
+		Description: "A service calls itself, e.g. through one of its aliases (error when retried)",
+		Help:        "Each request can recurse without bound. Break the loop, or at least stop retrying it."},
+	{ID: "mutual-retry", Severity: "error",
+		Description: "A→B and B→A both retried (retries bounce between the pair)",
+		Help:        "Retry in one direction only."},
+	{ID: "bidirectional-timeout-asymmetry", Severity: "warning",
+		Description: "A→B and B→A timeouts differ widely",
+		Help:        "Check that both directions were sized deliberately.",
+		Params:      []string{"bidirectional_max_ratio"}},
+	{ID: "scatter-gather-timeout", Severity: "warning",
+		Description: "Sibling calls of a fan-out differ widely in timeout",
+		Help:        "The fan-out waits for its slowest call. Align the sibling timeouts or set an aggregate_timeout.",
+		Params:      []string{"scatter_gather_max_ratio"}},
+	{ID: "fan-out-missing-timeout", Severity: "error",
+		Description: "A sibling call of a fan-out has no timeout (blocks the whole aggregation)",
+		Help:        "Give every awaited sibling call a timeout."},
+	{ID: "missing-aggregate-deadline", Severity: "warning",
+		Description: "Fan-out service without aggregate_timeout whose calls allow a long wait",
+		Help:        "Set an aggregate_timeout bounding the whole fan-out.",
+		Params:      []string{"aggregate_large_timeout"}},
+	{ID: "repeated-service-on-path", Severity: "warning",
+		Description: "Service where one request's call chains reconverge (e.g. the bottom of a diamond)",
+		Help:        "The service's load and failures count more than once per request. Collapse the duplicate chains."},
+	{ID: "retry-into-fixed-capacity", Severity: "warning",
+		Description: "Retried call into a service declared autoscaling: false",
+		Help:        "Retries multiply load the target cannot scale to absorb. Cut retries or add a circuit breaker."},
+	{ID: "retry-deterministic-errors", Severity: "warning",
+		Description: "Retried call into a service declared deterministic_errors: true",
+		Help:        "Every retry fails the same way. Stop retrying the call."},
+	{ID: "retries-exceed-replicas", Severity: "warning",
+		Description: "More attempts than the target has replicas",
+		Help:        "Later attempts land on replicas that already failed. Retry less or add replicas."},
+	{ID: "unknown-target-endpoint", Severity: "warning",
+		Description: "Call's method and endpoint match none of the target service's declared endpoints",
+		Help:        "Fix the call's endpoint or declare it on the target.",
+		Params:      []string{"endpoint_param_patterns"}},
+	{ID: "inconsistent-idempotency", Severity: "warning",
+		Description: "Same method and endpoint shape declared idempotent on one call but not another",
+		Help:        "One declaration is wrong. Make them agree.",
+		Params:      []string{"endpoint_param_patterns"}},
+	{ID: "conflicting-idempotency", Severity: "warning",
+		Description: "One caller retries a service as idempotent while another treats it as non-idempotent",
+		Help:        "Settle whether the target is idempotent and configure every caller to match."},
+	{ID: "idempotent-method-mismatch", Severity: "warning",
+		Description: "POST/PATCH call explicitly marked idempotent: true",
+		Help:        "Check the operation really is idempotent (e.g. it takes an idempotency key).",
+		Params:      []string{"idempotent_mismatch_methods"}},
+	{ID: "retry-over-slow-downstream", Severity: "warning",
+		Description: "Retried call into a service with a long unretried hop",
+		Help:        "Each retry re-runs the slow hop. Retry closer to the slow dependency or not at all.",
+		Params:      []string{"slow_downstream_timeout"}},
+	{ID: "backend-request-cap", Severity: "error",
+		Description: "One request can send too many worst-case requests to a single backend",
+		Help:        "Reduce retries and fan-out toward the backend.",
+		Params:      []string{"backend_request_cap"}},
+	{ID: "global-request-cap", Severity: "error",
+		Description: "One request can generate too many worst-case backend requests in total",
+		Help:        "Reduce retries and fan-out along the request tree.",
+		Params:      []string{"global_request_cap"}},
+	{ID: "retry-on-health-check", Severity: "warning",
+		Description: "Retries on a kind: health call",
+		Help:        "Retried health checks hide failures. Do not retry them."},
+	{ID: "retry-ignores-backpressure", Severity: "warning",
+		Description: "Retried call declared honors_retry_after: false",
+		Help:        "Retries ignore the target's request to back off. Wait as long as Retry-After asks before retrying."},
+	{ID: "unobserved-resilience", Severity: "info",
+		Description: "Call with retries or a timeout declared observable: false (no metrics or tracing)",
+		Help:        "Nobody sees when its retries fire or its timeout hits. Add metrics or tracing to the call."},
+	{ID: "chokepoint", Severity: "warning",
+		Description: "A service with high fan-in and high fan-out",
+		Help:        "Its failure spreads both ways. Give it extra replicas, bulkheads and circuit breakers, or split it.",
+		Params:      []string{"chokepoint_min_callers", "chokepoint_min_callees"}},
+	{ID: "single-point-of-failure", Severity: "warning",
+		Description: "A service whose loss disconnects the topology runs replicas: 1",
+		Help:        "Run more than one replica of the service."},
+	{ID: "tier-violation", Severity: "error/warning",
+		Description: "Disallowed tier crossing, or data-tier call without circuit breaker",
+		Help:        "Route the call through an allowed tier, or add the required circuit breaker."},
+	{ID: "orphaned-retry", Severity: "warning",
+		Description: "Downstream retries (incl. backoff) outlast the upstream timeout, where effective-timeout-inversion does not already report it",
+		Help:        "Those retries continue after the caller has given up. Cut them or raise the upstream timeout."},
+	{ID: "no-entry-point", Severity: "info",
+		Description: "Every service is called by another (fully cyclic topology)",
+		Help:        "Pick the entry with -root or break the cycle."},
+	{ID: "shared-backend", Severity: "warning/info",
+		Description: "In -batch mode, a service called from more than one topology file",
+		Help:        "Each team sees only its share of the load. Agree on a combined request budget.",
+		Params:      []string{"backend_request_cap"}},
+}
+
+// ruleParam is a configurable parameter in the -dump-rules output. Default
+// is the value in DefaultOptions, encoded as in -print-config.
+type ruleParam struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Default json.RawMessage `json:"default"`
+}
+
+// ruleEntry is one rule in the -dump-rules output.
+type ruleEntry struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description"`
+	Severity    string      `json:"severity"`
+	OptIn       bool        `json:"opt_in"`
+	Params      []ruleParam `json:"params"`
+	Help        string      `json:"help"`
+}
+
+// paramTypes names the JSON types of RuleConfig fields.
+var paramTypes = map[reflect.Type]string{
+	reflect.TypeOf(0):            "integer",
+	reflect.TypeOf(0.0):          "number",
+	reflect.TypeOf(false):        "boolean",
+	reflect.TypeOf(Duration(0)):  "duration",
+	reflect.TypeOf([]string{}):   "string list",
+	reflect.TypeOf([]Duration{}): "duration list",
+}
+
+// catalogRule returns the catalog entry for rule, if it has one. Rules
+// reported by plugins have none.
+func catalogRule(rule string) (ruleDoc, bool) {
+	for _, d := range ruleCatalog {
+		if d.ID == rule {
+			return d, true
+		}
+	}
+	return ruleDoc{}, false
+}
+
+// ruleHelp returns the catalog Help for rule, or "" for a rule without an
+// entry.
+func ruleHelp(rule string) string {
+	d, _ := catalogRule(rule)
+	return d.Help
+}
+
+// ruleEntries builds the catalog with each parameter's type and default
+// read from RuleConfig and DefaultOptions.
+func ruleEntries() ([]ruleEntry, error) {
+	defaults := reflect.ValueOf(DefaultOptions().Rules)
+	fields := make(map[string]int)
+	for i := 0; i < defaults.NumField(); i++ {
+		name, _, _ := strings.Cut(defaults.Type().Field(i).Tag.Get("json"), ",")
+		fields[name] = i
+	}
+	entries := make([]ruleEntry, 0, len(ruleCatalog))
+	for _, doc := range ruleCatalog {
+		_, optIn := optInRules[doc.ID]
+		e := ruleEntry{ID: doc.ID, Description: doc.Description, Severity: doc.Severity,
+			OptIn: optIn, Params: []ruleParam{}, Help: doc.Help}
+		for _, name := range doc.Params {
+			i, ok := fields[name]
+			if !ok {
+				return nil, fmt.Errorf("rule %s: unknown parameter %q", doc.ID, name)
+			}
+			def, err := json.Marshal(defaults.Field(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			e.Params = append(e.Params, ruleParam{Name: name, Type: paramTypes[defaults.Field(i).Type()], Default: def})
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// dumpRules writes the rule catalog in format (only "json" so far).
+func dumpRules(format string, w io.Writer) error {
+	if format != "json" {
+		return fmt.Errorf("unknown -dump-rules format %q (want json)", format)
+	}
+	entries, err := ruleEntries()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(entries)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cascadeguard/cascadeguard/output"
+)
+
+// loadBaseline reads the findings of an earlier run from its SARIF report.
+func loadBaseline(path string) ([]output.Violation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vs, err := output.ReadSARIF(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vs, nil
+}
+
+// tagAges sets each finding's Age: new if d reports it as new, otherwise
+// pre-existing.
+func tagAges(findings []Finding, d output.FindingsDiff) {
+	left := make(map[string]int)
+	for _, v := range d.New {
+		left[output.Fingerprint(v)]++
+	}
+	for i := range findings {
+		findings[i].Age = output.AgePreExisting
+		if fp := findings[i].fingerprint(); left[fp] > 0 {
+			left[fp]--
+			findings[i].Age = output.AgeNew
+		}
+	}
+}
+
+// onlyNew keeps the findings tagged new.
+func onlyNew(findings []Finding) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if f.Age == output.AgeNew {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// writeRegressionSummary writes the counts of d followed by the new and the
+// resolved findings.
+func writeRegressionSummary(d output.FindingsDiff, baseline string, w io.Writer) error {
+	fmt.Fprintf(w, "Compared with %s: %d new, %d resolved, %d unchanged\n",
+		baseline, len(d.New), len(d.Resolved), len(d.Unchanged))
+	for _, sec := range []struct {
+		title string
+		vs    []output.Violation
+	}{{"New", d.New}, {"Resolved", d.Resolved}} {
+		if len(sec.vs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", sec.title)
+		for _, v := range sec.vs {
+			line := fmt.Sprintf("  [%s][%s] %s", severityTag(v.Severity), v.Rule, v.Message)
+			if len(v.Path) > 0 {
+				line += " (" + strings.Join(v.Path, " → ") + ")"
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -5,17 +5,43 @@ import (
 	"go/parser"
 	"go/token"
 	"strconv"
+	"strings"
 )
 
 // ExtractedConfig holds a single timeout or retry configuration found in source.
 type ExtractedConfig struct {
 	File       string
 	Line       int
-	Type       string // e.g. "http-client-timeout", "context-timeout", "grpc-timeout", "retry-config", "gokit-retry"
+	Func       string // enclosing function, "Type.Method" for methods; empty at package level
+	Type       string // e.g. "http-client-timeout", "context-timeout", "grpc-timeout", "retry-config", "gokit-retry", "manual-sleep-backoff", "inherited-timeout", "transport-retry", "grpc-no-deadline", "timeout-mismatch", "layered-retry"
 	TimeoutMs  int64
 	MaxRetries int
+	BackoffMs  int64 // fixed delay between attempts, e.g. from a hand-rolled sleep loop
+	// ClientTimeoutMs is the http.Client timeout of a timeout-mismatch,
+	// whose TimeoutMs is the context timeout.
+	ClientTimeoutMs int64
+	// Confidence is how sure the extractor is that the config is what it
+	// appears to be, from 0 to 1: ConfidenceExact, ConfidenceHeuristic or
+	// ConfidenceUnresolved.
+	Confidence float64
+	// Severity is set on configs that are problems in themselves rather
+	// than values to check: "error" for a grpc-no-deadline call, "warning"
+	// for a timeout-mismatch or a layered-retry.
+	Severity string
 }
 
+// Confidence levels attached to extracted configs.
+const (
+	// ConfidenceExact: a known API with every value evaluated from literals.
+	ConfidenceExact = 1.0
+	// ConfidenceHeuristic: inferred from code shape (a sleep inside a loop
+	// with a network call, a context passed through) rather than an API.
+	ConfidenceHeuristic = 0.6
+	// ConfidenceUnresolved: a value that could not be evaluated (a
+	// variable, function call or config lookup), so it reads as zero.
+	ConfidenceUnresolved = 0.5
+)
+
 // ExtractFromFile parses a Go source file and extracts timeout/retry configs.
 func ExtractFromFile(filename string) ([]ExtractedConfig, error) {
 	fset := token.NewFileSet()
@@ -23,22 +49,7 @@ func ExtractFromFile(filename string) ([]ExtractedConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	var configs []ExtractedConfig
-
-	ast.Inspect(f, func(n ast.Node) bool {
-		switch node := n.(type) {
-		case *ast.CompositeLit:
-			if cfg := matchHTTPClient(fset, filename, node); cfg != nil {
-				configs = append(configs, *cfg)
-			}
-		case *ast.CallExpr:
-			configs = append(configs, matchCallExpr(fset, filename, node)...)
-		}
-		return true
-	})
-
-	return configs, nil
+	return extract(fset, filename, f), nil
 }
 
 // ExtractFromSource parses Go source bytes (useful for testing without files).
@@ -48,46 +59,146 @@ func ExtractFromSource(filename string, src []byte) ([]ExtractedConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	return extract(fset, filename, f), nil
+}
 
+// extract walks a parsed file and collects every recognised config.
+func extract(fset *token.FileSet, filename string, f *ast.File) []ExtractedConfig {
 	var configs []ExtractedConfig
+	sleeps := make(map[token.Pos]bool) // time.Sleep calls already reported by an enclosing loop
+	clients := packageClients(f)
 
-	ast.Inspect(f, func(n ast.Node) bool {
+	visit := func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.CompositeLit:
-			if cfg := matchHTTPClient(fset, filename, node); cfg != nil {
-				configs = append(configs, *cfg)
-			}
+			configs = append(configs, matchHTTPClient(fset, filename, node)...)
 		case *ast.CallExpr:
 			configs = append(configs, matchCallExpr(fset, filename, node)...)
+		case *ast.FuncDecl:
+			configs = append(configs, matchInheritedContext(fset, filename, node)...)
+			configs = append(configs, matchUnboundedGRPC(fset, filename, node)...)
+			configs = append(configs, matchTimeoutMismatch(fset, filename, node, clients)...)
+		case *ast.ForStmt:
+			configs = append(configs, matchSleepBackoff(fset, filename, node.Body, sleeps)...)
+		case *ast.RangeStmt:
+			configs = append(configs, matchSleepBackoff(fset, filename, node.Body, sleeps)...)
 		}
 		return true
-	})
+	}
+	for _, decl := range f.Decls {
+		start := len(configs)
+		ast.Inspect(decl, visit)
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			for i := start; i < len(configs); i++ {
+				configs[i].Func = funcName(fn)
+			}
+		}
+	}
+
+	for i := range configs {
+		configs[i].Confidence = confidence(configs[i])
+	}
+	for _, l := range FindLayeredRetries(configs) {
+		configs = append(configs, l.config())
+	}
+	return configs
+}
+
+// funcName returns "Name" for functions and "Type.Name" for methods.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
 
-	return configs, nil
+// confidence scores a config by how it was found: heuristic matches score
+// lower than API matches, and any value that did not evaluate lowers the
+// score to ConfidenceUnresolved.
+func confidence(c ExtractedConfig) float64 {
+	switch c.Type {
+	case "inherited-timeout", "grpc-no-deadline", "timeout-mismatch":
+		return ConfidenceHeuristic
+	case "manual-sleep-backoff":
+		if c.BackoffMs == 0 {
+			return ConfidenceUnresolved
+		}
+		return ConfidenceHeuristic
+	case "retry-config", "transport-retry":
+		if c.MaxRetries == 0 {
+			return ConfidenceUnresolved
+		}
+	case "gokit-retry":
+		if c.MaxRetries == 0 || c.TimeoutMs == 0 {
+			return ConfidenceUnresolved
+		}
+	default:
+		if c.TimeoutMs == 0 {
+			return ConfidenceUnresolved
+		}
+	}
+	return ConfidenceExact
 }
 
-// matchHTTPClient detects &http.Client{Timeout: <expr>} or http.Client{Timeout: <expr>}.
-func matchHTTPClient(fset *token.FileSet, filename string, cl *ast.CompositeLit) *ExtractedConfig {
+// matchHTTPClient detects &http.Client{Timeout: <expr>} or
+// http.Client{Timeout: <expr>}, and a Transport whose type or constructor
+// is named like a retrying transport (e.g. &RetryTransport{...}).
+func matchHTTPClient(fset *token.FileSet, filename string, cl *ast.CompositeLit) []ExtractedConfig {
 	if !isSel(cl.Type, "http", "Client") {
 		return nil
 	}
+	var out []ExtractedConfig
 	for _, elt := range cl.Elts {
 		kv, ok := elt.(*ast.KeyValueExpr)
 		if !ok {
 			continue
 		}
 		key, ok := kv.Key.(*ast.Ident)
-		if !ok || key.Name != "Timeout" {
+		if !ok {
 			continue
 		}
-		return &ExtractedConfig{
-			File:      filename,
-			Line:      fset.Position(cl.Pos()).Line,
-			Type:      "http-client-timeout",
-			TimeoutMs: evalDuration(kv.Value),
+		switch {
+		case key.Name == "Timeout":
+			out = append(out, ExtractedConfig{
+				File:      filename,
+				Line:      fset.Position(cl.Pos()).Line,
+				Type:      "http-client-timeout",
+				TimeoutMs: evalDuration(kv.Value),
+			})
+		case key.Name == "Transport" && mentionsRetry(kv.Value):
+			out = append(out, ExtractedConfig{
+				File: filename,
+				Line: fset.Position(cl.Pos()).Line,
+				Type: "transport-retry",
+			})
 		}
 	}
-	return nil
+	return out
+}
+
+// mentionsRetry reports whether the type or function named by a transport
+// expression contains "retry" (e.g. &RetryTransport{}, rehttp.NewRetryTransport()).
+func mentionsRetry(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		return mentionsRetry(e.X)
+	case *ast.CompositeLit:
+		return mentionsRetry(e.Type)
+	case *ast.CallExpr:
+		return mentionsRetry(e.Fun)
+	case *ast.SelectorExpr:
+		return mentionsRetry(e.Sel)
+	case *ast.Ident:
+		return strings.Contains(strings.ToLower(e.Name), "retry")
+	}
+	return false
 }
 
 // matchCallExpr detects context.WithTimeout, grpc.WithTimeout, retry.Do, and go-kit Retry.
@@ -152,6 +263,16 @@ func matchCallExpr(fset *token.FileSet, filename string, call *ast.CallExpr) []E
 		}
 		out = append(out, cfg)
 
+	// retryablehttp.NewClient(): an http client that retries up to RetryMax
+	// (4 by default) on its own.
+	case pkg == "retryablehttp" && fn == "NewClient":
+		out = append(out, ExtractedConfig{
+			File:       filename,
+			Line:       line,
+			Type:       "transport-retry",
+			MaxRetries: 4,
+		})
+
 	// go-kit: lb.Retry(maxRetries, timeout, ...) or sd.Retry(...)
 	case (pkg == "lb" || pkg == "sd") && fn == "Retry" && len(call.Args) >= 2:
 		out = append(out, ExtractedConfig{
@@ -166,6 +287,419 @@ func matchCallExpr(fset *token.FileSet, filename string, call *ast.CallExpr) []E
 	return out
 }
 
+// matchInheritedContext reports calls that pass a context.Context parameter
+// straight through. Their effective timeout is whatever the caller set, so
+// they are "inherited" rather than missing. Functions that derive a fresh
+// deadline from the parameter (context.WithTimeout / WithDeadline) are
+// skipped: those are reported as context-timeout instead.
+func matchInheritedContext(fset *token.FileSet, filename string, fn *ast.FuncDecl) []ExtractedConfig {
+	if fn.Body == nil {
+		return nil
+	}
+	ctxParams := make(map[string]bool)
+	for _, field := range fn.Type.Params.List {
+		if !isSel(field.Type, "context", "Context") {
+			continue
+		}
+		for _, name := range field.Names {
+			ctxParams[name.Name] = true
+		}
+	}
+	if len(ctxParams) == 0 {
+		return nil
+	}
+
+	var passthrough []*ast.CallExpr
+	fresh := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if isSel(call.Fun, "context", "WithTimeout") || isSel(call.Fun, "context", "WithDeadline") {
+			if len(call.Args) > 0 && isIdentIn(call.Args[0], ctxParams) {
+				fresh = true
+			}
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == "context" {
+				return true
+			}
+		}
+		for _, arg := range call.Args {
+			if isIdentIn(arg, ctxParams) {
+				passthrough = append(passthrough, call)
+				break
+			}
+		}
+		return true
+	})
+	if fresh {
+		return nil
+	}
+
+	out := make([]ExtractedConfig, 0, len(passthrough))
+	for _, call := range passthrough {
+		out = append(out, ExtractedConfig{
+			File: filename,
+			Line: fset.Position(call.Pos()).Line,
+			Type: "inherited-timeout",
+		})
+	}
+	return out
+}
+
+// grpcCallNames are grpc.ClientConn methods that issue a call on any
+// receiver.
+var grpcCallNames = map[string]bool{"Invoke": true, "NewStream": true}
+
+// matchUnboundedGRPC reports gRPC calls made with context.Background() or
+// context.TODO(), passed directly or through a variable still bound to one.
+// Without a deadline such a call can wait forever. A call is taken to be
+// gRPC when it is Invoke/NewStream or a method on a client built by a
+// generated New<Service>Client constructor. A context rebound through
+// context.WithTimeout (or any other call) no longer counts as background.
+func matchUnboundedGRPC(fset *token.FileSet, filename string, fn *ast.FuncDecl) []ExtractedConfig {
+	if fn.Body == nil {
+		return nil
+	}
+	background := make(map[string]bool) // context variables bound to Background/TODO
+	clients := make(map[string]bool)    // variables and fields holding generated gRPC clients
+	var out []ExtractedConfig
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				name := exprName(lhs)
+				if name == "" {
+					continue
+				}
+				var rhs ast.Expr
+				switch {
+				case len(node.Rhs) == len(node.Lhs):
+					rhs = node.Rhs[i]
+				case i == 0 && len(node.Rhs) == 1:
+					rhs = node.Rhs[0]
+				default:
+					delete(background, name)
+					continue
+				}
+				background[name] = isBackgroundContext(rhs)
+				if call, ok := rhs.(*ast.CallExpr); ok && isClientConstructor(call) {
+					clients[name] = true
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || len(node.Args) == 0 {
+				return true
+			}
+			if !grpcCallNames[sel.Sel.Name] && !clients[exprName(sel.X)] {
+				return true
+			}
+			ctx := node.Args[0]
+			if isBackgroundContext(ctx) || background[exprName(ctx)] {
+				out = append(out, ExtractedConfig{
+					File:     filename,
+					Line:     fset.Position(node.Pos()).Line,
+					Type:     "grpc-no-deadline",
+					Severity: "error",
+				})
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// packageClients returns the timeouts of the package-level variables bound
+// to an http.Client literal with a Timeout, in milliseconds.
+func packageClients(f *ast.File) map[string]int64 {
+	clients := make(map[string]int64)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i < len(vs.Values) {
+					if ms := clientTimeout(vs.Values[i]); ms > 0 {
+						clients[name.Name] = ms
+					}
+				}
+			}
+		}
+	}
+	return clients
+}
+
+// clientTimeout returns the Timeout in milliseconds of an http.Client
+// literal (or its address), or 0.
+func clientTimeout(expr ast.Expr) int64 {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	cl, ok := expr.(*ast.CompositeLit)
+	if !ok || !isSel(cl.Type, "http", "Client") {
+		return 0
+	}
+	for _, elt := range cl.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok && exprName(kv.Key) == "Timeout" {
+			return evalDuration(kv.Value)
+		}
+	}
+	return 0
+}
+
+// matchTimeoutMismatch reports client.Do(req) calls where both a context
+// timeout (a context.WithTimeout context attached to req through
+// http.NewRequestWithContext or req.WithContext) and the client's Timeout
+// apply, with different values. The shorter one always wins, so the other
+// is dead configuration. Clients are matched by variable name, among the
+// package-level ones and those bound in the function.
+func matchTimeoutMismatch(fset *token.FileSet, filename string, fn *ast.FuncDecl, pkgClients map[string]int64) []ExtractedConfig {
+	if fn.Body == nil {
+		return nil
+	}
+	clients := make(map[string]int64)
+	for name, ms := range pkgClients {
+		clients[name] = ms
+	}
+	contexts := make(map[string]int64)  // context variables -> WithTimeout ms
+	requests := make(map[string]string) // request variables -> their context variable
+	// requestContext returns the context variable attached to a request
+	// expression, or "".
+	requestContext := func(expr ast.Expr) string {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return requests[exprName(expr)]
+		}
+		if isSel(call.Fun, "http", "NewRequestWithContext") && len(call.Args) > 0 {
+			return exprName(call.Args[0])
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "WithContext" && len(call.Args) == 1 {
+			return exprName(call.Args[0])
+		}
+		return ""
+	}
+	var out []ExtractedConfig
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Rhs) != 1 && len(node.Rhs) != len(node.Lhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				name := exprName(lhs)
+				if name == "" || len(node.Rhs) == 1 && i > 0 {
+					continue
+				}
+				rhs := node.Rhs[i]
+				delete(clients, name)
+				delete(contexts, name)
+				delete(requests, name)
+				if ms := clientTimeout(rhs); ms > 0 {
+					clients[name] = ms
+				}
+				if call, ok := rhs.(*ast.CallExpr); ok && isSel(call.Fun, "context", "WithTimeout") && len(call.Args) == 2 {
+					if ms := evalDuration(call.Args[1]); ms > 0 {
+						contexts[name] = ms
+					}
+				}
+				if ctx := requestContext(rhs); ctx != "" {
+					requests[name] = ctx
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Do" || len(node.Args) != 1 {
+				return true
+			}
+			clientMs := clients[exprName(sel.X)]
+			ctxMs := contexts[requestContext(node.Args[0])]
+			if clientMs > 0 && ctxMs > 0 && clientMs != ctxMs {
+				out = append(out, ExtractedConfig{
+					File:            filename,
+					Line:            fset.Position(node.Pos()).Line,
+					Type:            "timeout-mismatch",
+					TimeoutMs:       ctxMs,
+					ClientTimeoutMs: clientMs,
+					Severity:        "warning",
+				})
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// isBackgroundContext reports whether expr is context.Background() or
+// context.TODO().
+func isBackgroundContext(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	return ok && len(call.Args) == 0 &&
+		(isSel(call.Fun, "context", "Background") || isSel(call.Fun, "context", "TODO"))
+}
+
+// isClientConstructor matches a protoc-generated client constructor,
+// pb.New<Service>Client(conn).
+func isClientConstructor(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	name := sel.Sel.Name
+	return len(name) > len("NewClient") && strings.HasPrefix(name, "New") && strings.HasSuffix(name, "Client")
+}
+
+// exprName returns the name an identifier or field selector refers to
+// (x for x, f for s.f), or "" for any other expression.
+func exprName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+func isIdentIn(expr ast.Expr, names map[string]bool) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && names[id.Name]
+}
+
+// networkCallNames are method/function names treated as outbound network
+// calls when looking for hand-rolled retry loops.
+var networkCallNames = map[string]bool{
+	"Do": true, "Get": true, "Post": true, "PostForm": true, "Head": true,
+	"Invoke": true, "NewStream": true, "Dial": true, "DialContext": true,
+}
+
+// matchSleepBackoff detects a loop body that both makes a network call and
+// calls time.Sleep: a hand-rolled retry with a fixed, jitterless delay. Each
+// time.Sleep is reported once, even when loops are nested.
+func matchSleepBackoff(fset *token.FileSet, filename string, body *ast.BlockStmt, seen map[token.Pos]bool) []ExtractedConfig {
+	var sleepCalls []*ast.CallExpr
+	var hasNetworkCall bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false // closures run on their own schedule
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if isSel(call.Fun, "time", "Sleep") {
+			sleepCalls = append(sleepCalls, call)
+		} else if sel, ok := call.Fun.(*ast.SelectorExpr); ok && networkCallNames[sel.Sel.Name] {
+			hasNetworkCall = true
+		}
+		return true
+	})
+	if !hasNetworkCall {
+		return nil
+	}
+
+	var out []ExtractedConfig
+	for _, call := range sleepCalls {
+		if seen[call.Pos()] || len(call.Args) != 1 {
+			continue
+		}
+		seen[call.Pos()] = true
+		out = append(out, ExtractedConfig{
+			File:      filename,
+			Line:      fset.Position(call.Pos()).Line,
+			Type:      "manual-sleep-backoff",
+			BackoffMs: evalDuration(call.Args[0]),
+		})
+	}
+	return out
+}
+
+// LayeredRetry is a function that retries the same call twice over: once in
+// its HTTP client (Transport) and again in application code (App). The
+// attempts multiply rather than add.
+type LayeredRetry struct {
+	File      string
+	Func      string
+	Transport ExtractedConfig // a "transport-retry" config
+	App       ExtractedConfig // a "retry-config", "gokit-retry" or "manual-sleep-backoff" config
+}
+
+// appRetryTypes are the config types that retry in application code.
+var appRetryTypes = map[string]bool{"retry-config": true, "gokit-retry": true, "manual-sleep-backoff": true}
+
+// FindLayeredRetries reports each function in which the extractor found
+// both a retrying HTTP client and an application-level retry, pairing the
+// first of each. Package-level configs are not associated with any
+// function and are ignored.
+func FindLayeredRetries(configs []ExtractedConfig) []LayeredRetry {
+	type scope struct{ file, fn string }
+	transports := make(map[scope]ExtractedConfig)
+	apps := make(map[scope]ExtractedConfig)
+	var order []scope
+	for _, c := range configs {
+		if c.Func == "" {
+			continue
+		}
+		s := scope{c.File, c.Func}
+		_, seenT := transports[s]
+		_, seenA := apps[s]
+		if !seenT && !seenA {
+			order = append(order, s)
+		}
+		switch {
+		case c.Type == "transport-retry" && !seenT:
+			transports[s] = c
+		case appRetryTypes[c.Type] && !seenA:
+			apps[s] = c
+		}
+	}
+	var out []LayeredRetry
+	for _, s := range order {
+		t, okT := transports[s]
+		a, okA := apps[s]
+		if okT && okA {
+			out = append(out, LayeredRetry{File: s.file, Func: s.fn, Transport: t, App: a})
+		}
+	}
+	return out
+}
+
+// Attempts returns the worst-case number of attempts the layers make
+// together, (1 + transport retries) × (1 + app retries), or 0 when either
+// retry count is unknown.
+func (l LayeredRetry) Attempts() int {
+	if l.Transport.MaxRetries == 0 || l.App.MaxRetries == 0 {
+		return 0
+	}
+	return (1 + l.Transport.MaxRetries) * (1 + l.App.MaxRetries)
+}
+
+// config returns l as a "layered-retry" finding at the application retry.
+// Its MaxRetries is the retries the layers make together, Attempts() - 1, or
+// 0 when either count is unknown; its confidence is the lower of the two
+// layers'.
+func (l LayeredRetry) config() ExtractedConfig {
+	retries := 0
+	if n := l.Attempts(); n > 0 {
+		retries = n - 1
+	}
+	return ExtractedConfig{
+		File:       l.File,
+		Line:       l.App.Line,
+		Func:       l.Func,
+		Type:       "layered-retry",
+		MaxRetries: retries,
+		Confidence: min(l.Transport.Confidence, l.App.Confidence),
+		Severity:   "warning",
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Duration / integer evaluation helpers
 // ---------------------------------------------------------------------------
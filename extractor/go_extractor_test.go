@@ -209,3 +209,229 @@ func TestEvalDurationFromSource(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		typ  string
+		want float64
+	}{
+		{
+			name: "literal timeout",
+			src:  `package p; var _ = &http.Client{Timeout: 5 * time.Second}`,
+			typ:  "http-client-timeout",
+			want: ConfidenceExact,
+		},
+		{
+			name: "timeout from a variable",
+			src:  `package p; var _ = &http.Client{Timeout: cfg.Timeout}`,
+			typ:  "http-client-timeout",
+			want: ConfidenceUnresolved,
+		},
+		{
+			name: "retry attempts from a variable",
+			src:  `package p; func f() { retry.Do(call, retry.Attempts(n)) }`,
+			typ:  "retry-config",
+			want: ConfidenceUnresolved,
+		},
+		{
+			name: "hand-rolled sleep loop",
+			src:  `package p; func f() { for { c.Get(u); time.Sleep(time.Second) } }`,
+			typ:  "manual-sleep-backoff",
+			want: ConfidenceHeuristic,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := ExtractFromSource("test.go", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			c := findByType(configs, tt.typ)
+			if c == nil {
+				t.Fatalf("expected %s, got %+v", tt.typ, configs)
+			}
+			if c.Confidence != tt.want {
+				t.Errorf("want confidence %v, got %v", tt.want, c.Confidence)
+			}
+		})
+	}
+}
+
+// ----------- Tests for sleep_retry.go -----------
+
+func TestExtractManualSleepBackoff(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/sleep_retry.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	sleeps := allByType(configs, "manual-sleep-backoff")
+	if len(sleeps) != 1 {
+		t.Fatalf("want 1 manual-sleep-backoff (sleep without network call is not a retry), got %d: %+v", len(sleeps), sleeps)
+	}
+	if sleeps[0].BackoffMs != 200 {
+		t.Errorf("manual-sleep-backoff: want BackoffMs=200, got %d", sleeps[0].BackoffMs)
+	}
+	if sleeps[0].Line != 16 {
+		t.Errorf("manual-sleep-backoff: want line 16, got %d", sleeps[0].Line)
+	}
+}
+
+// ----------- Tests for layered_retry.go -----------
+
+func TestFindLayeredRetries(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/layered_retry.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	layered := FindLayeredRetries(configs)
+	if len(layered) != 2 {
+		t.Fatalf("want layered retries in FetchProfile and FetchOrders (not FetchOnce), got %d: %+v", len(layered), layered)
+	}
+	profile, orders := layered[0], layered[1]
+	if profile.Func != "FetchProfile" || profile.App.Type != "retry-config" || profile.Transport.Line != 12 {
+		t.Errorf("FetchProfile: unexpected pairing %+v", profile)
+	}
+	if got := profile.Attempts(); got != 20 {
+		t.Errorf("FetchProfile: want (1+4)×(1+3) = 20 attempts, got %d", got)
+	}
+	if orders.Func != "FetchOrders" || orders.App.Type != "manual-sleep-backoff" || orders.Transport.Line != 23 {
+		t.Errorf("FetchOrders: unexpected pairing %+v", orders)
+	}
+	if got := orders.Attempts(); got != 0 {
+		t.Errorf("FetchOrders: retry counts unknown, want 0 attempts, got %d", got)
+	}
+}
+
+func TestExtractLayeredRetryFinding(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/layered_retry.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	found := allByType(configs, "layered-retry")
+	if len(found) != 2 {
+		t.Fatalf("want layered-retry findings in FetchProfile and FetchOrders, got %d: %+v", len(found), found)
+	}
+	profile, orders := found[0], found[1]
+	if profile.Func != "FetchProfile" || profile.Line != 13 || profile.Severity != "warning" {
+		t.Errorf("FetchProfile: unexpected finding %+v", profile)
+	}
+	if profile.MaxRetries != 19 || profile.Confidence != ConfidenceExact {
+		t.Errorf("FetchProfile: want 19 retries at exact confidence, got %d at %v", profile.MaxRetries, profile.Confidence)
+	}
+	if orders.Func != "FetchOrders" || orders.Line != 31 || orders.Severity != "warning" {
+		t.Errorf("FetchOrders: unexpected finding %+v", orders)
+	}
+	if orders.MaxRetries != 0 || orders.Confidence != ConfidenceUnresolved {
+		t.Errorf("FetchOrders: counts unknown, want 0 retries at unresolved confidence, got %d at %v", orders.MaxRetries, orders.Confidence)
+	}
+	for _, c := range configs {
+		if c.Func == "FetchOnce" && c.Type == "layered-retry" {
+			t.Errorf("FetchOnce retries in one layer only: %+v", c)
+		}
+	}
+}
+
+func TestExtractFuncAttribution(t *testing.T) {
+	src := `package p
+var c = &http.Client{Timeout: time.Second}
+func (s *Server) Call() { context.WithTimeout(ctx, time.Second) }`
+	configs, err := ExtractFromSource("test.go", []byte(src))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if c := findByType(configs, "http-client-timeout"); c == nil || c.Func != "" {
+		t.Errorf("package-level client: want no Func, got %+v", c)
+	}
+	if c := findByType(configs, "context-timeout"); c == nil || c.Func != "Server.Call" {
+		t.Errorf("method: want Func Server.Call, got %+v", c)
+	}
+}
+
+// ----------- Tests for context_calls.go -----------
+
+func TestExtractInheritedTimeout(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/context_calls.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	inherited := allByType(configs, "inherited-timeout")
+	if len(inherited) != 1 {
+		t.Fatalf("want 1 inherited-timeout (only FetchInherited passes ctx through), got %d: %+v", len(inherited), inherited)
+	}
+	if inherited[0].Line != 10 {
+		t.Errorf("inherited-timeout: want line 10, got %d", inherited[0].Line)
+	}
+	fresh := allByType(configs, "context-timeout")
+	if len(fresh) != 1 || fresh[0].Line != 19 {
+		t.Errorf("want FetchWithOwnDeadline reported as context-timeout at line 19, got %+v", fresh)
+	}
+}
+
+// ----------- Tests for grpc_calls.go -----------
+
+func TestExtractGRPCNoDeadline(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/grpc_calls.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	unbounded := allByType(configs, "grpc-no-deadline")
+	wantFuncs := []string{"GetUserNoDeadline", "ListUsersTODO", "InvokeNoDeadline"}
+	wantLines := []int{13, 19, 24}
+	if len(unbounded) != len(wantFuncs) {
+		t.Fatalf("want %d grpc-no-deadline (deadline contexts excluded), got %d: %+v", len(wantFuncs), len(unbounded), unbounded)
+	}
+	for i, c := range unbounded {
+		if c.Func != wantFuncs[i] || c.Line != wantLines[i] {
+			t.Errorf("finding %d: want %s at line %d, got %s at line %d", i, wantFuncs[i], wantLines[i], c.Func, c.Line)
+		}
+		if c.Severity != "error" {
+			t.Errorf("%s: want severity error, got %q", c.Func, c.Severity)
+		}
+	}
+}
+
+func TestExtractGRPCDeadlineContextClean(t *testing.T) {
+	src := `package p
+func f(conn *grpc.ClientConn) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c := pb.NewOrdersClient(conn)
+	c.Get(ctx, req)
+}`
+	configs, err := ExtractFromSource("test.go", []byte(src))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if c := findByType(configs, "grpc-no-deadline"); c != nil {
+		t.Errorf("call under a WithTimeout context should not be flagged: %+v", c)
+	}
+}
+
+// ----------- Tests for timeout_mismatch.go -----------
+
+func TestExtractTimeoutMismatch(t *testing.T) {
+	configs, err := ExtractFromFile("testdata/timeout_mismatch.go")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	mismatches := allByType(configs, "timeout-mismatch")
+	want := []ExtractedConfig{
+		{Func: "FetchMismatched", Line: 18, TimeoutMs: 3000, ClientTimeoutMs: 10000},
+		{Func: "FetchLocalMismatch", Line: 40, TimeoutMs: 5000, ClientTimeoutMs: 500},
+	}
+	if len(mismatches) != len(want) {
+		t.Fatalf("want %d timeout-mismatch (FetchMatched agrees), got %d: %+v", len(want), len(mismatches), mismatches)
+	}
+	for i, c := range mismatches {
+		w := want[i]
+		if c.Func != w.Func || c.Line != w.Line || c.TimeoutMs != w.TimeoutMs || c.ClientTimeoutMs != w.ClientTimeoutMs {
+			t.Errorf("finding %d: want %s line %d context %dms client %dms, got %s line %d context %dms client %dms",
+				i, w.Func, w.Line, w.TimeoutMs, w.ClientTimeoutMs, c.Func, c.Line, c.TimeoutMs, c.ClientTimeoutMs)
+		}
+		if c.Severity != "warning" {
+			t.Errorf("%s: want severity warning, got %q", c.Func, c.Severity)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package sample
+
+import (
+	"context"
+	"time"
+
+	pb "example.com/users/proto"
+	"google.golang.org/grpc"
+)
+
+func GetUserNoDeadline(conn *grpc.ClientConn, id string) (*pb.User, error) {
+	client := pb.NewUserServiceClient(conn)
+	return client.GetUser(context.Background(), &pb.GetUserRequest{Id: id})
+}
+
+func ListUsersTODO(conn *grpc.ClientConn) error {
+	ctx := context.TODO()
+	client := pb.NewUserServiceClient(conn)
+	_, err := client.ListUsers(ctx, &pb.ListUsersRequest{})
+	return err
+}
+
+func InvokeNoDeadline(conn *grpc.ClientConn, out *pb.User) error {
+	return conn.Invoke(context.Background(), "/users.UserService/GetUser", &pb.GetUserRequest{}, out)
+}
+
+func GetUserWithDeadline(conn *grpc.ClientConn, id string) (*pb.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client := pb.NewUserServiceClient(conn)
+	return client.GetUser(ctx, &pb.GetUserRequest{Id: id})
+}
+
+func RebindWithDeadline(conn *grpc.ClientConn) error {
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	client := pb.NewUserServiceClient(conn)
+	_, err := client.ListUsers(ctx, &pb.ListUsersRequest{})
+	return err
+}
@@ -0,0 +1,44 @@
+package sample
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+func FetchProfile(url string) error {
+	client := retryablehttp.NewClient()
+	return retry.Do(
+		func() error {
+			_, err := client.Get(url)
+			return err
+		},
+		retry.Attempts(3),
+	)
+}
+
+func FetchOrders(url string) (*http.Response, error) {
+	client := &http.Client{Transport: &RetryTransport{Base: http.DefaultTransport}}
+	var resp *http.Response
+	var err error
+	for i := 0; i < 3; i++ {
+		resp, err = client.Get(url)
+		if err == nil {
+			return resp, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil, err
+}
+
+func FetchOnce(url string) error {
+	return retry.Do(
+		func() error {
+			_, err := http.Get(url)
+			return err
+		},
+		retry.Attempts(3),
+	)
+}
@@ -0,0 +1,25 @@
+package sample
+
+import (
+	"net/http"
+	"time"
+)
+
+func FetchWithManualRetry(url string) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	for i := 0; i < 3; i++ {
+		resp, err = http.Get(url)
+		if err == nil {
+			return resp, nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil, err
+}
+
+func PollLocally() {
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Second)
+	}
+}
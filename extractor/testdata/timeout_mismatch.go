@@ -0,0 +1,42 @@
+package sample
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var slowClient = &http.Client{Timeout: 10 * time.Second}
+
+func FetchMismatched(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	_, err = slowClient.Do(req)
+	return err
+}
+
+func FetchMatched(ctx context.Context, url string) error {
+	client := &http.Client{Timeout: 2 * time.Second}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req.WithContext(ctx))
+	return err
+}
+
+func FetchLocalMismatch(url string) error {
+	client := &http.Client{Timeout: 500 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req = req.WithContext(ctx)
+	_, err := client.Do(req)
+	return err
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// findingFilter is a parsed -filter expression: clauses joined by OR, each
+// a list of conditions joined by AND.
+type findingFilter [][]filterCond
+
+// filterCond compares one finding field with a value.
+type filterCond struct {
+	Field, Op, Value string
+}
+
+// filterFields extracts the fields a filter can test. A path reads as its
+// services joined by "->".
+var filterFields = map[string]func(Finding) string{
+	"rule":     func(f Finding) string { return f.Rule },
+	"severity": func(f Finding) string { return f.Severity },
+	"message":  func(f Finding) string { return f.Message },
+	"path":     func(f Finding) string { return strings.Join(f.Path, "->") },
+}
+
+// parseFilter parses expressions such as
+//
+//	severity=error AND rule!=backoff-no-jitter AND path~payments
+//
+// Conditions are field, operator and value, where the operator is = or !=
+// (equality) or ~ or !~ (substring match). AND binds tighter than OR, and
+// values containing spaces or operators are written in double quotes.
+func parseFilter(expr string) (findingFilter, error) {
+	toks, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("filter: empty expression")
+	}
+	var f findingFilter
+	var clause []filterCond
+	for i := 0; ; {
+		if i+3 > len(toks) {
+			return nil, fmt.Errorf("filter: incomplete condition at %q", strings.Join(toks[i:], " "))
+		}
+		field, op, value := toks[i], toks[i+1], toks[i+2]
+		if _, ok := filterFields[field]; !ok {
+			return nil, fmt.Errorf("filter: unknown field %q (want rule, severity, message or path)", field)
+		}
+		if !isFilterOp(op) {
+			return nil, fmt.Errorf("filter: expected an operator (=, !=, ~, !~) after %s, got %q", field, op)
+		}
+		clause = append(clause, filterCond{field, op, strings.Trim(value, `"`)})
+		i += 3
+		if i == len(toks) {
+			break
+		}
+		switch strings.ToUpper(toks[i]) {
+		case "AND":
+		case "OR":
+			f, clause = append(f, clause), nil
+		default:
+			return nil, fmt.Errorf("filter: expected AND or OR, got %q", toks[i])
+		}
+		i++
+		if i == len(toks) {
+			return nil, fmt.Errorf("filter: expression ends with %s", toks[i-1])
+		}
+	}
+	return append(f, clause), nil
+}
+
+func isFilterOp(s string) bool {
+	return s == "=" || s == "!=" || s == "~" || s == "!~"
+}
+
+// lexFilter splits expr into words, operators and quoted values (kept with
+// their quotes so they are never taken for operators or keywords).
+func lexFilter(expr string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"':
+			end := strings.IndexByte(expr[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("filter: unterminated quote")
+			}
+			toks = append(toks, expr[i:i+end+2])
+			i += end + 2
+		case c == '=' || c == '~':
+			toks = append(toks, string(c))
+			i++
+		case c == '!':
+			if i+1 == len(expr) || (expr[i+1] != '=' && expr[i+1] != '~') {
+				return nil, fmt.Errorf("filter: expected != or !~")
+			}
+			toks = append(toks, expr[i:i+2])
+			i += 2
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t\"=~!", rune(expr[j])) {
+				j++
+			}
+			toks = append(toks, expr[i:j])
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// matches reports whether f satisfies the filter.
+func (ff findingFilter) matches(f Finding) bool {
+	for _, clause := range ff {
+		ok := true
+		for _, c := range clause {
+			if !c.matches(f) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c filterCond) matches(f Finding) bool {
+	v := filterFields[c.Field](f)
+	switch c.Op {
+	case "=":
+		return v == c.Value
+	case "!=":
+		return v != c.Value
+	case "~":
+		return strings.Contains(v, c.Value)
+	}
+	return !strings.Contains(v, c.Value) // "!~"
+}
+
+// apply keeps the findings matching the filter.
+func (ff findingFilter) apply(findings []Finding) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if ff.matches(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
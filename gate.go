@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// severityRank orders severities for threshold comparisons.
+var severityRank = map[string]int{"info": 1, "warning": 2, "error": 3}
+
+// failPolicy decides whether a set of findings fails the run. A run fails
+// when any finding is at or above MinSeverity, or when any finding comes
+// from one of Rules regardless of its severity. ExitCodes, when set,
+// replaces MinSeverity with an exit code per severity.
+type failPolicy struct {
+	MinSeverity string          // "info", "warning", "error" or "never"
+	Rules       map[string]bool // rule IDs that always fail
+	ExitCodes   map[string]int  // severity -> exit code; unmapped severities exit 0
+}
+
+// exitCode is the highest exit code any finding maps to: its ExitCodes entry
+// (or 1 when at or above MinSeverity), and at least 1 for findings from
+// Rules.
+func (p failPolicy) exitCode(findings []Finding) int {
+	threshold, gated := severityRank[p.MinSeverity]
+	code := 0
+	for _, f := range findings {
+		c := 0
+		switch {
+		case p.ExitCodes != nil:
+			c = p.ExitCodes[f.Severity]
+		case gated && severityRank[f.Severity] >= threshold:
+			c = 1
+		}
+		if p.Rules[f.Rule] {
+			c = max(c, 1)
+		}
+		code = max(code, c)
+	}
+	return code
+}
+
+// validExitCodes rejects keys other than the severities findings carry, and
+// exit codes outside 0-125 or the input-error code 2.
+func validExitCodes(codes map[string]int) error {
+	sevs := make([]string, 0, len(codes))
+	for sev := range codes {
+		sevs = append(sevs, sev)
+	}
+	sort.Strings(sevs)
+	for _, sev := range sevs {
+		if _, ok := severityRank[sev]; !ok {
+			return fmt.Errorf("exit_codes: unknown severity %q (want info, warning or error)", sev)
+		}
+		if c := codes[sev]; c < 0 || c > 125 || c == 2 {
+			return fmt.Errorf("exit_codes: %q maps to %d (want 0-125, and 2 is reserved for input errors)", sev, c)
+		}
+	}
+	return nil
+}
+
+func validFailOn(s string) error {
+	if _, ok := severityRank[s]; ok || s == "never" {
+		return nil
+	}
+	return fmt.Errorf("invalid -fail-on %q (want info, warning, error or never)", s)
+}
+
+// ruleList collects repeated or comma-separated rule IDs.
+type ruleList map[string]bool
+
+func (r ruleList) String() string {
+	return strings.Join(r.sortedKeys(), ",")
+}
+
+func (r ruleList) Set(v string) error {
+	for _, id := range strings.Split(v, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			r[id] = true
+		}
+	}
+	return nil
+}
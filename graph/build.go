@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/parser"
+)
+
+// BuildCallGraph converts a parsed topology into a CallGraph: a node per
+// declared service and an edge per call, visiting services in name order.
+// Calls to an alias go to the service declaring it. The values the graph
+// carries are validated with the same messages the analyzer uses.
+func BuildCallGraph(topo *parser.RawTopology) (*CallGraph, error) {
+	aliases, err := topo.Aliases()
+	if err != nil {
+		return nil, err
+	}
+	g := NewCallGraph()
+	for _, svc := range topo.ServiceNames() {
+		sc := topo.Services[svc]
+		if sc.Replicas < 0 {
+			return nil, fmt.Errorf("%s replicas must be non-negative", svc)
+		}
+		g.AddNode(Node{Name: svc, Namespace: sc.Namespace, Tier: sc.Tier, Replicas: sc.Replicas})
+	}
+	for _, svc := range topo.ServiceNames() {
+		for _, c := range topo.Services[svc].Calls {
+			timeout, err := parseCallDuration(svc, c.Target, "timeout", c.Timeout)
+			if err != nil {
+				return nil, err
+			}
+			backoff, err := parseCallDuration(svc, c.Target, "backoff_base", c.BackoffBase)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case c.Retries < 0:
+				return nil, fmt.Errorf("%s->%s retries must be non-negative", svc, c.Target)
+			case c.MaxConcurrency < 0:
+				return nil, fmt.Errorf("%s->%s max_concurrency must be non-negative", svc, c.Target)
+			case c.CallProbability < 0 || c.CallProbability > 1:
+				return nil, fmt.Errorf("%s->%s call_probability must be between 0 and 1", svc, c.Target)
+			case c.FailureProbability < 0 || c.FailureProbability > 1:
+				return nil, fmt.Errorf("%s->%s failure_probability must be between 0 and 1", svc, c.Target)
+			}
+			target := parser.ResolveAlias(aliases, c.Target)
+			if _, ok := g.nodes[target]; !ok {
+				g.AddNode(Node{Name: target})
+			}
+			g.AddEdge(Edge{
+				From:               svc,
+				To:                 target,
+				Timeout:            timeout,
+				MaxRetries:         c.Retries,
+				HasCircuitBreaker:  c.CircuitBreaker,
+				Idempotent:         parser.Idempotent(c.Method, c.Idempotent),
+				MaxConcurrency:     c.MaxConcurrency,
+				CallProbability:    c.CallProbability,
+				FailureProbability: c.FailureProbability,
+				Backoff:            BackoffConfig{InitialInterval: backoff, Multiplier: 2, HasJitter: c.BackoffJitter},
+			})
+		}
+	}
+	return g, nil
+}
+
+// parseCallDuration parses the duration field of the call from svc to
+// target; an empty value is 0.
+func parseCallDuration(svc, target, field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s->%s invalid %s %q: %v", svc, target, field, value, err)
+	}
+	return d, nil
+}
+
+// ReachableFrom returns the part of g reachable from root: every service
+// root reaches, each with its calls in their original order.
+func (g *CallGraph) ReachableFrom(root string) *CallGraph {
+	seen := make(map[string]bool)
+	g.reach(root, seen)
+	sub := NewCallGraph()
+	for name := range seen {
+		n, ok := g.nodes[name]
+		if !ok {
+			n = Node{Name: name}
+		}
+		sub.AddNode(n)
+		for _, e := range g.adj[name] {
+			sub.AddEdge(e)
+		}
+	}
+	return sub
+}
@@ -1,6 +1,9 @@
 package graph
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // BackoffConfig holds backoff parameters for retry policies.
 type BackoffConfig struct {
@@ -14,6 +17,8 @@ type BackoffConfig struct {
 type Node struct {
 	Name      string
 	Namespace string
+	Tier      string // architectural tier, e.g. "edge", "core", "data"
+	Replicas  int    // declared instance count; 0 if unknown
 }
 
 // Edge represents a directed call between two services.
@@ -25,6 +30,13 @@ type Edge struct {
 	Backoff           BackoffConfig
 	HasCircuitBreaker bool
 	Idempotent        bool
+	CallCount         int // observed calls, for graphs built from tracing data
+	MaxConcurrency    int // in-flight request limit (bulkhead); 0 means unbounded
+	// CallProbability is the chance (0-1) that a request to From makes
+	// this call at all; 0 means it always does. FailureProbability is the
+	// chance that one attempt fails and is retried; 0 means it never fails.
+	CallProbability    float64
+	FailureProbability float64
 }
 
 // CallGraph is a directed graph of service-to-service calls.
@@ -46,6 +58,12 @@ func (g *CallGraph) AddNode(n Node) {
 	g.nodes[n.Name] = n
 }
 
+// Node returns the named service and whether the graph has it.
+func (g *CallGraph) Node(name string) (Node, bool) {
+	n, ok := g.nodes[name]
+	return n, ok
+}
+
 // AddEdge adds a directed call edge to the graph.
 func (g *CallGraph) AddEdge(e Edge) {
 	g.adj[e.From] = append(g.adj[e.From], e)
@@ -57,38 +75,54 @@ func (g *CallGraph) AddEdge(e Edge) {
 // (its To field will match an earlier From in the path or the root itself).
 func (g *CallGraph) AllPathsFrom(root string) [][]Edge {
 	var result [][]Edge
-	visited := make(map[string]bool)
-	g.dfs(root, visited, nil, &result)
+	g.dfs(root, make(map[string]bool), nil, func(path []Edge) bool {
+		cp := make([]Edge, len(path))
+		copy(cp, path)
+		result = append(result, cp)
+		return true
+	})
 	return result
 }
 
-func (g *CallGraph) dfs(node string, visited map[string]bool, path []Edge, result *[][]Edge) {
+// WalkPaths calls fn for every path from each of the graph's Roots, in the
+// same order and with the same cycle handling as AllPathsFrom, without
+// materializing them all. Enumeration stops as soon as fn returns false.
+// The slice passed to fn is reused; copy it to keep it past the call.
+func (g *CallGraph) WalkPaths(fn func(path []Edge) bool) {
+	for _, root := range g.Roots() {
+		if !g.dfs(root, make(map[string]bool), nil, fn) {
+			return
+		}
+	}
+}
+
+// dfs reports each path below node to fn and returns false once fn has
+// asked to stop.
+func (g *CallGraph) dfs(node string, visited map[string]bool, path []Edge, fn func([]Edge) bool) bool {
 	visited[node] = true
 	defer func() { visited[node] = false }()
 
 	outEdges := g.adj[node]
 	if len(outEdges) == 0 {
-		// Leaf node: record current path if non-empty.
-		if len(path) > 0 {
-			cp := make([]Edge, len(path))
-			copy(cp, path)
-			*result = append(*result, cp)
-		}
-		return
+		// Leaf node: report current path if non-empty.
+		return len(path) == 0 || fn(path)
 	}
 
 	for _, e := range outEdges {
-		newPath := make([]Edge, len(path)+1)
-		copy(newPath, path)
-		newPath[len(path)] = e
+		newPath := append(path, e)
 
 		if visited[e.To] {
 			// Cycle detected: include the back-edge but stop recursion.
-			*result = append(*result, newPath)
+			if !fn(newPath) {
+				return false
+			}
 			continue
 		}
-		g.dfs(e.To, visited, newPath, result)
+		if !g.dfs(e.To, visited, newPath, fn) {
+			return false
+		}
 	}
+	return true
 }
 
 // RetryAmplificationFactor returns the multiplicative retry factor along a
@@ -115,3 +149,217 @@ func WorstCaseLatency(path []Edge) time.Duration {
 	}
 	return total
 }
+
+// RecommendEntryTimeout returns the smallest entry timeout that lets every
+// path from the graph's Roots run to completion under the current config:
+// the WorstCaseLatency of the critical (slowest) path. An entry timeout
+// below it cuts off requests that are still within their downstream
+// budgets. Returns 0 for an empty graph.
+func RecommendEntryTimeout(g *CallGraph) time.Duration {
+	var worst time.Duration
+	g.WalkPaths(func(path []Edge) bool {
+		if d := WorstCaseLatency(path); d > worst {
+			worst = d
+		}
+		return true
+	})
+	return worst
+}
+
+// Roots returns the services paths are walked from, in sorted order: the
+// callers that are never called, plus every caller none of those reaches.
+// The latter are the services on or below a cycle that no entry point leads
+// into, so such a cycle is analyzed whether or not the rest of the graph has
+// an entry point. In a fully cyclic graph every caller is returned.
+func (g *CallGraph) Roots() []string {
+	called := make(map[string]bool)
+	for _, edges := range g.adj {
+		for _, e := range edges {
+			called[e.To] = true
+		}
+	}
+	var roots, callers []string
+	for from := range g.adj {
+		callers = append(callers, from)
+		if !called[from] {
+			roots = append(roots, from)
+		}
+	}
+	reached := make(map[string]bool)
+	for _, root := range roots {
+		g.reach(root, reached)
+	}
+	for _, from := range callers {
+		if !reached[from] {
+			roots = append(roots, from)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// reach marks node and every service below it in seen.
+func (g *CallGraph) reach(node string, seen map[string]bool) {
+	if seen[node] {
+		return
+	}
+	seen[node] = true
+	for _, e := range g.adj[node] {
+		g.reach(e.To, seen)
+	}
+}
+
+// MaxBackendRequests returns, for each called service, the worst-case number
+// of requests it receives from a single root request: the sum over every
+// path reaching it of the path's retry amplification. Fan-out and retries
+// both add up. When there are several roots, the largest count is kept.
+func (g *CallGraph) MaxBackendRequests() map[string]int {
+	result := make(map[string]int)
+	for _, root := range g.Roots() {
+		counts := make(map[string]int)
+		g.countRequests(root, 1, map[string]bool{root: true}, counts)
+		for svc, n := range counts {
+			if n > result[svc] {
+				result[svc] = n
+			}
+		}
+	}
+	return result
+}
+
+// TotalWorstCaseRequests returns the worst-case number of outbound requests
+// a single request to root generates across everything it reaches: the sum
+// of MaxBackendRequests-style counts over every service below root.
+func (g *CallGraph) TotalWorstCaseRequests(root string) int {
+	counts := make(map[string]int)
+	g.countRequests(root, 1, map[string]bool{root: true}, counts)
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+func (g *CallGraph) countRequests(node string, factor int, onPath map[string]bool, counts map[string]int) {
+	for _, e := range g.adj[node] {
+		if onPath[e.To] {
+			continue
+		}
+		n := factor * (1 + e.MaxRetries)
+		counts[e.To] += n
+		onPath[e.To] = true
+		g.countRequests(e.To, n, onPath, counts)
+		delete(onPath, e.To)
+	}
+}
+
+// ExpectedBackendLoad returns, for each service below root, the expected
+// number of requests it receives per request to root, where
+// MaxBackendRequests gives the worst case. A call is made with its
+// CallProbability, and each attempt after the first is made only if the one
+// before failed, so a call with failure probability p and r retries makes
+// 1 + p + ... + p^r attempts on average. Every attempt that reaches a
+// service triggers its own calls in turn. Paths stop before revisiting a
+// service, as in MaxBackendRequests.
+func (g *CallGraph) ExpectedBackendLoad(root string) map[string]float64 {
+	load := make(map[string]float64)
+	g.expectRequests(root, 1, map[string]bool{root: true}, load)
+	return load
+}
+
+func (g *CallGraph) expectRequests(node string, factor float64, onPath map[string]bool, load map[string]float64) {
+	for _, e := range g.adj[node] {
+		if onPath[e.To] {
+			continue
+		}
+		n := factor * e.expectedAttempts()
+		load[e.To] += n
+		onPath[e.To] = true
+		g.expectRequests(e.To, n, onPath, load)
+		delete(onPath, e.To)
+	}
+}
+
+// expectedAttempts is the average number of requests one request to From
+// sends along e: CallProbability × (1 + p + ... + p^MaxRetries).
+func (e Edge) expectedAttempts() float64 {
+	attempts, p := 0.0, 1.0
+	for i := 0; i <= e.MaxRetries; i++ {
+		attempts += p
+		p *= e.FailureProbability
+	}
+	if e.CallProbability > 0 {
+		attempts *= e.CallProbability
+	}
+	return attempts
+}
+
+// ArticulationPoints returns, in sorted order, the services whose removal
+// would split the graph into more connected pieces when call direction is
+// ignored. Each is a single point of failure between the callers on one
+// side and the services on the other. It uses the DFS low-link algorithm.
+func (g *CallGraph) ArticulationPoints() []string {
+	neighbours := make(map[string][]string)
+	for name := range g.nodes {
+		neighbours[name] = nil
+	}
+	for from, edges := range g.adj {
+		if _, ok := neighbours[from]; !ok {
+			neighbours[from] = nil
+		}
+		for _, e := range edges {
+			if e.To == from {
+				continue
+			}
+			neighbours[from] = append(neighbours[from], e.To)
+			neighbours[e.To] = append(neighbours[e.To], from)
+		}
+	}
+	names := make([]string, 0, len(neighbours))
+	for name := range neighbours {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	disc := make(map[string]int)
+	low := make(map[string]int)
+	isCut := make(map[string]bool)
+	timer := 0
+	var visit func(node, parent string)
+	visit = func(node, parent string) {
+		timer++
+		disc[node], low[node] = timer, timer
+		children := 0
+		for _, next := range neighbours[node] {
+			if next == parent {
+				continue
+			}
+			if disc[next] != 0 {
+				low[node] = min(low[node], disc[next])
+				continue
+			}
+			children++
+			visit(next, node)
+			low[node] = min(low[node], low[next])
+			if parent != "" && low[next] >= disc[node] {
+				isCut[node] = true
+			}
+		}
+		if parent == "" && children > 1 {
+			isCut[node] = true
+		}
+	}
+	for _, name := range names {
+		if disc[name] == 0 {
+			visit(name, "")
+		}
+	}
+
+	var points []string
+	for _, name := range names {
+		if isCut[name] {
+			points = append(points, name)
+		}
+	}
+	return points
+}
@@ -1,8 +1,14 @@
 package graph
 
 import (
+	"math"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/cascadeguard/cascadeguard/parser"
 )
 
 // --- Test 1: Linear chain A→B→C ---
@@ -238,3 +244,368 @@ func TestMixedCycleAndLeaf(t *testing.T) {
 		t.Error("missing leaf path A→C")
 	}
 }
+
+// --- Worst-case requests per backend ---
+
+func TestMaxBackendRequests(t *testing.T) {
+	// gateway fans out to A and B (3 attempts each); both call db with
+	// 4 attempts, and A also calls db a second time via cache.
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "gateway", To: "A", MaxRetries: 2})
+	g.AddEdge(Edge{From: "gateway", To: "B", MaxRetries: 2})
+	g.AddEdge(Edge{From: "A", To: "db", MaxRetries: 3})
+	g.AddEdge(Edge{From: "B", To: "db", MaxRetries: 3})
+	g.AddEdge(Edge{From: "A", To: "cache", MaxRetries: 1})
+	g.AddEdge(Edge{From: "cache", To: "db", MaxRetries: 0})
+
+	got := g.MaxBackendRequests()
+	// db: 3*4 (via A) + 3*4 (via B) + 3*2*1 (via cache) = 30
+	want := map[string]int{"A": 3, "B": 3, "cache": 6, "db": 30}
+	for svc, n := range want {
+		if got[svc] != n {
+			t.Errorf("%s: want %d requests, got %d", svc, n, got[svc])
+		}
+	}
+	if _, ok := got["gateway"]; ok {
+		t.Errorf("root should not be counted as a backend: %v", got)
+	}
+}
+
+func TestExpectedBackendLoad(t *testing.T) {
+	// gateway always calls A, which fails half the time and retries twice;
+	// A calls db on 40% of requests, with a 10% failure rate and 3 retries.
+	// gateway also calls audit, which never fails, on 20% of requests.
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "gateway", To: "A", MaxRetries: 2, FailureProbability: 0.5})
+	g.AddEdge(Edge{From: "A", To: "db", MaxRetries: 3, CallProbability: 0.4, FailureProbability: 0.1})
+	g.AddEdge(Edge{From: "gateway", To: "audit", MaxRetries: 2, CallProbability: 0.2})
+
+	got := g.ExpectedBackendLoad("gateway")
+	// A: 1 + 0.5 + 0.25; db: 1.75 × 0.4 × (1 + 0.1 + 0.01 + 0.001); audit: 0.2 × 1
+	want := map[string]float64{"A": 1.75, "db": 1.75 * 0.4 * 1.111, "audit": 0.2}
+	worst := g.MaxBackendRequests()
+	for svc, n := range want {
+		if math.Abs(got[svc]-n) > 1e-9 {
+			t.Errorf("%s: want %.4f expected requests, got %.4f", svc, n, got[svc])
+		}
+		if got[svc] >= float64(worst[svc]) {
+			t.Errorf("%s: expected load %.4f should be below the worst case %d", svc, got[svc], worst[svc])
+		}
+	}
+	if worst["db"] != 12 {
+		t.Errorf("db: want worst case 3×4 = 12, got %d", worst["db"])
+	}
+	if _, ok := got["gateway"]; ok {
+		t.Errorf("root should not be counted as a backend: %v", got)
+	}
+}
+
+func TestExpectedBackendLoadUnannotatedCallsOnce(t *testing.T) {
+	// Without probabilities every call is made once and never retried.
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "gateway", To: "A", MaxRetries: 4})
+	g.AddEdge(Edge{From: "A", To: "db", MaxRetries: 4})
+	got := g.ExpectedBackendLoad("gateway")
+	if got["A"] != 1 || got["db"] != 1 {
+		t.Errorf("want one request each, got %v", got)
+	}
+}
+
+func TestTotalWorstCaseRequests(t *testing.T) {
+	// gateway fans out to A and B (3 attempts each); each fans out again to
+	// two backends with 4 attempts.
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "gateway", To: "A", MaxRetries: 2})
+	g.AddEdge(Edge{From: "gateway", To: "B", MaxRetries: 2})
+	for _, mid := range []string{"A", "B"} {
+		g.AddEdge(Edge{From: mid, To: "db", MaxRetries: 3})
+		g.AddEdge(Edge{From: mid, To: "cache", MaxRetries: 3})
+	}
+	// A, B: 3 each; db, cache: 12 (via A) + 12 (via B) each = 6 + 48
+	if got := g.TotalWorstCaseRequests("gateway"); got != 54 {
+		t.Errorf("gateway: want 54 requests, got %d", got)
+	}
+	if got := g.TotalWorstCaseRequests("A"); got != 8 {
+		t.Errorf("A: want 8 requests, got %d", got)
+	}
+	if got := g.TotalWorstCaseRequests("db"); got != 0 {
+		t.Errorf("leaf: want 0 requests, got %d", got)
+	}
+}
+
+func TestRecommendEntryTimeout(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  time.Duration
+	}{
+		{name: "empty", want: 0},
+		{
+			name: "chain",
+			edges: []Edge{
+				{From: "gateway", To: "api", Timeout: 2 * time.Second, MaxRetries: 1},
+				{From: "api", To: "db", Timeout: time.Second, MaxRetries: 2},
+			},
+			want: 7 * time.Second, // 2s×2 + 1s×3
+		},
+		{
+			name: "branches — critical path wins",
+			edges: []Edge{
+				{From: "gateway", To: "api", Timeout: time.Second},
+				{From: "api", To: "db", Timeout: time.Second},
+				{From: "gateway", To: "search", Timeout: 3 * time.Second, MaxRetries: 3},
+				{From: "api", To: "cache", Timeout: 100 * time.Millisecond},
+			},
+			want: 12 * time.Second, // gateway->search 3s×4
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewCallGraph()
+			for _, e := range tt.edges {
+				g.AddEdge(e)
+			}
+			if got := RecommendEntryTimeout(g); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRootsFullyCyclic(t *testing.T) {
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "B", To: "A"})
+	g.AddEdge(Edge{From: "A", To: "B"})
+	if roots := g.Roots(); len(roots) != 2 || roots[0] != "A" || roots[1] != "B" {
+		t.Errorf("want every caller as a root, got %v", roots)
+	}
+}
+
+func TestRootsIncludeUnreachedCycle(t *testing.T) {
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "gateway", To: "A"})
+	g.AddEdge(Edge{From: "C", To: "D"})
+	g.AddEdge(Edge{From: "D", To: "C"})
+	g.AddEdge(Edge{From: "A", To: "B"})
+	if roots := g.Roots(); strings.Join(roots, ",") != "C,D,gateway" {
+		t.Errorf("want the entry point plus the unreached cycle, got %v", roots)
+	}
+}
+
+// --- Jaeger dependencies ingestion ---
+
+func TestBuildGraphFromJaegerDependencies(t *testing.T) {
+	f, err := os.Open("testdata/jaeger_dependencies.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := BuildGraphFromJaegerDependencies(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{
+		"frontend->checkout": 1200,
+		"frontend->catalog":  5400,
+		"checkout->payments": 1150,
+		"checkout->catalog":  300,
+	}
+	got := map[string]int{}
+	for from, edges := range g.adj {
+		for _, e := range edges {
+			if e.Timeout != 0 || e.MaxRetries != 0 {
+				t.Errorf("%s->%s: observed edge should carry no timeout/retries, got %+v", from, e.To, e)
+			}
+			got[from+"->"+e.To] = e.CallCount
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("edges: want %v, got %v", want, got)
+	}
+	if len(g.nodes) != 4 {
+		t.Errorf("want 4 nodes, got %d", len(g.nodes))
+	}
+	if roots := g.Roots(); len(roots) != 1 || roots[0] != "frontend" {
+		t.Errorf("want frontend as the only root, got %v", roots)
+	}
+}
+
+func TestBuildGraphFromJaegerDependenciesBareArray(t *testing.T) {
+	g, err := BuildGraphFromJaegerDependencies(strings.NewReader(`[{"parent":"a","child":"b","callCount":7}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e := g.adj["a"]; len(e) != 1 || e[0].To != "b" || e[0].CallCount != 7 {
+		t.Errorf("want a->b with 7 calls, got %+v", e)
+	}
+}
+
+func TestBuildGraphFromJaegerDependenciesInvalid(t *testing.T) {
+	for _, in := range []string{`not json`, `[{"parent":"a","callCount":1}]`} {
+		if _, err := BuildGraphFromJaegerDependencies(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+// --- Articulation points ---
+
+func TestArticulationPointsChain(t *testing.T) {
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "A", To: "B"})
+	g.AddEdge(Edge{From: "B", To: "C"})
+	if got := g.ArticulationPoints(); !reflect.DeepEqual(got, []string{"B"}) {
+		t.Errorf("want [B], got %v", got)
+	}
+}
+
+func TestArticulationPointsDiamond(t *testing.T) {
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "A", To: "B"})
+	g.AddEdge(Edge{From: "A", To: "D"})
+	g.AddEdge(Edge{From: "B", To: "C"})
+	g.AddEdge(Edge{From: "D", To: "C"})
+	if got := g.ArticulationPoints(); len(got) != 0 {
+		t.Errorf("diamond has no articulation points, got %v", got)
+	}
+}
+
+func TestArticulationPointsIgnoreDirectionAndCycles(t *testing.T) {
+	// A ⇄ B → C, with a self-loop on C: B still separates A from C.
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "A", To: "B"})
+	g.AddEdge(Edge{From: "B", To: "A"})
+	g.AddEdge(Edge{From: "B", To: "C"})
+	g.AddEdge(Edge{From: "C", To: "C"})
+	if got := g.ArticulationPoints(); !reflect.DeepEqual(got, []string{"B"}) {
+		t.Errorf("want [B], got %v", got)
+	}
+}
+
+// --- Incremental path walking ---
+
+func TestWalkPathsMatchesAllPathsFrom(t *testing.T) {
+	g := NewCallGraph()
+	g.AddEdge(Edge{From: "A", To: "B", MaxRetries: 1})
+	g.AddEdge(Edge{From: "A", To: "D"})
+	g.AddEdge(Edge{From: "B", To: "C"})
+	g.AddEdge(Edge{From: "D", To: "C"})
+	g.AddEdge(Edge{From: "C", To: "B"}) // back-edge
+
+	var walked [][]Edge
+	g.WalkPaths(func(path []Edge) bool {
+		walked = append(walked, append([]Edge(nil), path...))
+		return true
+	})
+	if want := g.AllPathsFrom("A"); !reflect.DeepEqual(walked, want) {
+		t.Errorf("WalkPaths saw\n%v\nwant\n%v", walked, want)
+	}
+}
+
+func TestWalkPathsStopsEarly(t *testing.T) {
+	g := NewCallGraph()
+	for _, to := range []string{"B", "C", "D", "E"} {
+		g.AddEdge(Edge{From: "A", To: to})
+	}
+	calls := 0
+	g.WalkPaths(func(path []Edge) bool {
+		calls++
+		return calls < 2
+	})
+	if calls != 2 {
+		t.Errorf("want enumeration to halt after 2 paths, got %d callbacks", calls)
+	}
+}
+
+// --- Building from a topology ---
+
+func parseTopology(t *testing.T, yaml string) *parser.RawTopology {
+	t.Helper()
+	topo, err := parser.ParseTopology(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return topo
+}
+
+func TestBuildCallGraph(t *testing.T) {
+	topo := parseTopology(t, `services:
+  gateway:
+    namespace: edge
+    calls:
+      - target: users
+        timeout: 3s
+        retries: 3
+        method: GET
+  user-svc:
+    aliases: [users]
+    calls:
+      - target: db-svc
+        timeout: 5s
+        retries: 2
+        method: POST
+`)
+	cg, err := BuildCallGraph(topo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roots := cg.Roots(); len(roots) != 1 || roots[0] != "gateway" {
+		t.Fatalf("roots: want [gateway], got %v", roots)
+	}
+	paths := cg.AllPathsFrom("gateway")
+	if len(paths) != 1 || len(paths[0]) != 2 {
+		t.Fatalf("want the single path gateway->user-svc->db-svc, got %+v", paths)
+	}
+	first, second := paths[0][0], paths[0][1]
+	if first.From != "gateway" || first.To != "user-svc" || first.Timeout != 3*time.Second || first.MaxRetries != 3 || !first.Idempotent {
+		t.Errorf("gateway->user-svc (via alias): %+v", first)
+	}
+	if second.From != "user-svc" || second.To != "db-svc" || second.Timeout != 5*time.Second || second.MaxRetries != 2 || second.Idempotent {
+		t.Errorf("user-svc->db-svc (POST): %+v", second)
+	}
+	if n, ok := cg.Node("gateway"); !ok || n.Namespace != "edge" {
+		t.Errorf("gateway node: want namespace edge, got %+v", n)
+	}
+	if _, ok := cg.Node("db-svc"); !ok {
+		t.Error("an undeclared callee should still get a node")
+	}
+
+	// Probabilities survive the conversion, so expected-load estimates see
+	// the same calls as the rules.
+	cg, err = BuildCallGraph(parseTopology(t, "services:\n  a:\n    calls:\n      - target: b\n        timeout: 1s\n"+
+		"        retries: 1\n        call_probability: 0.5\n        failure_probability: 0.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cg.ExpectedBackendLoad("a")["b"]; got != 0.75 {
+		t.Errorf("expected load on b: want 0.75, got %v", got)
+	}
+
+	bad := parseTopology(t, "services:\n  a:\n    calls:\n      - target: b\n        timeout: soon\n")
+	if _, err := BuildCallGraph(bad); err == nil || !strings.Contains(err.Error(), `a->b invalid timeout "soon"`) {
+		t.Errorf("an invalid timeout should fail the conversion, as in analysis; got %v", err)
+	}
+}
+
+func TestReachableFrom(t *testing.T) {
+	g := NewCallGraph()
+	g.AddNode(Node{Name: "A", Tier: "edge"})
+	g.AddEdge(Edge{From: "A", To: "B"})
+	g.AddEdge(Edge{From: "B", To: "C"})
+	g.AddEdge(Edge{From: "X", To: "B"})
+
+	sub := g.ReachableFrom("A")
+	if roots := sub.Roots(); !reflect.DeepEqual(roots, []string{"A"}) {
+		t.Errorf("roots: want [A], got %v", roots)
+	}
+	if n, _ := sub.Node("A"); n.Tier != "edge" {
+		t.Errorf("A should keep its tier, got %+v", n)
+	}
+	if _, ok := sub.Node("X"); ok {
+		t.Error("X is not reachable from A")
+	}
+	if got := len(sub.AllPathsFrom("A")); got != 1 {
+		t.Errorf("want the single path A->B->C, got %d paths", got)
+	}
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jaegerDependency is one entry of Jaeger's /api/dependencies response.
+type jaegerDependency struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount int    `json:"callCount"`
+}
+
+// BuildGraphFromJaegerDependencies builds a call graph from the JSON served
+// by Jaeger's /api/dependencies endpoint, either the {"data": [...]} envelope
+// or the bare array. Each dependency becomes an edge weighted by its
+// CallCount. Observed traffic carries no timeout or retry settings, so those
+// fields stay zero: only structural checks such as fan-out and cycles are
+// meaningful on the result, which makes it useful for validating a declared
+// topology against what actually runs.
+func BuildGraphFromJaegerDependencies(r io.Reader) (*CallGraph, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading jaeger dependencies: %w", err)
+	}
+	var deps []jaegerDependency
+	var envelope struct {
+		Data []jaegerDependency `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil {
+		deps = envelope.Data
+	} else if err := json.Unmarshal(raw, &deps); err != nil {
+		return nil, fmt.Errorf("parsing jaeger dependencies: %w", err)
+	}
+
+	g := NewCallGraph()
+	for i, d := range deps {
+		if d.Parent == "" || d.Child == "" {
+			return nil, fmt.Errorf("jaeger dependency %d: parent and child are required", i)
+		}
+		for _, name := range []string{d.Parent, d.Child} {
+			if _, ok := g.nodes[name]; !ok {
+				g.AddNode(Node{Name: name})
+			}
+		}
+		g.AddEdge(Edge{From: d.Parent, To: d.Child, CallCount: d.CallCount})
+	}
+	return g, nil
+}
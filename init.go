@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cascadeguard/cascadeguard/extractor"
+)
+
+// starterCall is one call in a generated starter topology: the configs the
+// extractor found in a single function (or at package level in one file).
+type starterCall struct {
+	Service, File, Func string
+	Configs             []extractor.ExtractedConfig
+}
+
+// timeoutTypes and retryTypes are the extracted config types that carry a
+// call's timeout and retry count.
+var (
+	timeoutTypes = map[string]bool{"http-client-timeout": true, "context-timeout": true, "grpc-timeout": true, "gokit-retry": true}
+	retryTypes   = map[string]bool{"retry-config": true, "gokit-retry": true, "transport-retry": true}
+)
+
+// scanSources runs the extractor over every non-test .go file under dir,
+// skipping testdata and vendor directories, and groups the configs into
+// calls. A file's service is its first directory below dir (or dir itself
+// for files at the top). Files that do not parse are reported on stderr
+// and skipped.
+func scanSources(dir string, stderr io.Writer) ([]starterCall, error) {
+	base := filepath.Base(mustAbs(dir))
+	var calls []starterCall
+	index := make(map[[2]string]int) // (file, func) -> position in calls
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == "testdata" || d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		configs, err := extractor.ExtractFromFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: skipping %v\n", err)
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		svc := base
+		if first, _, nested := strings.Cut(filepath.ToSlash(rel), "/"); nested {
+			svc = first
+		}
+		for _, c := range configs {
+			key := [2]string{rel, c.Func}
+			i, ok := index[key]
+			if !ok {
+				i = len(calls)
+				index[key] = i
+				calls = append(calls, starterCall{Service: svc, File: filepath.ToSlash(rel), Func: c.Func})
+			}
+			calls[i].Configs = append(calls[i].Configs, c)
+		}
+		return nil
+	})
+	return calls, err
+}
+
+func mustAbs(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// runInit writes a starter topology built from a source scan of dir. Values
+// the extractor cannot know (the called service, circuit breakers,
+// idempotency) are left as TODO comments for the user to fill in.
+func runInit(dir string, stdout, stderr io.Writer) int {
+	calls, err := scanSources(dir, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if len(calls) == 0 {
+		fmt.Fprintf(stderr, "error: %s: no timeout or retry configs found in Go sources\n", dir)
+		return 2
+	}
+	if err := writeStarterTopology(calls, stdout); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// writeStarterTopology renders calls as topology YAML, services in name
+// order and calls in scan order.
+func writeStarterTopology(calls []starterCall, w io.Writer) error {
+	bySvc := make(map[string][]starterCall)
+	var svcs []string
+	for _, c := range calls {
+		if _, ok := bySvc[c.Service]; !ok {
+			svcs = append(svcs, c.Service)
+		}
+		bySvc[c.Service] = append(bySvc[c.Service], c)
+	}
+	sort.Strings(svcs)
+
+	var b strings.Builder
+	b.WriteString("# Starter topology generated by cascadeguard -init from a source scan.\n")
+	b.WriteString("# Replace each TODO target with the service called and review the values.\n")
+	b.WriteString("services:\n")
+	for _, svc := range svcs {
+		fmt.Fprintf(&b, "  %s:\n    calls:\n", yamlString(svc))
+		for _, c := range bySvc[svc] {
+			writeStarterCall(&b, c)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeStarterCall(b *strings.Builder, c starterCall) {
+	where := c.File
+	if c.Func != "" {
+		where += " " + c.Func
+	}
+	target := "todo-" + kebab(c.Func)
+	if c.Func == "" {
+		target = "todo-" + kebab(strings.TrimSuffix(filepath.Base(c.File), ".go"))
+	}
+	fmt.Fprintf(b, "      # %s\n", where)
+	fmt.Fprintf(b, "      - target: %s # TODO: name the called service\n", yamlString(target))
+
+	var timeoutMs, backoffMs int64
+	retries := 0
+	confidence := 1.0
+	for _, cfg := range c.Configs {
+		if timeoutTypes[cfg.Type] && cfg.TimeoutMs > timeoutMs {
+			timeoutMs = cfg.TimeoutMs
+		}
+		if retryTypes[cfg.Type] && cfg.MaxRetries > retries {
+			retries = cfg.MaxRetries
+		}
+		if cfg.Type == "manual-sleep-backoff" && cfg.BackoffMs > backoffMs {
+			backoffMs = cfg.BackoffMs
+		}
+		if cfg.Confidence < confidence {
+			confidence = cfg.Confidence
+		}
+	}
+	// The layers' retries multiply, so a layered-retry finding overrides
+	// the per-layer counts.
+	for _, cfg := range c.Configs {
+		if cfg.Type == "layered-retry" && cfg.MaxRetries > 0 {
+			retries = cfg.MaxRetries
+		}
+	}
+
+	if timeoutMs > 0 {
+		fmt.Fprintf(b, "        timeout: %v\n", time.Duration(timeoutMs)*time.Millisecond)
+	} else {
+		b.WriteString("        # timeout: TODO (none found in source)\n")
+	}
+	if retries > 0 {
+		fmt.Fprintf(b, "        retries: %d\n", retries)
+	}
+	if backoffMs > 0 {
+		fmt.Fprintf(b, "        backoff_base: %v\n", time.Duration(backoffMs)*time.Millisecond)
+	}
+	b.WriteString("        # circuit_breaker: TODO true|false\n")
+	b.WriteString("        # idempotent: TODO true|false (defaults from method)\n")
+	if confidence < 1 {
+		fmt.Fprintf(b, "        confidence: %v\n", confidence)
+	}
+	for _, cfg := range c.Configs {
+		note := cfg.Type
+		if cfg.Severity != "" {
+			note = cfg.Severity + ": " + note
+		}
+		if cfg.Type == "timeout-mismatch" {
+			note += fmt.Sprintf(" (context %v, client %v)",
+				time.Duration(cfg.TimeoutMs)*time.Millisecond, time.Duration(cfg.ClientTimeoutMs)*time.Millisecond)
+		}
+		if cfg.Type == "layered-retry" && cfg.MaxRetries > 0 {
+			note += fmt.Sprintf(" (%d attempts across transport and application)", cfg.MaxRetries+1)
+		}
+		fmt.Fprintf(b, "        # found %s at line %d\n", note, cfg.Line)
+	}
+}
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// yamlString returns s as a YAML scalar, quoted unless it is a plain name.
+func yamlString(s string) string {
+	if plainYAML.MatchString(s) {
+		return s
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// kebab turns "Server.FetchProfile" into "server-fetch-profile" and
+// "NewHTTPClient" into "new-http-client".
+func kebab(s string) string {
+	rs := []rune(s)
+	isUpper := func(i int) bool { return i >= 0 && i < len(rs) && unicode.IsUpper(rs[i]) }
+	isLower := func(i int) bool { return i >= 0 && i < len(rs) && (unicode.IsLower(rs[i]) || unicode.IsDigit(rs[i])) }
+	var b strings.Builder
+	dash := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case isUpper(i):
+			if isLower(i-1) || isUpper(i-1) && isLower(i+1) {
+				dash()
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case isLower(i):
+			b.WriteRune(r)
+		default:
+			dash()
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
@@ -1,84 +1,352 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/cascadeguard/cascadeguard/graph"
+	"github.com/cascadeguard/cascadeguard/output"
+	"github.com/cascadeguard/cascadeguard/parser"
+	"github.com/cascadeguard/cascadeguard/progress"
 )
 
-type Config struct {
-	Services map[string]struct {
-		Calls []struct {
-			Target         string `yaml:"target"`
-			Timeout        string `yaml:"timeout"`
-			Retries        int    `yaml:"retries"`
-			CircuitBreaker bool   `yaml:"circuit_breaker"`
-			Method         string `yaml:"method"`
-			BackoffJitter  bool   `yaml:"backoff_jitter"`
-		} `yaml:"calls"`
-	} `yaml:"services"`
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: cascadeguard <topology.yaml>")
-		os.Exit(2)
+// run executes the CLI and returns the process exit code:
+// 0 = clean (or no finding met the fail policy), 1 = findings detected,
+// 2 = input error.
+func run(args []string, stdout, stderr io.Writer) int {
+	start := time.Now()
+	defaults := DefaultOptions()
+	fs := flag.NewFlagSet("cascadeguard", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var outputs outputFlags
+	fs.Var(&outputs, "output", "output `format[:file]` (text, md, sarif, mermaid, github, svg, issues); repeatable, file defaults to stdout")
+	configPath := fs.String("config", "", "load analysis options from a JSON `file` (see -print-config); flags override it")
+	printConfig := fs.Bool("print-config", false, "print the effective analysis options as JSON and exit")
+	failOn := fs.String("fail-on", defaults.FailOn, "minimum `severity` that fails the run: info, warning, error or never")
+	failRules := ruleList{}
+	fs.Var(failRules, "fail-on-rule", "`rule` ID that fails the run regardless of severity; repeatable or comma-separated")
+	enabled := ruleList{}
+	fs.Var(enabled, "enable", "opt-in `rule` ID to run (e.g. orphaned-retry); repeatable or comma-separated")
+	var plugins commandList
+	fs.Var(&plugins, "plugin", "external checker `command` (with arguments) speaking the JSON rule protocol; repeatable")
+	violationsOnly := fs.Bool("violations-only", false, "render only the services and calls that appear in a finding's path")
+	summaryJSON := fs.Bool("summary-json", false, "write a JSON summary (counts, fail decision, elapsed time) to stderr")
+	input := fs.String("input", "topology", "input `format`: topology, resilience4j (Spring Boot YAML) or openapi")
+	compare := fs.String("compare", "", "compare with an earlier run's SARIF `file`: print new and resolved findings and fail only on new ones")
+	filterExpr := fs.String("filter", "", "report only findings matching this `expression`, e.g. 'severity=error AND path~payments' (operators =, !=, ~, !~; AND, OR)")
+	newOnly := fs.Bool("only-new", false, "with -compare, report only findings absent from the baseline")
+	since := fs.String("since", "", "report only findings on calls made by services whose `sources` changed since this git ref")
+	stream := fs.Bool("stream", false, "check calls one at a time without building the graph (per-call rules only; for huge topologies)")
+	batch := fs.String("batch", "", "analyze every .yaml/.yml topology in this `dir` and add a cross-file report (text or github output)")
+	root := fs.String("root", "", "analyze only the calls reachable from this `service`")
+	maxNodes := fs.Int("max-nodes", defaults.Limits.MaxNodes, "refuse topologies with more services than this (0 = unlimited)")
+	maxEdges := fs.Int("max-edges", defaults.Limits.MaxEdges, "refuse topologies with more calls than this (0 = unlimited)")
+	maxPaths := fs.Int("max-paths", defaults.Limits.MaxPaths, "refuse topologies with more root-to-leaf call paths than this (0 = unlimited)")
+	minConfidence := fs.Float64("min-confidence", defaults.MinConfidence, "downgrade findings on calls whose `confidence` (0-1) is below this; 0 disables")
+	sortBy := fs.String("sort", "severity", "finding order: severity, impact (largest blast radius first) or criticality (most critical calls first)")
+	debug := fs.Bool("debug", false, "write a JSON trace of every rule decision to stderr")
+	verbose := fs.Bool("verbose", false, "report progress and total elapsed time on stderr")
+	printSchema := fs.Bool("print-schema", false, "print the topology JSON Schema and exit")
+	dumpFormat := fs.String("dump-rules", "", "print the rule catalog (IDs, severities, parameters, help) in this `format` (json) and exit")
+	initDir := fs.String("init", "", "scan the Go sources under this `dir` and print a starter topology, then exit")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cascadeguard [flags] <topology.yaml>\n       cascadeguard [flags] -batch <dir>\n       cascadeguard -init <src-dir> > topology.yaml")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *printSchema {
+		schema, err := parser.Schema()
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		fmt.Fprintln(stdout, string(schema))
+		return 0
+	}
+	if *dumpFormat != "" {
+		if err := dumpRules(*dumpFormat, stdout); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+	if *initDir != "" {
+		return runInit(*initDir, stdout, stderr)
+	}
+
+	opts := defaults
+	if *configPath != "" {
+		f, err := os.Open(*configPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		opts, err = LoadOptions(f, opts)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %s: %v\n", *configPath, err)
+			return 2
+		}
+	}
+	failOnSet := false
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "fail-on":
+			opts.FailOn = *failOn
+			failOnSet = true
+		case "fail-on-rule":
+			opts.FailOnRules = failRules.sortedKeys()
+		case "enable":
+			opts.Enable = enabled.sortedKeys()
+		case "max-nodes":
+			opts.Limits.MaxNodes = *maxNodes
+		case "max-edges":
+			opts.Limits.MaxEdges = *maxEdges
+		case "max-paths":
+			opts.Limits.MaxPaths = *maxPaths
+		case "plugin":
+			opts.Plugins = plugins
+		case "min-confidence":
+			opts.MinConfidence = *minConfidence
+		}
+	})
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if failOnSet && len(opts.ExitCodes) > 0 {
+		fmt.Fprintln(stderr, "error: -fail-on cannot be combined with exit_codes, which replace it")
+		return 2
+	}
+	if *sortBy != "severity" && *sortBy != "impact" && *sortBy != "criticality" {
+		fmt.Fprintf(stderr, "error: invalid -sort %q (want severity, impact or criticality)\n", *sortBy)
+		return 2
+	}
+	if *printConfig {
+		if err := opts.WriteJSON(stdout); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+	var filter findingFilter
+	if *filterExpr != "" {
+		var err error
+		if filter, err = parseFilter(*filterExpr); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		if *batch != "" || *stream {
+			fmt.Fprintln(stderr, "error: -filter cannot be combined with -batch or -stream")
+			return 2
+		}
+	}
+	if err := checkSinceRef(*since); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if *newOnly && *compare == "" {
+		fmt.Fprintln(stderr, "error: -only-new needs a baseline given with -compare")
+		return 2
+	}
+	explicitOutput := len(outputs) > 0
+	if len(outputs) == 0 {
+		outputs = outputFlags{{Format: "text"}}
+	}
+	if *batch != "" {
+		if *stream || *root != "" || *since != "" || *compare != "" || *violationsOnly || *sortBy != "severity" ||
+			*summaryJSON || *verbose || *debug {
+			fmt.Fprintln(stderr, "error: -batch cannot be combined with -stream, -root, -since, -compare, -only-new, -violations-only, -sort impact|criticality, -summary-json, -verbose or -debug")
+			return 2
+		}
+		return runBatch(*batch, *input, opts, outputs, stdout, stderr)
 	}
-	data, err := os.ReadFile(os.Args[1])
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	if *stream {
+		if *root != "" || *since != "" || *compare != "" || *violationsOnly || *sortBy != "severity" {
+			fmt.Fprintln(stderr, "error: -stream cannot be combined with -root, -since, -compare, -violations-only or -sort impact|criticality")
+			return 2
+		}
+		return runStream(fs.Arg(0), *input, opts, outputs, stdout, stderr)
+	}
+
+	reporter := progress.Nop
+	if *verbose {
+		reporter = &progress.Writer{W: stderr}
+	}
+	topo, err := loadTopology(fs.Arg(0), *input)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(2)
-	}
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "parse error: %v\n", err)
-		os.Exit(2)
-	}
-	var edges []CallEdge
-	for svc, sc := range cfg.Services {
-		for _, c := range sc.Calls {
-			var t time.Duration
-			if c.Timeout != "" {
-				var err error
-				t, err = time.ParseDuration(c.Timeout)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "error: %s->%s invalid timeout %q: %v\n", svc, c.Target, c.Timeout, err)
-					os.Exit(2)
-				}
-			}
-			if c.Retries < 0 {
-				fmt.Fprintf(os.Stderr, "error: %s->%s retries must be non-negative\n", svc, c.Target)
-				os.Exit(2)
-			}
-			m := c.Method
-			if m == "" {
-				m = "GET"
-			}
-			edges = append(edges, CallEdge{Source: svc, Target: c.Target,
-				Timeout: t, Retries: c.Retries, CircuitBreaker: c.CircuitBreaker,
-				Method: m, BackoffJitter: c.BackoffJitter})
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	reporter.Add(progress.Files, 1)
+	edges, services, err := topologyEdges(topo)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	cg, err := graph.BuildCallGraph(topo)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	labels, _ := parseEdgeLabel(opts.EdgeLabel) // checked by Validate
+	if err := applyEdgeLabel(edges, labels); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if *root != "" {
+		edges = ReachableFrom(edges, *root)
+		if len(edges) == 0 {
+			fmt.Fprintf(stderr, "error: -root %q has no outbound calls in the topology\n", *root)
+			return 2
 		}
+		cg = cg.ReachableFrom(*root)
 	}
 	g := NewGraph(edges)
+	if err := g.CheckLimits(opts.Limits); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	g.configure(opts, services, topo)
+	g.Progress = reporter
+	g.Entry = *root
+	if *debug {
+		g.Logger = slog.New(slog.NewJSONHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
 	findings := g.Analyze()
+	tmpls, _ := parseMessageTemplates(opts.Messages, opts.MessageVars) // checked by Validate
+	if err := applyMessageTemplates(findings, tmpls, opts.MessageVars); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if *since != "" {
+		files, err := changedFiles(filepath.Dir(fs.Arg(0)), *since)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		findings = onlyTouched(findings, servicesForFiles(files, services))
+	}
+	if filter != nil {
+		findings = filter.apply(findings)
+	}
+	switch *sortBy {
+	case "impact":
+		sortByImpact(findings)
+	case "criticality":
+		g.sortByCriticality(findings)
+	}
+	gated := findings
+	if *compare != "" {
+		baseline, err := loadBaseline(*compare)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		d := output.DiffFindings(baseline, toViolations(findings))
+		tagAges(findings, d)
+		gated = onlyNew(findings)
+		if *newOnly {
+			findings = gated
+		}
+		if !explicitOutput {
+			outputs = nil
+			if err := writeRegressionSummary(d, *compare, stdout); err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				return 2
+			}
+		}
+	}
+	rendered := edges
+	if *violationsOnly {
+		rendered = violationSubgraph(edges, findings)
+	}
+	if err := writeOutputs(outputs, fs.Arg(0), rendered, cg, findings, stdout); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	exitCode := opts.failPolicy().exitCode(gated)
+	if *verbose {
+		fmt.Fprintf(stderr, "analysis finished in %v\n", time.Since(start).Round(time.Millisecond))
+	}
+	if *summaryJSON {
+		s := newRunSummary(findings, exitCode != 0, time.Since(start))
+		s.RecommendedEntryTimeoutMs = graph.RecommendEntryTimeout(cg).Milliseconds()
+		writeSummary(s, stderr)
+	}
+	return exitCode
+}
+
+// configure applies the analysis options and the topology's services and
+// tier policy to g.
+func (g *Graph) configure(opts Options, services map[string]Service, topo *parser.RawTopology) {
+	g.Services = services
+	g.Enabled = toSet(opts.Enable)
+	g.TierPolicy = tierPolicy(topo)
+	g.Config = opts.Rules
+	g.NamespaceConfig = opts.namespaceConfigs()
+	g.Plugins = opts.Plugins
+	g.Scopes, _ = opts.ruleScopes() // checked by Validate
+	g.MinConfidence = opts.MinConfidence
+}
+
+// severityTag is the fixed-width severity marker of the text report.
+func severityTag(severity string) string {
+	switch severity {
+	case "error":
+		return "ERR "
+	case "info":
+		return "INFO"
+	}
+	return "WARN"
+}
+
+// renderText writes the human-readable report: the numbered findings, each
+// with its stable fingerprint (and age, with -compare), the entry timeout
+// recommended for cg when there is one, and a Mermaid sketch of edges.
+func renderText(edges []CallEdge, cg *graph.CallGraph, findings []Finding, w io.Writer) error {
 	if len(findings) == 0 {
-		fmt.Println("No issues found in service topology.")
-		return
+		_, err := fmt.Fprintln(w, "No issues found in service topology.")
+		return err
 	}
-	fmt.Printf("Found %d issue(s):\n\n", len(findings))
+	fmt.Fprintf(w, "Found %d issue(s):\n\n", len(findings))
 	for i, f := range findings {
-		sev := "WARN"
-		if f.Severity == "error" {
-			sev = "ERR "
+		fmt.Fprintf(w, "%d. [%s][%s] %s\n   Path: %v\n   Blast radius: %d service(s)\n   Fingerprint: %s\n",
+			i+1, severityTag(f.Severity), f.Rule, f.Message, f.Path, f.BlastRadius, f.fingerprint())
+		if f.Age != "" {
+			fmt.Fprintf(w, "   Age: %s\n", f.Age)
 		}
-		fmt.Printf("%d. [%s][%s] %s\n   Path: %v\n\n", i+1, sev, f.Rule, f.Message, f.Path)
+		fmt.Fprintln(w)
 	}
-	fmt.Println("--- Mermaid Topology ---")
-	fmt.Println("graph LR")
+	if cg != nil {
+		if d := graph.RecommendEntryTimeout(cg); d > 0 {
+			fmt.Fprintf(w, "Recommended entry timeout: >= %v to accommodate current config, or reduce downstream budgets.\n\n", d)
+		}
+	}
+	if len(edges) == 0 {
+		return nil // stream mode keeps no edges to draw
+	}
+	fmt.Fprintln(w, "--- Mermaid Topology ---")
+	fmt.Fprintln(w, "graph LR")
 	for _, e := range edges {
-		fmt.Printf("  %s -->|\"t=%s r=%d\"| %s\n", e.Source, e.Timeout, e.Retries, e.Target)
+		if _, err := fmt.Fprintln(w, output.MermaidEdge(toOutputEdge(e))); err != nil {
+			return err
+		}
 	}
-	os.Exit(1)
+	return nil
 }
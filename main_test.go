@@ -0,0 +1,1263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/graph"
+	"github.com/cascadeguard/cascadeguard/parser"
+	"github.com/cascadeguard/cascadeguard/rules"
+)
+
+const sampleTopology = `services:
+  gateway:
+    calls:
+      - target: user-svc
+        timeout: 3s
+        retries: 3
+        circuit_breaker: false
+        method: GET
+  user-svc:
+    calls:
+      - target: db-svc
+        timeout: 5s
+        retries: 2
+        circuit_breaker: false
+        method: POST
+`
+
+func writeTopology(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "topology.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseOutputSpec(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    outputSpec
+		wantErr bool
+	}{
+		{in: "sarif:results.sarif", want: outputSpec{Format: "sarif", Path: "results.sarif"}},
+		{in: "md:comment.md", want: outputSpec{Format: "md", Path: "comment.md"}},
+		{in: "text", want: outputSpec{Format: "text"}},
+		{in: "mermaid:-", want: outputSpec{Format: "mermaid", Path: "-"}},
+		{in: "github", want: outputSpec{Format: "github"}},
+		{in: "issues:issues.json", want: outputSpec{Format: "issues", Path: "issues.json"}},
+		{in: "html:out.html", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parseOutputSpec(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: err=%v, wantErr=%v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMultipleOutputsDispatch(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	dir := t.TempDir()
+	sarifPath := filepath.Join(dir, "results.sarif")
+	mdPath := filepath.Join(dir, "comment.md")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-output", "sarif:" + sarifPath, "-output", "md:" + mdPath, topo}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code: want 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected nothing on stdout when every output goes to a file, got:\n%s", stdout.String())
+	}
+
+	sarif, err := os.ReadFile(sarifPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(sarif, &doc); err != nil {
+		t.Fatalf("sarif output is not JSON: %v", err)
+	}
+	if doc["version"] != "2.1.0" {
+		t.Errorf("sarif version: got %v", doc["version"])
+	}
+
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "| Severity | Rule |") {
+		t.Errorf("markdown output missing findings table:\n%s", md)
+	}
+}
+
+func TestDefaultOutputIsText(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{topo}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code: want 1, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "Found ") || !strings.Contains(stdout.String(), "graph LR") {
+		t.Errorf("unexpected text output:\n%s", stdout.String())
+	}
+}
+
+func TestUnknownOutputFormatIsInputError(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-output", "html", topo}, &stdout, &stderr); code != 2 {
+		t.Fatalf("exit code: want 2, got %d", code)
+	}
+}
+
+func TestPrintSchema(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-print-schema"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code: want 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatalf("schema output is not JSON: %v", err)
+	}
+}
+
+func TestFailPolicy(t *testing.T) {
+	findings := []Finding{
+		{Rule: "backoff-no-jitter", Severity: "warning"},
+		{Rule: "non-idempotent-retry", Severity: "error"},
+		{Rule: "uniform-config", Severity: "info"},
+	}
+	warnings := []Finding{
+		{Rule: "backoff-no-jitter", Severity: "warning"},
+		{Rule: "retry-without-cb", Severity: "warning"},
+	}
+	tests := []struct {
+		name     string
+		findings []Finding
+		policy   failPolicy
+		want     bool
+	}{
+		{"default threshold fails on any finding", findings, failPolicy{MinSeverity: "info"}, true},
+		{"error threshold met", findings, failPolicy{MinSeverity: "error"}, true},
+		{"error threshold not met", warnings, failPolicy{MinSeverity: "error"}, false},
+		{"never ignores severity", findings, failPolicy{MinSeverity: "never"}, false},
+		{"rule gate matches below threshold", warnings,
+			failPolicy{MinSeverity: "error", Rules: ruleList{"retry-without-cb": true}}, true},
+		{"rule gate with never", findings,
+			failPolicy{MinSeverity: "never", Rules: ruleList{"non-idempotent-retry": true}}, true},
+		{"rule gate does not match", warnings,
+			failPolicy{MinSeverity: "never", Rules: ruleList{"non-idempotent-retry": true}}, false},
+		{"no findings", nil, failPolicy{MinSeverity: "info", Rules: ruleList{"x": true}}, false},
+	}
+	for _, tc := range tests {
+		if got := tc.policy.exitCode(tc.findings) != 0; got != tc.want {
+			t.Errorf("%s: fails=%v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExitCodeMapping(t *testing.T) {
+	findings := []Finding{
+		{Rule: "backoff-no-jitter", Severity: "warning"},
+		{Rule: "non-idempotent-retry", Severity: "error"},
+		{Rule: "no-entry-point", Severity: "info"},
+	}
+	codes := map[string]int{"error": 4, "warning": 0, "info": 1}
+	tests := []struct {
+		name     string
+		findings []Finding
+		policy   failPolicy
+		want     int
+	}{
+		{"highest mapped code wins", findings, failPolicy{MinSeverity: "info", ExitCodes: codes}, 4},
+		{"info maps to 1", []Finding{findings[0], findings[2]}, failPolicy{ExitCodes: codes}, 1},
+		{"warnings map to 0 despite fail-on", findings[:1], failPolicy{MinSeverity: "info", ExitCodes: codes}, 0},
+		{"unmapped severity exits 0", findings[:1], failPolicy{ExitCodes: map[string]int{"error": 1}}, 0},
+		{"fail-on-rule still exits 1", findings[:1],
+			failPolicy{ExitCodes: codes, Rules: ruleList{"backoff-no-jitter": true}}, 1},
+		{"without a mapping fail-on gives 1", findings, failPolicy{MinSeverity: "warning"}, 1},
+	}
+	for _, tc := range tests {
+		if got := tc.policy.exitCode(tc.findings); got != tc.want {
+			t.Errorf("%s: exit code %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(`{"exit_codes": {"error": 3, "warning": 5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", config, writeTopology(t, sampleTopology)}, &stdout, &stderr); code != 5 {
+		t.Errorf("sample topology has warnings mapped to 5: got exit %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"exit_codes": {"error": 2}}`), DefaultOptions()); err == nil {
+		t.Error("exit code 2 should be rejected as reserved")
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"exit_codes": {"policy": 4}}`), DefaultOptions()); err == nil {
+		t.Error("exit_codes keys other than info, warning and error should be rejected")
+	}
+	stderr.Reset()
+	if code := run([]string{"-config", config, "-fail-on", "never", writeTopology(t, sampleTopology)}, &stdout, &stderr); code != 2 {
+		t.Errorf("-fail-on with exit_codes: want exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "exit_codes") {
+		t.Errorf("error should name exit_codes: %s", stderr.String())
+	}
+}
+
+func TestFailOnRuleFlag(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-fail-on", "never", "-output", "md:-", topo}, &stdout, &stderr); code != 0 {
+		t.Fatalf("-fail-on never: want exit 0, got %d", code)
+	}
+	stdout.Reset()
+	code := run([]string{"-fail-on", "never", "-fail-on-rule", "non-idempotent-retry", topo}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("-fail-on-rule non-idempotent-retry: want exit 1, got %d", code)
+	}
+	if code := run([]string{"-fail-on", "bogus", topo}, &stdout, &stderr); code != 2 {
+		t.Fatalf("invalid -fail-on: want exit 2, got %d", code)
+	}
+	if code := run([]string{"-fail-on", "never", "-fail-on-rule", "non-idempotnet-retry", topo}, &stdout, &stderr); code != 2 {
+		t.Fatalf("misspelled -fail-on-rule: want exit 2, got %d", code)
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"fail_on_rules": ["non-idempotnet-retry"]}`), DefaultOptions()); err == nil {
+		t.Error("misspelled fail_on_rules entry should be rejected")
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"fail_on_rules": ["org-policy"], "plugins": ["checker"]}`), DefaultOptions()); err != nil {
+		t.Errorf("with plugins, fail_on_rules may name their rules: %v", err)
+	}
+}
+
+func TestViolationsOnlyMermaid(t *testing.T) {
+	topo := writeTopology(t, `services:
+  gateway:
+    calls:
+      - target: api
+        timeout: 3s
+      - target: static
+        timeout: 1s
+  api:
+    calls:
+      - target: db
+        timeout: 5s
+`)
+	var stdout, stderr bytes.Buffer
+	run([]string{"-violations-only", "-output", "mermaid", topo}, &stdout, &stderr)
+	out := stdout.String()
+	if strings.Contains(out, "static") {
+		t.Errorf("clean edge gateway->static should be excluded:\n%s", out)
+	}
+	if !strings.Contains(out, "gateway -->") || !strings.Contains(out, "api -->") {
+		t.Errorf("timeout-inversion edges should remain:\n%s", out)
+	}
+	if !strings.Contains(out, "stroke:red") {
+		t.Errorf("violation edges should still be styled red:\n%s", out)
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	findings := []Finding{
+		{Rule: "retry-without-cb", Severity: "warning"},
+		{Rule: "retry-without-cb", Severity: "warning"},
+		{Rule: "non-idempotent-retry", Severity: "error"},
+	}
+	s := newRunSummary(findings, true, 1500*time.Millisecond)
+	if s.Total != 3 || !s.Failed || s.ElapsedMs != 1500 {
+		t.Errorf("unexpected summary header: %+v", s)
+	}
+	if s.BySeverity["warning"] != 2 || s.BySeverity["error"] != 1 {
+		t.Errorf("by_severity: %v", s.BySeverity)
+	}
+	if s.ByRule["retry-without-cb"] != 2 || s.ByRule["non-idempotent-retry"] != 1 {
+		t.Errorf("by_rule: %v", s.ByRule)
+	}
+}
+
+func TestTextReportRecommendsEntryTimeout(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	run([]string{topo}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Recommended entry timeout: >= 27s to accommodate current config") {
+		t.Errorf("missing entry timeout recommendation:\n%s", stdout.String())
+	}
+}
+
+func TestRenderersShareEdgeLabel(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	cfg := filepath.Join(t.TempDir(), "options.json")
+	if err := os.WriteFile(cfg, []byte(`{"edge_label": "{{.Method}} {{.Timeout}} x{{.Retries}}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{topo}, {"-config", cfg, topo}} {
+		want := map[bool]string{false: "3s/3", true: "GET 3s x3"}[len(args) > 1]
+		for _, format := range []string{"text", "mermaid", "svg"} {
+			var stdout, stderr bytes.Buffer
+			run(append([]string{"-output", format}, args...), &stdout, &stderr)
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("%s %v: want edge label %q:\n%s%s", format, args, want, stdout.String(), stderr.String())
+			}
+		}
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"edge_label": "{{.NoSuchField}}"}`), DefaultOptions()); err == nil {
+		t.Error("expected load-time error for an invalid edge label template")
+	}
+}
+
+func TestBatchSharedBackend(t *testing.T) {
+	dir := t.TempDir()
+	teams := map[string]string{
+		"checkout.yaml": `services:
+  checkout:
+    calls:
+      - {target: payments-db, timeout: 1s, retries: 2, circuit_breaker: true, backoff_jitter: true}
+`,
+		"billing.yaml": `services:
+  billing:
+    calls:
+      - {target: payments-db, timeout: 1s, retries: 1, circuit_breaker: true, backoff_jitter: true}
+      - {target: ledger, timeout: 1s}
+`,
+		"README.md": "not a topology",
+	}
+	for name, body := range teams {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-batch", dir}, &stdout, &stderr)
+	out := stdout.String()
+	if code != 1 {
+		t.Errorf("want exit 1 for the shared-backend finding, got %d (%s)", code, stderr.String())
+	}
+	for _, want := range []string{
+		"=== " + filepath.Join(dir, "billing.yaml") + " ===",
+		"=== " + filepath.Join(dir, "checkout.yaml") + " ===",
+		"=== cross-file ===",
+		"[INFO][shared-backend] payments-db is called from 2 topology files",
+		"up to 5 requests",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ledger is called") {
+		t.Errorf("ledger has a single caller file:\n%s", out)
+	}
+
+	stdout.Reset()
+	run([]string{"-batch", dir, "-output", "github", "-fail-on", "never"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "title=shared-backend::payments-db") {
+		t.Errorf("github output missing the cross-file finding:\n%s", stdout.String())
+	}
+
+	if code := run([]string{"-batch", dir, "-output", "sarif"}, &stdout, &stderr); code != 2 {
+		t.Errorf("unsupported batch output: want exit 2, got %d", code)
+	}
+	for _, flags := range [][]string{
+		{"-root", "nope"},
+		{"-compare", filepath.Join(dir, "missing.sarif")},
+		{"-compare", filepath.Join(dir, "missing.sarif"), "-only-new"},
+		{"-since", "HEAD"},
+		{"-sort", "impact"},
+		{"-violations-only"},
+		{"-summary-json"},
+		{"-verbose"},
+		{"-debug"},
+		{"-stream"},
+	} {
+		if code := run(append([]string{"-batch", dir}, flags...), &stdout, &stderr); code != 2 {
+			t.Errorf("-batch with %v: want exit 2, got %d", flags, code)
+		}
+	}
+}
+
+func TestBatchFindingsNameTheirFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(path, []byte(sampleTopology), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	run([]string{"-batch", dir, "-output", "github"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "file="+path) {
+		t.Errorf("want findings attributed to %s:\n%s", path, stdout.String())
+	}
+}
+
+func TestSummaryJSONOnStderr(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	run([]string{"-summary-json", topo}, &stdout, &stderr)
+	var s runSummary
+	if err := json.Unmarshal(stderr.Bytes(), &s); err != nil {
+		t.Fatalf("stderr is not a JSON summary: %v\n%s", err, stderr.String())
+	}
+	if s.Total == 0 || !s.Failed {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if s.RecommendedEntryTimeoutMs != 27000 {
+		t.Errorf("recommended entry timeout: want 27000ms (3s×4 + 5s×3), got %d", s.RecommendedEntryTimeoutMs)
+	}
+	if strings.Contains(stdout.String(), "by_severity") {
+		t.Error("summary must not be written to stdout")
+	}
+}
+
+func TestOptionsRoundTrip(t *testing.T) {
+	opts := DefaultOptions()
+	opts.FailOn = "error"
+	opts.FailOnRules = []string{"non-idempotent-retry"}
+	opts.Enable = []string{"orphaned-retry"}
+	opts.Rules.AmplificationThreshold = 4
+	opts.Rules.SlowDownstreamTimeout = Duration(45 * time.Second)
+
+	var buf bytes.Buffer
+	if err := opts.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadOptions(&buf, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, opts) {
+		t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", got, opts)
+	}
+}
+
+func TestLoadOptionsRejectsUnknownKeys(t *testing.T) {
+	if _, err := LoadOptions(strings.NewReader(`{"fail_onn": "error"}`), DefaultOptions()); err == nil {
+		t.Fatal("expected error for misspelled key")
+	}
+}
+
+func TestPrintConfigReproducesAnalysis(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	flags := []string{"-fail-on", "error", "-enable", "orphaned-retry", "-max-edges", "50"}
+
+	var cfg, stderr bytes.Buffer
+	if code := run(append(flags, "-print-config"), &cfg, &stderr); code != 0 {
+		t.Fatalf("-print-config: exit %d (%s)", code, stderr.String())
+	}
+	cfgPath := filepath.Join(t.TempDir(), "options.json")
+	if err := os.WriteFile(cfgPath, cfg.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var fromFlags, fromConfig bytes.Buffer
+	codeFlags := run(append(flags, "-output", "md", topo), &fromFlags, &stderr)
+	codeConfig := run([]string{"-config", cfgPath, "-output", "md", topo}, &fromConfig, &stderr)
+	if codeFlags != codeConfig {
+		t.Errorf("exit codes differ: flags=%d config=%d", codeFlags, codeConfig)
+	}
+	if fromFlags.String() != fromConfig.String() {
+		t.Errorf("reports differ:\n--- flags ---\n%s\n--- config ---\n%s", fromFlags.String(), fromConfig.String())
+	}
+
+	var again bytes.Buffer
+	run([]string{"-config", cfgPath, "-print-config"}, &again, &stderr)
+	if again.String() != cfg.String() {
+		t.Errorf("re-printed config differs:\n%s\nvs\n%s", again.String(), cfg.String())
+	}
+}
+
+func TestTextNumberingStableAcrossRuns(t *testing.T) {
+	topo := writeTopology(t, `services:
+  gateway:
+    calls:
+      - {target: orders, timeout: 1s, retries: 3}
+      - {target: users, timeout: 1s, retries: 3}
+      - {target: search, timeout: 1s, retries: 3}
+  orders:
+    calls:
+      - {target: db, timeout: 5s, retries: 2, method: POST}
+  users:
+    calls:
+      - {target: db, timeout: 5s, retries: 2}
+  search:
+    calls:
+      - {target: db, timeout: 5s, retries: 2}
+`)
+	var first string
+	for i := 0; i < 20; i++ {
+		var stdout, stderr bytes.Buffer
+		run([]string{topo}, &stdout, &stderr)
+		if i == 0 {
+			first = stdout.String()
+			if !strings.Contains(first, "   Fingerprint: ") {
+				t.Fatalf("text output lacks fingerprints:\n%s", first)
+			}
+			continue
+		}
+		if stdout.String() != first {
+			t.Fatalf("run %d differs from run 0:\n--- run 0 ---\n%s\n--- run %d ---\n%s", i, first, i, stdout.String())
+		}
+	}
+}
+
+func TestGitHubOutputAnnotatesTopologyFile(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	run([]string{"-output", "github", topo}, &stdout, &stderr)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "::") || !strings.Contains(l, "file="+topo+",") {
+			t.Errorf("annotation without topology file: %s", l)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "::error ") {
+		t.Errorf("want errors first, got %s", lines[0])
+	}
+}
+
+func TestCustomMessageTemplate(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	cfg := filepath.Join(t.TempDir(), "options.json")
+	opts := `{"messages": {"retry-amplification": "{{.Rule}} on {{index .Path 0}}: {{.Message}} ({{.Vars.tracker}}/RES-1)"},
+	          "message_vars": {"tracker": "https://tracker.example"}}`
+	if err := os.WriteFile(cfg, []byte(opts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", cfg, "-output", "md", topo}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit %d (%s)", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "retry-amplification on gateway: ") || !strings.Contains(out, "(https://tracker.example/RES-1)") {
+		t.Errorf("template not applied:\n%s", out)
+	}
+	if !strings.Contains(out, "has 3 retries but no circuit breaker") {
+		t.Errorf("rules without a template should keep the built-in message:\n%s", out)
+	}
+}
+
+func TestInvalidMessageTemplateRejected(t *testing.T) {
+	for _, tmpl := range []string{`{{.Rule`, `{{.NoSuchField}}`, `{{.Vars.missing}}`} {
+		doc, _ := json.Marshal(map[string]any{"messages": map[string]string{"retry-amplification": tmpl}})
+		if _, err := LoadOptions(bytes.NewReader(doc), DefaultOptions()); err == nil {
+			t.Errorf("%q: expected load-time error", tmpl)
+		}
+	}
+}
+
+func TestInvalidScopeRejected(t *testing.T) {
+	for _, doc := range []string{
+		`{"scopes": {"orphaned-retry": "roots"}}`,
+		`{"scopes": {"timeout-inversion": "leaf-edges"}}`,
+	} {
+		if _, err := LoadOptions(strings.NewReader(doc), DefaultOptions()); err == nil {
+			t.Errorf("%s: expected load-time error", doc)
+		}
+	}
+	if _, err := LoadOptions(strings.NewReader(`{"scopes": {"orphaned-retry": "entry-edges"}}`), DefaultOptions()); err != nil {
+		t.Errorf("valid scope rejected: %v", err)
+	}
+}
+
+func TestServicesForFiles(t *testing.T) {
+	services := map[string]Service{
+		"api":     {Sources: []string{"services/api"}},
+		"billing": {Sources: []string{"services/billing/main.go", "lib/billing/*.go"}},
+		"db":      {},
+	}
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"services/api/handler.go"}, []string{"api"}},
+		{[]string{"services/api2/handler.go"}, nil},
+		{[]string{"services/billing/main.go", "README.md"}, []string{"billing"}},
+		{[]string{"lib/billing/charge.go"}, []string{"billing"}},
+		{[]string{"lib/billing/sub/charge.go"}, nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		got := servicesForFiles(tt.files, services)
+		var names []string
+		for name := range got {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%v: want %v, got %v", tt.files, tt.want, names)
+		}
+	}
+}
+
+func TestOnlyTouched(t *testing.T) {
+	findings := []Finding{
+		{Rule: "retry-without-cb", Path: []string{"gateway", "api"}},
+		{Rule: "retry-without-cb", Path: []string{"api", "db"}},
+		{Rule: "retry-amplification", Path: []string{"gateway", "api", "db"}},
+		{Rule: "single-point-of-failure", Path: []string{"api"}},
+		{Rule: "single-point-of-failure", Path: []string{"db"}},
+		{Rule: "no-entry-point"},
+	}
+	got := onlyTouched(findings, map[string]bool{"api": true})
+	var kept []string
+	for _, f := range got {
+		kept = append(kept, strings.Join(f.Path, "->"))
+	}
+	want := []string{"api->db", "gateway->api->db", "api"}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("want findings on calls made by api %v, got %v", want, kept)
+	}
+}
+
+func TestSinceLimitsFindingsToChangedServices(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	topo := filepath.Join(dir, "topology.yaml")
+	doc := `services:
+  gateway:
+    sources: [gateway]
+    calls:
+      - target: api
+        timeout: 2s
+        retries: 2
+  api:
+    sources: [api]
+    calls:
+      - target: db
+        timeout: 1s
+        retries: 2
+`
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	for path, body := range map[string]string{topo: doc, filepath.Join(dir, "api", "main.go"): "package api\n"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	if err := os.WriteFile(filepath.Join(dir, "api", "main.go"), []byte("package api // changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"-since", "HEAD", "-output", "md", topo}, &stdout, &stderr)
+	out := stdout.String()
+	if !strings.Contains(out, "api → db") {
+		t.Errorf("want findings on api->db:\n%s\n%s", out, stderr.String())
+	}
+	if strings.Contains(out, "| gateway → api |") {
+		t.Errorf("gateway did not change; its calls should be skipped:\n%s", out)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-since", "no-such-ref", topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("unknown ref: want exit 2, got %d (%s)", code, stderr.String())
+	}
+
+	stderr.Reset()
+	leak := filepath.Join(dir, "leak.txt")
+	if code := run([]string{"-since", "--output=" + leak, topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("option-like ref: want exit 2, got %d (%s)", code, stderr.String())
+	}
+	if _, err := os.Stat(leak); err == nil {
+		t.Errorf("option-like ref was passed to git and wrote %s", leak)
+	}
+}
+
+func TestPluginFindingsReported(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "checker.sh")
+	body := "#!/bin/sh\ncat >/dev/null\n" +
+		`echo '{"violations":[{"rule":"org-rule","severity":"warning","message":"from plugin","path":["gateway"]}]}'` + "\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	run([]string{"-plugin", script, "-output", "md", topo}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "| warning | `org-rule` | gateway | from plugin |") {
+		t.Errorf("plugin finding missing:\n%s\n%s", stdout.String(), stderr.String())
+	}
+}
+
+func TestResilience4jInput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-input", "resilience4j", "-output", "md", "parser/testdata/resilience4j.yaml"}, &stdout, &stderr)
+	if code == 2 {
+		t.Fatalf("input error: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "checkout → inventory") {
+		t.Errorf("expected findings on the mapped checkout->inventory call:\n%s", stdout.String())
+	}
+	if code := run([]string{"-input", "nope", "x.yaml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("unknown input format: want exit 2, got %d", code)
+	}
+}
+
+func TestTopologyIncludes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "teams"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"topology.yaml":    "includes: [teams/users.yaml]\nservices:\n  gateway:\n    calls:\n      - target: user-svc\n        timeout: 3s\n",
+		"teams/users.yaml": "includes: [db.yaml]\nservices:\n  user-svc:\n    calls:\n      - target: db-svc\n        timeout: 1s\n",
+		"teams/db.yaml":    "services:\n  db-svc: {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	topo, err := loadTopology(filepath.Join(dir, "topology.yaml"), "topology")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, svc := range []string{"gateway", "user-svc", "db-svc"} {
+		if _, ok := topo.Services[svc]; !ok {
+			t.Errorf("service %s missing after includes: %v", svc, topo.Services)
+		}
+	}
+	edges, _, err := topologyEdges(topo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 2 {
+		t.Errorf("want gateway->user-svc and user-svc->db-svc, got %+v", edges)
+	}
+}
+
+// TestCallGraphConversionsAgree guards against drift between the CLI's
+// graph.BuildCallGraph and rules.ToCallGraph, which the rules use: both must
+// give the same answers for the same topology.
+func TestCallGraphConversionsAgree(t *testing.T) {
+	topologies := map[string]string{
+		"sample": sampleTopology,
+		"aliased fan-out": `services:
+  gateway:
+    namespace: edge
+    calls:
+      - {target: orders, timeout: 4s, retries: 2}
+      - {target: users, timeout: 2s, retries: 1, call_probability: 0.5}
+  orders:
+    namespace: shop
+    aliases: [orders-api]
+    calls:
+      - {target: db, timeout: 1s, retries: 1, failure_probability: 0.1}
+  users:
+    calls:
+      - {target: orders-api, timeout: 1s, retries: 3}
+`,
+	}
+	for name, yaml := range topologies {
+		topo, err := parser.ParseTopology(strings.NewReader(yaml))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		built, err := graph.BuildCallGraph(topo)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		edges, services, err := topologyEdges(topo)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		g := NewGraph(edges)
+		g.Services = services
+		converted := rules.ToCallGraph(g)
+
+		if a, b := built.Roots(), converted.Roots(); !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: roots %v vs %v", name, a, b)
+		}
+		if a, b := built.MaxBackendRequests(), converted.MaxBackendRequests(); !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: backend requests %v vs %v", name, a, b)
+		}
+		if a, b := built.ExpectedBackendLoad("gateway"), converted.ExpectedBackendLoad("gateway"); !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: expected load %v vs %v", name, a, b)
+		}
+		if a, b := graph.RecommendEntryTimeout(built), graph.RecommendEntryTimeout(converted); a != b {
+			t.Errorf("%s: entry timeout %v vs %v", name, a, b)
+		}
+		if a, b := built.ArticulationPoints(), converted.ArticulationPoints(); !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: articulation points %v vs %v", name, a, b)
+		}
+		for _, e := range edges {
+			for _, svc := range []string{e.Source, e.Target} {
+				a, _ := built.Node(svc)
+				b, _ := converted.Node(svc)
+				if a != b {
+					t.Errorf("%s: node %s %+v vs %+v", name, svc, a, b)
+				}
+			}
+		}
+	}
+}
+
+func TestTopologyIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	if err := os.WriteFile(a, []byte("includes: [b.yaml]\nservices:\n  x: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("includes: [a.yaml]\nservices:\n  y: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{a}, &stdout, &stderr); code != 2 {
+		t.Fatalf("include cycle: want exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "include cycle: "+a+" -> "+b+" -> "+a) {
+		t.Errorf("error should name the cycle, got: %s", stderr.String())
+	}
+}
+
+func TestTopologyIncludeDuplicateService(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "main.yaml")
+	if err := os.WriteFile(root, []byte("includes: [other.yaml]\nservices:\n  x: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("services:\n  x: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTopology(root, "topology"); err == nil || !strings.Contains(err.Error(), `service "x"`) {
+		t.Errorf("want duplicate service error, got %v", err)
+	}
+}
+
+func TestInitStarterTopologyRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users/client.go": `package users
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/avast/retry-go"
+)
+
+func NewClient() *http.Client { return &http.Client{Timeout: 2 * time.Second} }
+
+func FetchUser(c *http.Client) error {
+	return retry.Do(func() error { _, err := c.Get("http://users/1"); return err }, retry.Attempts(2))
+}
+`,
+		"users/client_test.go":  "package users\nvar _ = context.WithTimeout(ctx, time.Hour)\n",
+		"billing/charge.go":     "package billing\nfunc Charge() { context.WithTimeout(ctx, 500*time.Millisecond) }\n",
+		"billing/testdata/x.go": "package x\nfunc X() { context.WithTimeout(ctx, time.Hour) }\n",
+		"billing/broken.go":     "package billing\nfunc {",
+		"orders/fetch.go": `package orders
+
+func FetchOrders(url string) error {
+	client := retryablehttp.NewClient()
+	return retry.Do(func() error { _, err := client.Get(url); return err }, retry.Attempts(3))
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-init", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "broken.go") {
+		t.Errorf("unparsable file should be reported as skipped, got: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "# found warning: layered-retry (20 attempts across transport and application) at line 5") {
+		t.Errorf("layered retry should be noted as a finding:\n%s", stdout.String())
+	}
+	topo, err := parser.ParseTopology(&stdout)
+	if err != nil {
+		t.Fatalf("generated topology does not parse: %v", err)
+	}
+	edges, _, err := topologyEdges(topo)
+	if err != nil {
+		t.Fatalf("generated topology does not validate: %v", err)
+	}
+	want := map[string]CallEdge{
+		"billing->todo-charge":      {Timeout: 500 * time.Millisecond},
+		"users->todo-new-client":    {Timeout: 2 * time.Second},
+		"users->todo-fetch-user":    {Retries: 2},
+		"orders->todo-fetch-orders": {Retries: 19},
+	}
+	if len(edges) != len(want) {
+		t.Fatalf("want %d calls (tests and testdata skipped), got %+v", len(want), edges)
+	}
+	for _, e := range edges {
+		w, ok := want[e.Source+"->"+e.Target]
+		if !ok {
+			t.Errorf("unexpected call %s->%s", e.Source, e.Target)
+			continue
+		}
+		if e.Timeout != w.Timeout || e.Retries != w.Retries {
+			t.Errorf("%s->%s: got timeout %v retries %d, want %v / %d", e.Source, e.Target, e.Timeout, e.Retries, w.Timeout, w.Retries)
+		}
+	}
+}
+
+func TestStreamModeReportsPerCallFindings(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-stream", "-output", "md", topo}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code: want 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "`retry-without-cb`") {
+		t.Errorf("expected per-call findings:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "`retry-amplification`") {
+		t.Errorf("path-based rules should not run in stream mode:\n%s", stdout.String())
+	}
+	if code := run([]string{"-stream", "-output", "svg", topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("-stream with svg output: want exit 2, got %d", code)
+	}
+	if code := run([]string{"-stream", "-plugin", "true", topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("-stream with a plugin: want exit 2, got %d", code)
+	}
+	cfg := filepath.Join(t.TempDir(), "options.json")
+	if err := os.WriteFile(cfg, []byte(`{"namespaces": {"payments": {"amplification_threshold": 4}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"-stream", "-config", cfg, topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("-stream with namespaces settings: want exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "namespaces") {
+		t.Errorf("error should name the namespaces settings: %s", stderr.String())
+	}
+}
+
+func TestCompareReportsRegressions(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "old.sarif")
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-output", "sarif:" + baseline, topo}, &stdout, &stderr); code != 1 {
+		t.Fatalf("baseline run: want exit 1, got %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-compare", baseline, topo}, &stdout, &stderr); code != 0 {
+		t.Fatalf("unchanged run: want exit 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), ": 0 new, 0 resolved,") {
+		t.Errorf("unchanged run summary:\n%s", stdout.String())
+	}
+
+	// Dropping user-svc's retries resolves findings; a new retried call
+	// without a timeout adds one.
+	changed := strings.Replace(sampleTopology, "        retries: 2\n", "        retries: 0\n", 1) +
+		"  db-svc:\n    calls:\n      - target: cache\n        retries: 2\n        circuit_breaker: true\n"
+	stdout.Reset()
+	code := run([]string{"-compare", baseline, writeTopology(t, changed)}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("regressed run: want exit 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"New:", "[unbounded-retry]", "Resolved:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("regression summary missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Mermaid") {
+		t.Errorf("-compare without -output should print only the summary:\n%s", out)
+	}
+}
+
+func TestInvalidCriticalityRejected(t *testing.T) {
+	topo := writeTopology(t, "services:\n  gateway:\n    calls:\n      - target: api\n        criticality: urgent\n")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{topo}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), `invalid criticality "urgent"`) {
+		t.Errorf("want exit 2 naming the criticality, got %d: %s", code, stderr.String())
+	}
+	if code := run([]string{"-sort", "criticality", writeTopology(t, sampleTopology)}, &stdout, &stderr); code == 2 {
+		t.Errorf("-sort criticality rejected: %s", stderr.String())
+	}
+}
+
+func TestCompareTagsFindingAge(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "old.sarif")
+	var stdout, stderr bytes.Buffer
+	base := "services:\n  gateway:\n    calls:\n      - target: api\n        timeout: 2s\n        retries: 2\n        circuit_breaker: true\n"
+	if code := run([]string{"-output", "sarif:" + baseline, writeTopology(t, base)}, &stdout, &stderr); code == 2 {
+		t.Fatalf("baseline run: %s", stderr.String())
+	}
+	// A second, unbounded call adds findings on api->db.
+	changed := base + "  api:\n    calls:\n      - target: db\n        retries: 1\n"
+	topo := writeTopology(t, changed)
+	run([]string{"-compare", baseline, "-output", "md", topo}, &stdout, &stderr)
+	out := stdout.String()
+	if !strings.Contains(out, "| new | `unbounded-retry` | api → db |") {
+		t.Errorf("the unbounded api->db retry should be new:\n%s", out)
+	}
+	if !strings.Contains(out, "| pre-existing | ") {
+		t.Errorf("baselined findings should be pre-existing:\n%s", out)
+	}
+
+	stdout.Reset()
+	run([]string{"-compare", baseline, "-only-new", "-output", "md", topo}, &stdout, &stderr)
+	if out := stdout.String(); strings.Contains(out, "pre-existing") || !strings.Contains(out, "| new |") {
+		t.Errorf("-only-new should keep only new findings:\n%s", out)
+	}
+	if code := run([]string{"-only-new", topo}, &stdout, &stderr); code != 2 {
+		t.Errorf("-only-new without -compare: want exit 2, got %d", code)
+	}
+}
+
+func TestFindingFilter(t *testing.T) {
+	findings := []Finding{
+		{Rule: "timeout-inversion", Severity: "error", Message: "gateway->payments 5s", Path: []string{"gateway", "payments"}},
+		{Rule: "backoff-no-jitter", Severity: "error", Message: "no jitter", Path: []string{"payments", "ledger"}},
+		{Rule: "retry-without-cb", Severity: "warning", Message: "no circuit breaker", Path: []string{"gateway", "search"}},
+	}
+	tests := []struct {
+		expr string
+		want []string // rules kept
+	}{
+		{"severity=error", []string{"timeout-inversion", "backoff-no-jitter"}},
+		{"rule!=backoff-no-jitter", []string{"timeout-inversion", "retry-without-cb"}},
+		{"path~payments", []string{"timeout-inversion", "backoff-no-jitter"}},
+		{"path !~ payments", []string{"retry-without-cb"}},
+		{"path=payments->ledger", []string{"backoff-no-jitter"}},
+		{`message~"circuit breaker"`, []string{"retry-without-cb"}},
+		{"severity=error AND rule!=backoff-no-jitter AND path~payments", []string{"timeout-inversion"}},
+		{"rule=backoff-no-jitter or severity=warning", []string{"backoff-no-jitter", "retry-without-cb"}},
+	}
+	for _, tc := range tests {
+		ff, err := parseFilter(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		var got []string
+		for _, f := range ff.apply(findings) {
+			got = append(got, f.Rule)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: kept %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "tier=edge", "rule", "rule=x AND", "rule=x XOR rule=y", "rule ! x", `rule="x`} {
+		if _, err := parseFilter(bad); err == nil {
+			t.Errorf("%q: expected a parse error", bad)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-filter", "rule=retry-without-cb", "-output", "md", writeTopology(t, sampleTopology)}, &stdout, &stderr)
+	if code != 1 || strings.Contains(stdout.String(), "timeout-inversion") || !strings.Contains(stdout.String(), "retry-without-cb") {
+		t.Errorf("-filter should keep only retry-without-cb (exit %d):\n%s", code, stdout.String())
+	}
+}
+
+func TestMalformedTimeoutIsInputError(t *testing.T) {
+	for _, timeout := range []string{"30 seconds", "1500", "fast"} {
+		topo := writeTopology(t, "services:\n  gateway:\n    calls:\n      - target: api\n        timeout: \""+timeout+"\"\n")
+		for _, mode := range [][]string{{topo}, {"-stream", topo}} {
+			var stdout, stderr bytes.Buffer
+			if code := run(mode, &stdout, &stderr); code != 2 {
+				t.Errorf("%v with timeout %q: want exit 2, got %d (stdout: %s)", mode, timeout, code, stdout.String())
+				continue
+			}
+			want := fmt.Sprintf("gateway->api invalid timeout %q", timeout)
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("%v: error should contain %s, got: %s", mode, want, stderr.String())
+			}
+		}
+	}
+}
+
+func TestDumpRules(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-dump-rules", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	var entries []struct {
+		ID       string `json:"id"`
+		Severity string `json:"severity"`
+		OptIn    bool   `json:"opt_in"`
+		Params   []struct {
+			Name    string          `json:"name"`
+			Type    string          `json:"type"`
+			Default json.RawMessage `json:"default"`
+		} `json:"params"`
+		Help string `json:"help"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	byID := make(map[string]int)
+	for i, e := range entries {
+		byID[e.ID] = i
+		if e.Help == "" {
+			t.Errorf("%s has no help text", e.ID)
+		}
+	}
+	builtin := map[string]string{
+		"timeout-inversion":    "",
+		"retry-amplification":  `amplification_threshold integer 10`,
+		"retry-without-cb":     "",
+		"non-idempotent-retry": "",
+		"backoff-no-jitter":    "",
+		"no-entry-point":       "",
+	}
+	for id, want := range builtin {
+		i, ok := byID[id]
+		if !ok {
+			t.Errorf("built-in rule %s missing", id)
+			continue
+		}
+		var params []string
+		for _, p := range entries[i].Params {
+			params = append(params, fmt.Sprintf("%s %s %s", p.Name, p.Type, p.Default))
+		}
+		if got := strings.Join(params, "; "); got != want {
+			t.Errorf("%s params = %q, want %q", id, got, want)
+		}
+	}
+	if i := byID["chatty-chain"]; entries[i].Params[1].Type != "duration" || string(entries[i].Params[1].Default) != `"50ms"` {
+		t.Errorf("chatty-chain max mean timeout = %+v", entries[i].Params[1])
+	}
+	for id := range (&Graph{}).extraRules() {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("rule %s missing from the catalog", id)
+		}
+	}
+	for id := range optInRules {
+		if i, ok := byID[id]; !ok || !entries[i].OptIn {
+			t.Errorf("opt-in rule %s missing or not marked opt_in", id)
+		}
+	}
+}
+
+func TestIssuesSuggestCatalogHelp(t *testing.T) {
+	topo := writeTopology(t, sampleTopology)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-output", "issues", topo}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code: want 1, got %d (%s)", code, stderr.String())
+	}
+	var issues []struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(issues) == 0 {
+		t.Fatal("want issues for the sample topology")
+	}
+	for _, is := range issues {
+		rule, _, _ := strings.Cut(is.Title, ":")
+		if help := ruleHelp(rule); help == "" || !strings.Contains(is.Body, "Suggestion: "+help) {
+			t.Errorf("%s: body should suggest the catalog help %q:\n%s", rule, help, is.Body)
+		}
+	}
+}
+
+func TestAliasedSelfCallResolvesToSelfLoop(t *testing.T) {
+	topo, err := parser.ParseTopology(strings.NewReader(`services:
+  orders:
+    aliases: [orders-proxy]
+    calls:
+      - target: orders-proxy
+        timeout: 1s
+        retries: 2
+        circuit_breaker: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, _, err := topologyEdges(topo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 1 || edges[0].Source != "orders" || edges[0].Target != "orders" {
+		t.Fatalf("want the aliased call resolved to orders->orders, got %+v", edges)
+	}
+	var severity string
+	for _, f := range NewGraph(edges).Analyze() {
+		if f.Rule == "self-call" {
+			severity = f.Severity
+		}
+	}
+	if severity != "error" {
+		t.Errorf("want an error self-call finding for the retried aliased self-call, got %q", severity)
+	}
+
+	clash := "services:\n  orders:\n    aliases: [api]\n  api: {}\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{writeTopology(t, clash)}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), `alias "api" is also a service name`) {
+		t.Errorf("want exit 2 for an alias naming a service, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestAliasedReadsFromResolves(t *testing.T) {
+	topo, err := parser.ParseTopology(strings.NewReader(`services:
+  gateway:
+    calls:
+      - target: orders
+        timeout: 3s
+        method: POST
+        circuit_breaker: true
+  orders:
+    aliases: [orders-primary]
+    calls:
+      - target: orders-view
+        timeout: 1s
+        retries: 2
+        circuit_breaker: true
+        backoff_jitter: true
+        reads_from: [orders-primary]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, _, err := topologyEdges(topo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range edges {
+		if e.Target == "orders-view" && !reflect.DeepEqual(e.ReadsFrom, []string{"orders"}) {
+			t.Errorf("want reads_from resolved to [orders], got %v", e.ReadsFrom)
+		}
+	}
+	if !hasRule(NewGraph(edges).Analyze(), "read-after-write-retry") {
+		t.Error("expected read-after-write-retry for a retried read declared through an alias")
+	}
+}
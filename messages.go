@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// messageData is what a custom message template is rendered against, e.g.
+// "{{.Rule}} on {{.Path}}: {{.Message}} (see {{.Vars.runbook}})".
+type messageData struct {
+	Rule     string
+	Severity string
+	Message  string // the built-in message
+	Path     []string
+	Vars     map[string]string // Options.MessageVars
+}
+
+// parseMessageTemplates compiles the per-rule templates and renders each once
+// against sample data, so unknown fields or variables are reported when the
+// options are loaded rather than halfway through a run.
+func parseMessageTemplates(messages, vars map[string]string) (map[string]*template.Template, error) {
+	tmpls := make(map[string]*template.Template, len(messages))
+	for rule, text := range messages {
+		t, err := template.New(rule).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("message template for %s: %w", rule, err)
+		}
+		sample := messageData{Rule: rule, Severity: "warning", Message: "sample", Path: []string{"a", "b"}, Vars: vars}
+		if err := t.Execute(new(strings.Builder), sample); err != nil {
+			return nil, fmt.Errorf("message template for %s: %w", rule, err)
+		}
+		tmpls[rule] = t
+	}
+	return tmpls, nil
+}
+
+// parseEdgeLabel compiles the edge label template (Options.EdgeLabel),
+// rendered against each CallEdge, e.g. "{{.Timeout}} x{{.Retries}}". An
+// empty text yields a nil template, leaving the default label.
+func parseEdgeLabel(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New("edge_label").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("edge label template: %w", err)
+	}
+	if err := t.Execute(new(strings.Builder), CallEdge{Source: "a", Target: "b"}); err != nil {
+		return nil, fmt.Errorf("edge label template: %w", err)
+	}
+	return t, nil
+}
+
+// applyEdgeLabel sets the Label of every edge that does not declare one.
+func applyEdgeLabel(edges []CallEdge, t *template.Template) error {
+	if t == nil {
+		return nil
+	}
+	for i, e := range edges {
+		if e.Label != "" {
+			continue
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, e); err != nil {
+			return fmt.Errorf("edge label template for %s->%s: %w", e.Source, e.Target, err)
+		}
+		edges[i].Label = b.String()
+	}
+	return nil
+}
+
+// applyMessageTemplates replaces the message of every finding whose rule has
+// a template. Findings of other rules keep their built-in message.
+func applyMessageTemplates(findings []Finding, tmpls map[string]*template.Template, vars map[string]string) error {
+	for i, f := range findings {
+		t, ok := tmpls[f.Rule]
+		if !ok {
+			continue
+		}
+		var b strings.Builder
+		data := messageData{Rule: f.Rule, Severity: f.Severity, Message: f.Message, Path: f.Path, Vars: vars}
+		if err := t.Execute(&b, data); err != nil {
+			return fmt.Errorf("message template for %s: %w", f.Rule, err)
+		}
+		findings[i].Message = b.String()
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/rules"
+)
+
+// Options is the effective analysis configuration: everything besides the
+// topology that affects which findings are produced and whether the run
+// fails. It round-trips through JSON (-print-config / -config) so a CI run
+// can record exactly which settings produced its report.
+type Options struct {
+	FailOn      string     `json:"fail_on"`
+	FailOnRules []string   `json:"fail_on_rules"`
+	Enable      []string   `json:"enable"`
+	Limits      Limits     `json:"limits"`
+	Rules       RuleConfig `json:"rules"`
+	// MinConfidence downgrades findings on calls whose confidence is below
+	// it (see Graph.MinConfidence); 0 disables it.
+	MinConfidence float64 `json:"min_confidence"`
+	// Plugins are external checker commands (see rules.ExternalRule), each
+	// split on whitespace into the program and its arguments.
+	Plugins []string `json:"plugins,omitempty"`
+
+	// Messages maps rule IDs to text/template strings that replace the
+	// built-in message (see messageData); MessageVars are exposed to them
+	// as {{.Vars.name}}.
+	Messages    map[string]string `json:"messages,omitempty"`
+	MessageVars map[string]string `json:"message_vars,omitempty"`
+
+	// EdgeLabel is a text/template, rendered against each CallEdge, for the
+	// edge labels drawn by every renderer; empty keeps "timeout/retries".
+	// A call's own label in the topology takes precedence.
+	EdgeLabel string `json:"edge_label,omitempty"`
+
+	// Scopes limits rules to part of the graph, keyed by rule ID: "all"
+	// (the default), "entry-edges" or "leaf-edges" (see rules.Scope).
+	Scopes map[string]string `json:"scopes,omitempty"`
+
+	// ExitCodes maps finding severities (info, warning, error) to exit
+	// codes; the run exits with the highest. When set it replaces FailOn,
+	// while FailOnRules still exit at least 1.
+	ExitCodes map[string]int `json:"exit_codes,omitempty"`
+
+	// Namespaces overrides Rules for calls made by services declaring the
+	// keyed namespace. Zero fields inherit the value from Rules.
+	Namespaces map[string]RuleConfig `json:"namespaces,omitempty"`
+}
+
+// RuleConfig holds per-rule thresholds.
+type RuleConfig struct {
+	AmplificationThreshold    int      `json:"amplification_threshold"`
+	UniformConfigMinCluster   int      `json:"uniform_config_min_cluster"`
+	BidirectionalMaxRatio     float64  `json:"bidirectional_max_ratio"`
+	SlowDownstreamTimeout     Duration `json:"slow_downstream_timeout"`
+	IdempotentMismatchMethods []string `json:"idempotent_mismatch_methods"`
+	BackendRequestCap         int      `json:"backend_request_cap"`
+	GlobalRequestCap          int      `json:"global_request_cap"`
+	ScatterGatherMaxRatio     float64  `json:"scatter_gather_max_ratio"`
+	AggregateLargeTimeout     Duration `json:"aggregate_large_timeout"`
+	EndpointParamPatterns     []string `json:"endpoint_param_patterns"`
+	// ProxyTimeouts are the timeouts of load balancers and proxies in front
+	// of the services, e.g. ["60s"] for nginx and AWS ELB defaults.
+	ProxyTimeouts        []Duration `json:"proxy_timeouts"`
+	ProxyTimeoutEpsilon  Duration   `json:"proxy_timeout_epsilon"`
+	SuspiciousTimeoutMax Duration   `json:"suspicious_timeout_max"`
+	SuspiciousTimeoutMin Duration   `json:"suspicious_timeout_min"`
+	// CBHalfOpenMaxRequests caps half-open probes on calls without
+	// max_concurrency; CBHalfOpenRequireDeclared also reports circuit
+	// breakers that leave cb_half_open_requests unset.
+	CBHalfOpenMaxRequests     int  `json:"cb_half_open_max_requests"`
+	CBHalfOpenRequireDeclared bool `json:"cb_half_open_require_declared"`
+	// TimeoutOverheadFactor is the headroom a timeout needs above a call's
+	// processing_time, as a fraction (0.2 = 20%).
+	TimeoutOverheadFactor float64 `json:"timeout_overhead_factor"`
+	// P99Budget is the p99 latency a path may reach once its retries fire,
+	// for both backoff-latency-inflation and retry-tail-amplification.
+	P99Budget Duration `json:"p99_budget"`
+	// EntryTimeout is the end-to-end deadline set at the entry; 0 means
+	// unknown (entry-timeout-missing then checks the entry calls instead).
+	EntryTimeout Duration `json:"entry_timeout"`
+	// CBCountsPerCallMinRetries is the retry count at which a breaker that
+	// counts per call is reported.
+	CBCountsPerCallMinRetries int `json:"cb_counts_per_call_min_retries"`
+	// ChattyChainMinHops and ChattyChainMaxMeanTimeout select the paths
+	// reported as chatty: at least that many hops, with mean timeout at or
+	// below the limit.
+	ChattyChainMinHops        int      `json:"chatty_chain_min_hops"`
+	ChattyChainMaxMeanTimeout Duration `json:"chatty_chain_max_mean_timeout"`
+	// ChokepointMinCallers and ChokepointMinCallees are the fan-in and
+	// fan-out a service must both reach to be reported as a chokepoint.
+	ChokepointMinCallers int `json:"chokepoint_min_callers"`
+	ChokepointMinCallees int `json:"chokepoint_min_callees"`
+	// BudgetMaxShare is the largest fraction of the budget one hop of the
+	// critical path may take (0.7 = 70%).
+	BudgetMaxShare float64 `json:"budget_max_share"`
+}
+
+// proxyTimeouts converts ProxyTimeouts for the rules package; nil selects
+// the rule's default.
+func (c RuleConfig) proxyTimeouts() []time.Duration {
+	if c.ProxyTimeouts == nil {
+		return nil
+	}
+	out := make([]time.Duration, len(c.ProxyTimeouts))
+	for i, d := range c.ProxyTimeouts {
+		out[i] = time.Duration(d)
+	}
+	return out
+}
+
+// failPolicy returns the policy deciding the run's exit code.
+func (o Options) failPolicy() failPolicy {
+	return failPolicy{MinSeverity: o.FailOn, Rules: toSet(o.FailOnRules), ExitCodes: o.ExitCodes}
+}
+
+// inherit returns c with its zero fields filled in from base.
+func (c RuleConfig) inherit(base RuleConfig) RuleConfig {
+	out := reflect.ValueOf(&base).Elem()
+	in := reflect.ValueOf(c)
+	for i := 0; i < in.NumField(); i++ {
+		if f := in.Field(i); !f.IsZero() {
+			out.Field(i).Set(f)
+		}
+	}
+	return base
+}
+
+// namespaceConfigs resolves Namespaces against Rules.
+func (o Options) namespaceConfigs() map[string]RuleConfig {
+	if len(o.Namespaces) == 0 {
+		return nil
+	}
+	out := make(map[string]RuleConfig, len(o.Namespaces))
+	for ns, c := range o.Namespaces {
+		out[ns] = c.inherit(o.Rules)
+	}
+	return out
+}
+
+// DefaultOptions returns the settings used when no -config is given.
+func DefaultOptions() Options {
+	return Options{
+		FailOn:      "info",
+		FailOnRules: []string{},
+		Enable:      []string{},
+		Limits:      DefaultLimits,
+		Rules: RuleConfig{
+			AmplificationThreshold:    10,
+			UniformConfigMinCluster:   5,
+			BidirectionalMaxRatio:     10,
+			SlowDownstreamTimeout:     Duration(30 * time.Second),
+			IdempotentMismatchMethods: []string{"POST", "PATCH"},
+			BackendRequestCap:         50,
+			GlobalRequestCap:          200,
+			ScatterGatherMaxRatio:     10,
+			AggregateLargeTimeout:     Duration(5 * time.Second),
+			EndpointParamPatterns:     []string{},
+			ProxyTimeouts:             []Duration{Duration(60 * time.Second)},
+			ProxyTimeoutEpsilon:       Duration(time.Second),
+			SuspiciousTimeoutMax:      Duration(5 * time.Minute),
+			SuspiciousTimeoutMin:      Duration(10 * time.Millisecond),
+			CBHalfOpenMaxRequests:     10,
+			TimeoutOverheadFactor:     0.2,
+			P99Budget:                 Duration(time.Second),
+			CBCountsPerCallMinRetries: 2,
+			ChattyChainMinHops:        6,
+			ChattyChainMaxMeanTimeout: Duration(50 * time.Millisecond),
+			ChokepointMinCallers:      4,
+			ChokepointMinCallees:      4,
+			BudgetMaxShare:            0.7,
+		},
+	}
+}
+
+// LoadOptions decodes a JSON options document on top of base, so keys
+// missing from the document keep their base value. Unknown keys are
+// rejected to catch typos.
+func LoadOptions(r io.Reader, base Options) (Options, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&base); err != nil {
+		return Options{}, fmt.Errorf("options: %w", err)
+	}
+	return base, base.Validate()
+}
+
+// Validate reports settings that cannot be applied.
+func (o Options) Validate() error {
+	if err := validFailOn(o.FailOn); err != nil {
+		return err
+	}
+	if err := validExitCodes(o.ExitCodes); err != nil {
+		return err
+	}
+	for _, id := range o.Enable {
+		if _, ok := optInRules[id]; !ok {
+			return fmt.Errorf("unknown opt-in rule %q", id)
+		}
+	}
+	// Plugins report rule IDs of their own, which cannot be known here.
+	for _, id := range o.FailOnRules {
+		if _, ok := catalogRule(id); !ok && len(o.Plugins) == 0 {
+			return fmt.Errorf("fail_on_rules: unknown rule %q", id)
+		}
+	}
+	for _, p := range o.Rules.EndpointParamPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("endpoint_param_patterns: %w", err)
+		}
+	}
+	for ns, c := range o.Namespaces {
+		if ns == "" {
+			return fmt.Errorf("namespaces: empty namespace name")
+		}
+		for _, p := range c.EndpointParamPatterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("namespaces[%q].endpoint_param_patterns: %w", ns, err)
+			}
+		}
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return fmt.Errorf("min_confidence %v must be between 0 and 1", o.MinConfidence)
+	}
+	for _, p := range o.Plugins {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty plugin command")
+		}
+	}
+	if _, err := o.ruleScopes(); err != nil {
+		return err
+	}
+	if _, err := parseEdgeLabel(o.EdgeLabel); err != nil {
+		return err
+	}
+	_, err := parseMessageTemplates(o.Messages, o.MessageVars)
+	return err
+}
+
+// ruleScopes parses Scopes. Only rules from the rules package can be
+// scoped; the built-in checks in analyzer.go always see the whole graph.
+func (o Options) ruleScopes() (map[string]rules.Scope, error) {
+	scoped := (&Graph{}).extraRules()
+	scopes := make(map[string]rules.Scope, len(o.Scopes))
+	for id, s := range o.Scopes {
+		if _, ok := scoped[id]; !ok {
+			if _, ok := optInRules[id]; !ok {
+				return nil, fmt.Errorf("scope for %q: not a scopeable rule", id)
+			}
+		}
+		scope, err := rules.ParseScope(s)
+		if err != nil {
+			return nil, fmt.Errorf("scope for %q: %w", id, err)
+		}
+		scopes[id] = scope
+	}
+	return scopes, nil
+}
+
+// WriteJSON writes o as indented JSON.
+func (o Options) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(o)
+}
+
+// sortedKeys returns the rule IDs in the list in sorted order.
+func (l ruleList) sortedKeys() []string {
+	ids := make([]string, 0, len(l))
+	for id := range l {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// commandList collects repeated -plugin commands.
+type commandList []string
+
+func (c *commandList) String() string { return strings.Join(*c, "; ") }
+
+func (c *commandList) Set(v string) error {
+	*c = append(*c, v)
+	return nil
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// Duration is a time.Duration that encodes as a Go duration string ("30s")
+// in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
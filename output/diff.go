@@ -0,0 +1,38 @@
+package output
+
+// FindingsDiff is the difference between two analysis runs.
+type FindingsDiff struct {
+	New       []Violation // only in the new run
+	Resolved  []Violation // only in the old run
+	Unchanged []Violation // in both, as reported by the new run
+}
+
+// DiffFindings matches the violations of two runs by Fingerprint, so a
+// finding whose message wording changed still counts as unchanged. Repeated
+// fingerprints are matched one for one. Each list keeps its run's order.
+func DiffFindings(old, new []Violation) FindingsDiff {
+	remaining := make(map[string]int)
+	for _, v := range old {
+		remaining[Fingerprint(v)]++
+	}
+	var d FindingsDiff
+	for _, v := range new {
+		fp := Fingerprint(v)
+		if remaining[fp] > 0 {
+			remaining[fp]--
+			d.Unchanged = append(d.Unchanged, v)
+		} else {
+			d.New = append(d.New, v)
+		}
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		if fp := Fingerprint(old[i]); remaining[fp] > 0 {
+			remaining[fp]--
+			d.Resolved = append(d.Resolved, old[i])
+		}
+	}
+	for i, j := 0, len(d.Resolved)-1; i < j; i, j = i+1, j-1 {
+		d.Resolved[i], d.Resolved[j] = d.Resolved[j], d.Resolved[i]
+	}
+	return d
+}
@@ -0,0 +1,16 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint returns a short, stable identifier for a violation derived
+// from its rule and path. It does not depend on the message wording or on
+// the order findings were discovered, so the same finding keeps the same
+// fingerprint across runs and releases.
+func Fingerprint(v Violation) string {
+	sum := sha256.Sum256([]byte(v.Rule + "\x00" + strings.Join(v.Path, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderGitHubActions writes one GitHub Actions workflow command per
+// violation (e.g. "::error file=topology.yaml,title=timeout-inversion::..."),
+// which the Actions runner turns into inline annotations on the pull
+// request. Severity picks the command: "error" → ::error, "warning" →
+// ::warning, anything else → ::notice. File and Line are included when set,
+// and the message starts with "[new]" or "[pre-existing]" when Age is.
+func RenderGitHubActions(violations []Violation, w io.Writer) error {
+	var b strings.Builder
+	for _, v := range violations {
+		props := []string{}
+		if v.File != "" {
+			props = append(props, "file="+ghaEscapeProperty(v.File))
+			if v.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", v.Line))
+			}
+		}
+		props = append(props, "title="+ghaEscapeProperty(v.Rule))
+		msg := v.Message
+		if v.Age != "" {
+			msg = "[" + v.Age + "] " + msg
+		}
+		if len(v.Path) > 0 {
+			msg += " (path: " + strings.Join(v.Path, " → ") + ")"
+		}
+		fmt.Fprintf(&b, "::%s %s::%s\n", ghaCommand(v.Severity), strings.Join(props, ","), ghaEscapeData(msg))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func ghaCommand(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// ghaEscapeData escapes a workflow command message.
+func ghaEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// ghaEscapeProperty escapes a workflow command property value, which
+// additionally may not contain the ':' and ',' separators.
+func ghaEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Issue is one tracker-neutral ticket for a violation. Fingerprint is stable
+// across runs, so a script can find the issue it opened before and update
+// it instead of filing a duplicate.
+type Issue struct {
+	Title       string   `json:"title"`
+	Body        string   `json:"body"`
+	Labels      []string `json:"labels"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+// issueSuggestion returns the fix for v, or a generic prompt when its rule
+// has no Help.
+func issueSuggestion(v Violation) string {
+	if v.Help != "" {
+		return v.Help
+	}
+	return "Review the resilience settings of the calls on this path."
+}
+
+// RenderIssues writes violations as a JSON array of Issue objects, one per
+// violation, for bulk issue creation in any tracker. The title names the
+// rule and the start of the path. The body holds the message, the full
+// path and the violation's Help as a suggested fix. Labels are "cascadeguard", "rule:<id>" and
+// "severity:<level>", plus "age:<age>" when compared with a baseline.
+func RenderIssues(violations []Violation, w io.Writer) error {
+	issues := make([]Issue, 0, len(violations))
+	for _, v := range violations {
+		title := v.Rule
+		if len(v.Path) > 0 {
+			title = fmt.Sprintf("%s: %s", v.Rule, strings.Join(v.Path, " → "))
+		}
+		var body strings.Builder
+		fmt.Fprintf(&body, "%s\n\n", v.Message)
+		if len(v.Path) > 0 {
+			fmt.Fprintf(&body, "Path: %s\n", strings.Join(v.Path, " → "))
+		}
+		if v.File != "" {
+			fmt.Fprintf(&body, "File: %s\n", v.File)
+		}
+		fmt.Fprintf(&body, "Severity: %s\n", v.Severity)
+		labels := []string{"cascadeguard", "rule:" + v.Rule, "severity:" + v.Severity}
+		if v.Age != "" {
+			fmt.Fprintf(&body, "Age: %s\n", v.Age)
+			labels = append(labels, "age:"+v.Age)
+		}
+		fmt.Fprintf(&body, "\nSuggestion: %s\n", issueSuggestion(v))
+		issues = append(issues, Issue{
+			Title:       title,
+			Body:        body.String(),
+			Labels:      labels,
+			Fingerprint: Fingerprint(v),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
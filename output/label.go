@@ -0,0 +1,22 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EdgeLabel returns the label every renderer draws on an edge: e.Label when
+// set, otherwise "timeout/retries" (e.g. "3s/2").
+func EdgeLabel(e Edge) string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return fmt.Sprintf("%s/%d", e.Timeout, e.Retries)
+}
+
+// MermaidEdge formats e as a Mermaid flowchart edge line, labelled with
+// EdgeLabel. Double quotes in the label are escaped as #quot;.
+func MermaidEdge(e Edge) string {
+	label := strings.ReplaceAll(EdgeLabel(e), `"`, "#quot;")
+	return fmt.Sprintf("  %s -->|\"%s\"| %s", e.Source, label, e.Target)
+}
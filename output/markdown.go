@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderMarkdown writes the violations as a Markdown report suitable for a
+// pull-request comment: a heading with the finding count and a table with
+// one row per violation, with an Age column when compared with a baseline.
+// Pipe characters in messages are escaped so they do not break the table.
+func RenderMarkdown(violations []Violation, w io.Writer) error {
+	if len(violations) == 0 {
+		_, err := fmt.Fprint(w, "## CascadeGuard\n\nNo issues found in service topology.\n")
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## CascadeGuard\n\nFound %d issue(s):\n\n", len(violations))
+	aged := hasAge(violations)
+	if aged {
+		b.WriteString("| Severity | Age | Rule | Path | Message |\n")
+		b.WriteString("|----------|-----|------|------|---------|\n")
+	} else {
+		b.WriteString("| Severity | Rule | Path | Message |\n")
+		b.WriteString("|----------|------|------|---------|\n")
+	}
+	for _, v := range violations {
+		severity := v.Severity
+		if aged {
+			severity += " | " + v.Age
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n",
+			severity, v.Rule, mdEscape(strings.Join(v.Path, " → ")), mdEscape(v.Message))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hasAge reports whether any violation was compared with a baseline.
+func hasAge(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Age != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func mdEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
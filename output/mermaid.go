@@ -11,6 +11,7 @@ type Edge struct {
 	Target  string
 	Timeout string
 	Retries int
+	Label   string // overrides the default label; see EdgeLabel
 }
 
 // CallGraph represents the service call graph.
@@ -24,10 +25,20 @@ type Violation struct {
 	Severity string
 	Message  string
 	Path     []string
+	File     string // file the finding relates to, if known
+	Line     int    // 1-based line in File; 0 if unknown
+	Age      string // AgeNew or AgePreExisting against a baseline; empty without one
+	Help     string // how to fix the rule's findings, from the rule catalog; empty if unknown
 }
 
+// Values of Violation.Age.
+const (
+	AgeNew         = "new"
+	AgePreExisting = "pre-existing"
+)
+
 // RenderMermaid writes a Mermaid flowchart to w.
-// Edge labels use the format "timeout/retries".
+// Edges are labelled with EdgeLabel.
 // Edges involved in violations are styled red via linkStyle directives.
 // The output ends without extra blank lines.
 func RenderMermaid(graph CallGraph, violations []Violation, w io.Writer) error {
@@ -45,7 +56,7 @@ func RenderMermaid(graph CallGraph, violations []Violation, w io.Writer) error {
 
 	var redIndices []int
 	for i, e := range graph.Edges {
-		lines = append(lines, fmt.Sprintf("  %s -->|\"%s/%d\"| %s", e.Source, e.Timeout, e.Retries, e.Target))
+		lines = append(lines, MermaidEdge(e))
 		if violationEdges[edgeKey{e.Source, e.Target}] {
 			redIndices = append(redIndices, i)
 		}
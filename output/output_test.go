@@ -3,8 +3,13 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/graph"
 )
 
 func TestMermaidStartsWithGraphLR(t *testing.T) {
@@ -81,6 +86,36 @@ func TestMermaidEdgeLabelFormat(t *testing.T) {
 	}
 }
 
+func TestRenderersShareEdgeLabel(t *testing.T) {
+	for _, e := range []Edge{
+		{Source: "gateway", Target: "api", Timeout: "3s", Retries: 2},
+		{Source: "gateway", Target: "api", Timeout: "3s", Retries: 2, Label: "p99 120ms"},
+	} {
+		graph := CallGraph{Edges: []Edge{e}}
+		var mermaid, svg bytes.Buffer
+		if err := RenderMermaid(graph, nil, &mermaid); err != nil {
+			t.Fatal(err)
+		}
+		if err := RenderSVG(graph, nil, &svg); err != nil {
+			t.Fatal(err)
+		}
+		label := EdgeLabel(e)
+		if !strings.Contains(mermaid.String(), `|"`+label+`"|`) {
+			t.Errorf("mermaid: want label %q, got:\n%s", label, mermaid.String())
+		}
+		if !strings.Contains(svg.String(), ">"+label+"</text>") {
+			t.Errorf("svg: want label %q, got:\n%s", label, svg.String())
+		}
+	}
+}
+
+func TestMermaidEdgeEscapesQuotes(t *testing.T) {
+	got := MermaidEdge(Edge{Source: "a", Target: "b", Label: `say "hi"`})
+	if got != `  a -->|"say #quot;hi#quot;"| b` {
+		t.Errorf("unexpected edge line: %s", got)
+	}
+}
+
 func TestMermaidNoExtraTrailingBlankLines(t *testing.T) {
 	graph := CallGraph{
 		Edges: []Edge{
@@ -226,3 +261,296 @@ func TestSARIFVersion(t *testing.T) {
 		t.Fatalf("expected version '2.1.0', got: %v", m["version"])
 	}
 }
+
+func TestMarkdownTable(t *testing.T) {
+	violations := []Violation{
+		{Rule: "timeout-inversion", Severity: "error", Message: "a|b", Path: []string{"A", "B", "C"}},
+	}
+	var buf bytes.Buffer
+	if err := RenderMarkdown(violations, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "| error | `timeout-inversion` | A → B → C | a\\|b |") {
+		t.Fatalf("unexpected markdown row:\n%s", out)
+	}
+}
+
+func TestMarkdownNoViolations(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderMarkdown(nil, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No issues found") {
+		t.Fatalf("expected clean message, got:\n%s", buf.String())
+	}
+}
+
+func TestFingerprintStableAndDistinct(t *testing.T) {
+	a := Violation{Rule: "retry-amplification", Severity: "error", Message: "x", Path: []string{"A", "B"}}
+	reworded := a
+	reworded.Message = "different wording"
+	if Fingerprint(a) != Fingerprint(reworded) {
+		t.Error("fingerprint should not depend on the message")
+	}
+	other := a
+	other.Path = []string{"A", "C"}
+	if Fingerprint(a) == Fingerprint(other) {
+		t.Error("different paths should have different fingerprints")
+	}
+	joined := Violation{Rule: "retry-amplification", Path: []string{"AB"}}
+	if Fingerprint(a) == Fingerprint(joined) {
+		t.Error("path segments must not collide when concatenated")
+	}
+	if len(Fingerprint(a)) != 12 {
+		t.Errorf("want 12 hex chars, got %q", Fingerprint(a))
+	}
+}
+
+func TestGitHubActionsCommandFormat(t *testing.T) {
+	violations := []Violation{
+		{Rule: "timeout-inversion", Severity: "error", Message: "A->B 1s but B->C 2s", Path: []string{"A", "B", "C"}, File: "topology.yaml", Line: 12},
+		{Rule: "retry-without-cb", Severity: "warning", Message: "100% retried", File: "topology.yaml"},
+		{Rule: "uniform-config", Severity: "info", Message: "line one\nline two"},
+	}
+	var buf bytes.Buffer
+	if err := RenderGitHubActions(violations, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"::error file=topology.yaml,line=12,title=timeout-inversion::A->B 1s but B->C 2s (path: A → B → C)",
+		"::warning file=topology.yaml,title=retry-without-cb::100%25 retried",
+		"::notice title=uniform-config::line one%0Aline two",
+	}
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("want %d commands, got %d:\n%s", len(want), len(got), buf.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("command %d:\nwant %s\ngot  %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestGitHubActionsEscapesProperties(t *testing.T) {
+	var buf bytes.Buffer
+	v := Violation{Rule: "r", Severity: "error", Message: "m", File: "dir,1/a:b.yaml"}
+	if err := RenderGitHubActions([]Violation{v}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "::error file=dir%2C1/a%3Ab.yaml,") {
+		t.Errorf("property not escaped: %s", buf.String())
+	}
+}
+
+func TestSVGRootElement(t *testing.T) {
+	graph := CallGraph{Edges: []Edge{
+		{Source: "A", Target: "B", Timeout: "3s", Retries: 3},
+		{Source: "B", Target: "A", Timeout: "1s", Retries: 0}, // cycle
+		{Source: "B", Target: "<db>", Timeout: "5s", Retries: 2},
+	}}
+	var buf bytes.Buffer
+	if err := RenderSVG(graph, nil, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("not well-formed XML: %v\n%s", err, buf.String())
+	}
+	if root.XMLName.Local != "svg" || root.XMLName.Space != "http://www.w3.org/2000/svg" {
+		t.Errorf("want svg root element, got %+v", root.XMLName)
+	}
+	if !strings.Contains(buf.String(), "&lt;db&gt;") {
+		t.Errorf("service names should be escaped:\n%s", buf.String())
+	}
+}
+
+func TestSVGViolationEdgeStrokeRed(t *testing.T) {
+	graph := CallGraph{Edges: []Edge{
+		{Source: "A", Target: "B", Timeout: "3s", Retries: 3},
+		{Source: "B", Target: "C", Timeout: "5s", Retries: 2},
+	}}
+	violations := []Violation{{Rule: "timeout-inversion", Severity: "error", Path: []string{"A", "B"}}}
+	var buf bytes.Buffer
+	if err := RenderSVG(graph, violations, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(l, "<line ") {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) != 2 {
+		t.Fatalf("want 2 edges, got %d:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], `stroke="red"`) {
+		t.Errorf("violation edge A->B should be red: %s", lines[0])
+	}
+	if strings.Contains(lines[1], `stroke="red"`) {
+		t.Errorf("clean edge B->C should not be red: %s", lines[1])
+	}
+}
+
+func TestRenderIssues(t *testing.T) {
+	violations := []Violation{
+		{Rule: "timeout-inversion", Severity: "error", Message: "A->B 1s but B->C 2s", Path: []string{"A", "B", "C"}, File: "topology.yaml", Help: "Lower the downstream timeout."},
+		{Rule: "org-rule", Severity: "warning", Message: "custom", Path: []string{"D"}},
+	}
+	var buf bytes.Buffer
+	if err := RenderIssues(violations, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var issues []Issue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("issues output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(issues) != len(violations) {
+		t.Fatalf("want %d issues, got %d", len(violations), len(issues))
+	}
+	for i, is := range issues {
+		v := violations[i]
+		if !strings.HasPrefix(is.Title, v.Rule) {
+			t.Errorf("issue %d title %q should start with rule %s", i, is.Title, v.Rule)
+		}
+		if path := strings.Join(v.Path, " → "); !strings.Contains(is.Body, "Path: "+path) {
+			t.Errorf("issue %d body should contain path %s:\n%s", i, path, is.Body)
+		}
+		if !strings.Contains(is.Body, "Suggestion: ") {
+			t.Errorf("issue %d body has no suggestion:\n%s", i, is.Body)
+		}
+		want := []string{"cascadeguard", "rule:" + v.Rule, "severity:" + v.Severity}
+		if !reflect.DeepEqual(is.Labels, want) {
+			t.Errorf("issue %d labels = %v, want %v", i, is.Labels, want)
+		}
+		if is.Fingerprint != Fingerprint(v) {
+			t.Errorf("issue %d fingerprint = %q, want %q", i, is.Fingerprint, Fingerprint(v))
+		}
+	}
+	if !strings.Contains(issues[0].Body, "Suggestion: Lower the downstream timeout.") {
+		t.Errorf("a violation with Help should suggest it:\n%s", issues[0].Body)
+	}
+	if !strings.Contains(issues[1].Body, "Suggestion: Review the resilience settings") {
+		t.Errorf("a violation without Help should get the generic suggestion:\n%s", issues[1].Body)
+	}
+}
+
+func TestRenderIssuesEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderIssues(nil, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("no violations should render an empty array, got %q", buf.String())
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	unchanged := Violation{Rule: "timeout-inversion", Severity: "error", Message: "A->B 1s but B->C 2s", Path: []string{"A", "B", "C"}}
+	resolved := Violation{Rule: "retry-without-cb", Severity: "warning", Message: "A->B retries", Path: []string{"A", "B"}}
+	added := Violation{Rule: "backoff-no-jitter", Severity: "warning", Message: "B->C no jitter", Path: []string{"B", "C"}}
+
+	reworded := unchanged
+	reworded.Message = "new wording"
+	d := DiffFindings([]Violation{unchanged, resolved}, []Violation{reworded, added})
+	if len(d.New) != 1 || d.New[0].Rule != "backoff-no-jitter" {
+		t.Errorf("New = %+v, want the backoff-no-jitter finding", d.New)
+	}
+	if len(d.Resolved) != 1 || d.Resolved[0].Rule != "retry-without-cb" {
+		t.Errorf("Resolved = %+v, want the retry-without-cb finding", d.Resolved)
+	}
+	if len(d.Unchanged) != 1 || d.Unchanged[0].Message != "new wording" {
+		t.Errorf("Unchanged = %+v, want the reworded timeout-inversion from the new run", d.Unchanged)
+	}
+}
+
+func TestDiffFindingsThroughSARIF(t *testing.T) {
+	old := []Violation{
+		{Rule: "timeout-inversion", Severity: "error", Message: "m", Path: []string{"A", "B", "C"}},
+		{Rule: "uniform-config", Severity: "info", Message: "m"},
+	}
+	var buf bytes.Buffer
+	if err := RenderSARIF(old, &buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSARIF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, old) {
+		t.Fatalf("SARIF round trip:\nwant %+v\ngot  %+v", old, read)
+	}
+	if d := DiffFindings(read, old); len(d.New) != 0 || len(d.Resolved) != 0 || len(d.Unchanged) != 2 {
+		t.Errorf("identical runs should be all unchanged: %+v", d)
+	}
+}
+
+func TestRenderersShowAge(t *testing.T) {
+	vs := []Violation{
+		{Rule: "timeout-inversion", Severity: "error", Message: "m", Path: []string{"A", "B"}, Age: AgeNew},
+		{Rule: "retry-without-cb", Severity: "warning", Message: "m", Age: AgePreExisting},
+	}
+	var md bytes.Buffer
+	if err := RenderMarkdown(vs, &md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| Severity | Age |", "| error | new |", "| warning | pre-existing |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+	var gha bytes.Buffer
+	if err := RenderGitHubActions(vs, &gha); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(gha.String(), "::[new] m") {
+		t.Errorf("github annotation should start with the age:\n%s", gha.String())
+	}
+	var sarif bytes.Buffer
+	if err := RenderSARIF(vs, &sarif); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sarif.String(), `"baselineState": "unchanged"`) {
+		t.Errorf("pre-existing finding should be baselineState unchanged:\n%s", sarif.String())
+	}
+	read, err := ReadSARIF(&sarif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read[0].Age != AgeNew || read[1].Age != AgePreExisting {
+		t.Errorf("ages lost in the SARIF round trip: %+v", read)
+	}
+}
+
+func TestRenderWaterfall(t *testing.T) {
+	path := []graph.Edge{
+		{From: "gateway", To: "orders", Timeout: 2 * time.Second, MaxRetries: 1},
+		{From: "orders", To: "payments", Timeout: 500 * time.Millisecond, MaxRetries: 2},
+		{From: "payments", To: "bank", Timeout: time.Second},
+	}
+	var buf bytes.Buffer
+	if err := RenderWaterfall(path, 5*time.Second, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("want a title, a header and 3 hops, got:\n%s", buf.String())
+	}
+	want := []struct{ worst, cumulative, remaining string }{
+		{"4s", "4s", "1s"},
+		{"1.5s", "5.5s", "-500ms"},
+		{"1s", "6.5s", "-1.5s"},
+	}
+	for i, w := range want {
+		fields := strings.Fields(lines[i+2])
+		if got := [3]string{fields[6], fields[7], fields[8]}; got != [3]string{w.worst, w.cumulative, w.remaining} {
+			t.Errorf("hop %d: worst/cumulative/remaining = %v, want %v", i+1, got, w)
+		}
+	}
+	if strings.Contains(lines[2], "exhausted") || !strings.HasSuffix(lines[3], "exhausted") {
+		t.Errorf("want the budget exhausted from hop 2 on:\n%s", buf.String())
+	}
+}
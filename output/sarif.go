@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 )
 
@@ -27,11 +28,25 @@ type sarifDriver struct {
 }
 
 type sarifResult struct {
-	RuleID  string       `json:"ruleId"`
-	Level   string       `json:"level"`
-	Message sarifMessage `json:"message"`
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	BaselineState       string            `json:"baselineState,omitempty"`
+	Properties          *sarifProperties  `json:"properties,omitempty"`
 }
 
+// sarifBaselineStates maps Violation.Age to SARIF baselineState.
+var sarifBaselineStates = map[string]string{AgeNew: "new", AgePreExisting: "unchanged"}
+
+// sarifProperties carries the finding's path so ReadSARIF can recover it.
+type sarifProperties struct {
+	Path []string `json:"path,omitempty"`
+}
+
+// sarifFingerprintKey names CascadeGuard's entry in partialFingerprints.
+const sarifFingerprintKey = "cascadeguard/v1"
+
 type sarifMessage struct {
 	Text string `json:"text"`
 }
@@ -39,16 +54,24 @@ type sarifMessage struct {
 // RenderSARIF writes a SARIF v2.1.0 JSON document to w.
 // Each Violation is mapped to a SARIF result. Severity is mapped to SARIF
 // level: "error" → "error", "warning" → "warning", anything else → "note".
-// The tool driver name is "CascadeGuard".
+// The tool driver name is "CascadeGuard". Each result carries its
+// Fingerprint in partialFingerprints and its path in properties. Age maps
+// to baselineState: "new" stays "new", "pre-existing" becomes "unchanged".
 func RenderSARIF(violations []Violation, w io.Writer) error {
 	results := make([]sarifResult, 0, len(violations))
 	for _, v := range violations {
 		level := mapLevel(v.Severity)
-		results = append(results, sarifResult{
-			RuleID:  v.Rule,
-			Level:   level,
-			Message: sarifMessage{Text: v.Message},
-		})
+		r := sarifResult{
+			RuleID:              v.Rule,
+			Level:               level,
+			Message:             sarifMessage{Text: v.Message},
+			PartialFingerprints: map[string]string{sarifFingerprintKey: Fingerprint(v)},
+			BaselineState:       sarifBaselineStates[v.Age],
+		}
+		if len(v.Path) > 0 {
+			r.Properties = &sarifProperties{Path: v.Path}
+		}
+		results = append(results, r)
 	}
 
 	doc := sarifDocument{
@@ -69,6 +92,35 @@ func RenderSARIF(violations []Violation, w io.Writer) error {
 	return enc.Encode(doc)
 }
 
+// ReadSARIF reads back the violations of a document written by RenderSARIF,
+// e.g. a previous run's report. Levels map back to severities ("note" →
+// "info").
+func ReadSARIF(r io.Reader) ([]Violation, error) {
+	var doc sarifDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("reading SARIF: %w", err)
+	}
+	var vs []Violation
+	for _, run := range doc.Runs {
+		for _, res := range run.Results {
+			v := Violation{Rule: res.RuleID, Severity: res.Level, Message: res.Message.Text}
+			if v.Severity == "note" {
+				v.Severity = "info"
+			}
+			if res.Properties != nil {
+				v.Path = res.Properties.Path
+			}
+			for age, state := range sarifBaselineStates {
+				if res.BaselineState == state {
+					v.Age = age
+				}
+			}
+			vs = append(vs, v)
+		}
+	}
+	return vs, nil
+}
+
 func mapLevel(severity string) string {
 	switch severity {
 	case "error":
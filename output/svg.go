@@ -0,0 +1,140 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SVG layout dimensions, in pixels.
+const (
+	svgNodeWidth  = 140
+	svgNodeHeight = 40
+	svgColumnGap  = 90
+	svgRowGap     = 30
+	svgMargin     = 20
+)
+
+// RenderSVG writes the call graph as a standalone SVG image, so it can be
+// shared without Graphviz or a Mermaid renderer. Services are laid out left
+// to right in columns by call depth (longest path from an entry point,
+// ignoring edges that close a cycle). Edges are labelled with EdgeLabel, as
+// in RenderMermaid, and edges involved in violations are drawn with
+// stroke="red".
+func RenderSVG(graph CallGraph, violations []Violation, w io.Writer) error {
+	type edgeKey struct{ src, tgt string }
+	violationEdges := make(map[edgeKey]bool)
+	for _, v := range violations {
+		for i := 0; i+1 < len(v.Path); i++ {
+			violationEdges[edgeKey{v.Path[i], v.Path[i+1]}] = true
+		}
+	}
+
+	ranks, order := svgRanks(graph)
+	row := make(map[string]int)
+	rowsInRank := make(map[int]int)
+	maxRank, maxRows := 0, 0
+	for _, n := range order {
+		r := ranks[n]
+		row[n] = rowsInRank[r]
+		rowsInRank[r]++
+		maxRank = max(maxRank, r)
+		maxRows = max(maxRows, rowsInRank[r])
+	}
+	pos := func(n string) (x, y int) {
+		return svgMargin + ranks[n]*(svgNodeWidth+svgColumnGap), svgMargin + row[n]*(svgNodeHeight+svgRowGap)
+	}
+	width := 2*svgMargin + (maxRank+1)*svgNodeWidth + maxRank*svgColumnGap
+	height := 2*svgMargin + maxRows*svgNodeHeight + max(maxRows-1, 0)*svgRowGap
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	b.WriteString(`  <defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="context-stroke"/></marker></defs>` + "\n")
+	for _, e := range graph.Edges {
+		sx, sy := pos(e.Source)
+		tx, ty := pos(e.Target)
+		x1, y1 := sx+svgNodeWidth, sy+svgNodeHeight/2
+		x2, y2 := tx, ty+svgNodeHeight/2
+		stroke := "#555"
+		if violationEdges[edgeKey{e.Source, e.Target}] {
+			stroke = "red"
+		}
+		fmt.Fprintf(&b, `  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="1.5" marker-end="url(#arrow)"/>`+"\n",
+			x1, y1, x2, y2, stroke)
+		fmt.Fprintf(&b, `  <text x="%d" y="%d" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			(x1+x2)/2, (y1+y2)/2-4, stroke, svgEscape(EdgeLabel(e)))
+	}
+	for _, n := range order {
+		x, y := pos(n)
+		fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#f4f4f4" stroke="#333"/>`+"\n",
+			x, y, svgNodeWidth, svgNodeHeight)
+		fmt.Fprintf(&b, `  <text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			x+svgNodeWidth/2, y+svgNodeHeight/2, svgEscape(n))
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// svgRanks assigns each service its column: the longest path to it from a
+// service nothing calls, with cycle-closing edges ignored. order lists the
+// services in order of first appearance in graph.Edges.
+func svgRanks(graph CallGraph) (ranks map[string]int, order []string) {
+	adj := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, e := range graph.Edges {
+		adj[e.Source] = append(adj[e.Source], e.Target)
+		for _, n := range []string{e.Source, e.Target} {
+			if !seen[n] {
+				seen[n] = true
+				order = append(order, n)
+			}
+		}
+	}
+
+	// Find the edges that close a cycle so the layering works on a DAG.
+	type edgeKey struct{ src, tgt string }
+	back := make(map[edgeKey]bool)
+	state := make(map[string]int) // 0 unvisited, 1 on stack, 2 done
+	var visit func(n string)
+	visit = func(n string) {
+		state[n] = 1
+		for _, t := range adj[n] {
+			switch state[t] {
+			case 0:
+				visit(t)
+			case 1:
+				back[edgeKey{n, t}] = true
+			}
+		}
+		state[n] = 2
+	}
+	for _, n := range order {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+
+	ranks = make(map[string]int)
+	for changed, i := true, 0; changed && i < len(order); i++ {
+		changed = false
+		for _, e := range graph.Edges {
+			if back[edgeKey{e.Source, e.Target}] {
+				continue
+			}
+			if r := ranks[e.Source] + 1; r > ranks[e.Target] {
+				ranks[e.Target] = r
+				changed = true
+			}
+		}
+	}
+	return ranks, order
+}
+
+func svgEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/graph"
+)
+
+// waterfallWidth is the width in characters of the bars in RenderWaterfall.
+const waterfallWidth = 40
+
+// RenderWaterfall writes one path as a latency waterfall: a row per hop with
+// its timeout, retries, worst-case time (timeout × attempts, as in
+// graph.WorstCaseLatency), the cumulative worst case up to and including
+// the hop, and what is left of entryBudget after it. A bar shows where each
+// hop's time falls, scaled to the budget or the path's total, whichever is
+// longer. Hops that end past the budget are marked "exhausted". With a zero
+// entryBudget the remaining column is left blank.
+func RenderWaterfall(path []graph.Edge, entryBudget time.Duration, w io.Writer) error {
+	if len(path) == 0 {
+		_, err := fmt.Fprintln(w, "Empty path.")
+		return err
+	}
+	total := graph.WorstCaseLatency(path)
+	scale := total
+	if entryBudget > scale {
+		scale = entryBudget
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Waterfall %s (worst case %v", waterfallPath(path), total)
+	if entryBudget > 0 {
+		fmt.Fprintf(&b, ", budget %v", entryBudget)
+	}
+	b.WriteString(")\n")
+	fmt.Fprintf(&b, "%-3s %-24s %9s %7s %10s %10s %10s\n", "#", "Hop", "Timeout", "Retries", "Worst", "Cumulative", "Remaining")
+	var cumulative time.Duration
+	for i, e := range path {
+		worst := graph.WorstCaseLatency(path[i : i+1])
+		start := cumulative
+		cumulative += worst
+		remaining := ""
+		if entryBudget > 0 {
+			remaining = (entryBudget - cumulative).String()
+		}
+		fmt.Fprintf(&b, "%-3d %-24s %9v %7d %10v %10v %10s %s", i+1, e.From+" -> "+e.To,
+			e.Timeout, e.MaxRetries, worst, cumulative, remaining, waterfallBar(start, cumulative, scale))
+		if entryBudget > 0 && cumulative > entryBudget {
+			b.WriteString(" exhausted")
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// waterfallPath names the services along path, "A -> B -> C".
+func waterfallPath(path []graph.Edge) string {
+	names := []string{path[0].From}
+	for _, e := range path {
+		names = append(names, e.To)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// waterfallBar draws the span from start to end on a waterfallWidth scale
+// covering scale.
+func waterfallBar(start, end, scale time.Duration) string {
+	if scale <= 0 {
+		return "|" + strings.Repeat(" ", waterfallWidth) + "|"
+	}
+	from := int(int64(start) * waterfallWidth / int64(scale))
+	to := int(int64(end) * waterfallWidth / int64(scale))
+	if to == from && end > start {
+		to++
+	}
+	if to > waterfallWidth {
+		to = waterfallWidth
+	}
+	return "|" + strings.Repeat(" ", from) + strings.Repeat("#", to-from) + strings.Repeat(" ", waterfallWidth-to) + "|"
+}
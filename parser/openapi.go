@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods are the path item keys that hold operations, in the order
+// OpenAPI lists them.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPIOperation holds the vendor extensions CascadeGuard reads from one
+// operation. Caller overrides the document-level x-caller.
+type openAPIOperation struct {
+	Caller         string `yaml:"x-caller"`
+	Timeout        string `yaml:"x-timeout"`
+	Retries        *int   `yaml:"x-retries"`
+	CircuitBreaker *bool  `yaml:"x-circuit-breaker"`
+	Idempotent     *bool  `yaml:"x-idempotent"`
+	BackoffBase    string `yaml:"x-backoff-base"`
+	BackoffJitter  bool   `yaml:"x-backoff-jitter"`
+}
+
+// resilient reports whether the operation carries any resilience extension.
+func (op openAPIOperation) resilient() bool {
+	return op.Timeout != "" || op.Retries != nil || op.CircuitBreaker != nil ||
+		op.Idempotent != nil || op.BackoffBase != "" || op.BackoffJitter
+}
+
+type openAPIDocument struct {
+	Info struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Service string                          `yaml:"x-service"`
+	Caller  string                          `yaml:"x-caller"`
+	Paths   map[string]map[string]yaml.Node `yaml:"paths"`
+	// IdempotencyKey says the service's mutating operations accept an
+	// idempotency key.
+	IdempotencyKey bool `yaml:"x-supports-idempotency-key"`
+}
+
+// OpenAPICaller is the calling service used by ParseOpenAPI for operations
+// when neither the operation nor the document sets x-caller.
+const OpenAPICaller = "client"
+
+// ParseOpenAPI maps an OpenAPI document (YAML or JSON) onto a topology. The
+// document describes one service, named by x-service or info.title, and
+// every path+method becomes one of its endpoints ("GET /users/{id}"). Each
+// operation carrying a resilience extension becomes a call to that endpoint
+// from its x-caller (or the document's, or OpenAPICaller): the timeout
+// comes from x-timeout (a duration, or a bare number of milliseconds),
+// retries from x-retries, and the backoff from x-backoff-base and
+// x-backoff-jitter; x-circuit-breaker and x-idempotent set the matching
+// call fields. A document-level x-supports-idempotency-key: true sets the
+// service's supports_idempotency_key.
+func ParseOpenAPI(r io.Reader) (*RawTopology, error) {
+	var doc openAPIDocument
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	service := doc.Service
+	if service == "" {
+		service = doc.Info.Title
+	}
+	topo := &RawTopology{Services: map[string]RawService{}}
+	if len(doc.Paths) == 0 {
+		return topo, nil
+	}
+	if service == "" {
+		return nil, fmt.Errorf("openapi: name the service with x-service or info.title")
+	}
+	defaultCaller := doc.Caller
+	if defaultCaller == "" {
+		defaultCaller = OpenAPICaller
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var endpoints []string
+	calls := make(map[string][]RawCall) // caller -> calls
+	var callers []string
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, method := range openAPIMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := node.Decode(&op); err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			endpoint := strings.ToUpper(method) + " " + path
+			endpoints = append(endpoints, endpoint)
+			if !op.resilient() {
+				continue
+			}
+			call := RawCall{
+				Target:        service,
+				Method:        strings.ToUpper(method),
+				Endpoint:      path,
+				Timeout:       r4jDuration(op.Timeout),
+				BackoffBase:   r4jDuration(op.BackoffBase),
+				BackoffJitter: op.BackoffJitter,
+				Idempotent:    op.Idempotent,
+			}
+			if op.Retries != nil {
+				if *op.Retries < 0 {
+					return nil, fmt.Errorf("openapi: %s: x-retries must not be negative", endpoint)
+				}
+				call.Retries = *op.Retries
+			}
+			call.CircuitBreaker = op.CircuitBreaker != nil && *op.CircuitBreaker
+			caller := op.Caller
+			if caller == "" {
+				caller = defaultCaller
+			}
+			if _, ok := calls[caller]; !ok {
+				callers = append(callers, caller)
+			}
+			calls[caller] = append(calls[caller], call)
+		}
+	}
+	topo.Services[service] = RawService{Endpoints: endpoints, SupportsIdempotencyKey: doc.IdempotencyKey}
+	for _, caller := range callers {
+		if caller == service {
+			return nil, fmt.Errorf("openapi: %s cannot call itself", service)
+		}
+		topo.Services[caller] = RawService{Calls: calls[caller]}
+	}
+	return topo, nil
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTopology(t *testing.T) {
+	src := `services:
+  gateway:
+    calls:
+      - target: user-svc
+        timeout: 3s
+        retries: 3
+        method: GET
+`
+	topo, err := ParseTopology(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := topo.Services["gateway"].Calls
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	if calls[0].Target != "user-svc" || calls[0].Timeout != "3s" || calls[0].Retries != 3 {
+		t.Errorf("unexpected call: %+v", calls[0])
+	}
+}
+
+func TestParseTopologyEmpty(t *testing.T) {
+	topo, err := ParseTopology(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("empty document should parse, got %v", err)
+	}
+	if len(topo.Services) != 0 {
+		t.Errorf("expected no services, got %d", len(topo.Services))
+	}
+}
+
+func TestSchemaValidJSONWithRequiredServices(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	req, ok := s["required"].([]interface{})
+	if !ok || len(req) == 0 || req[0] != "services" {
+		t.Fatalf("expected services to be required, got %v", s["required"])
+	}
+	props := s["properties"].(map[string]interface{})
+	services := props["services"].(map[string]interface{})
+	svc := services["additionalProperties"].(map[string]interface{})
+	calls := svc["properties"].(map[string]interface{})["calls"].(map[string]interface{})
+	call := calls["items"].(map[string]interface{})
+	timeout := call["properties"].(map[string]interface{})["timeout"].(map[string]interface{})
+	if timeout["pattern"] != durationPattern {
+		t.Errorf("timeout should carry the duration pattern, got %v", timeout["pattern"])
+	}
+}
+
+func TestParseTopologyCallKind(t *testing.T) {
+	src := `services:
+  lb:
+    calls:
+      - target: api
+        kind: health
+`
+	topo, err := ParseTopology(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := topo.Services["lb"].Calls[0].Kind; kind != "health" {
+		t.Errorf("want kind health, got %q", kind)
+	}
+}
+
+func TestParseTopologyBoundaryFields(t *testing.T) {
+	src := `services:
+  api:
+    calls:
+      - target: partner
+        crosses_boundary: true
+        secure: true
+        max_concurrency: 32
+        async: true
+`
+	topo, err := ParseTopology(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := topo.Services["api"].Calls[0]
+	if !c.CrossesBoundary || !c.Secure {
+		t.Errorf("want crosses_boundary and secure set, got %+v", c)
+	}
+	if c.MaxConcurrency != 32 {
+		t.Errorf("want max_concurrency 32, got %d", c.MaxConcurrency)
+	}
+	if !c.Async {
+		t.Error("want async set")
+	}
+}
+
+func TestParseResilience4j(t *testing.T) {
+	f, err := os.Open("testdata/resilience4j.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	topo, err := ParseResilience4j(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc, ok := topo.Services["checkout"]
+	if !ok || len(topo.Services) != 1 {
+		t.Fatalf("want a single checkout service, got %+v", topo.Services)
+	}
+	want := []RawCall{
+		{Target: "inventory", Timeout: "1500ms", Retries: 4, BackoffBase: "0.5s"},
+		{Target: "payments", Timeout: "2s", Retries: 2, CircuitBreaker: true, BackoffBase: "200ms", BackoffJitter: true},
+	}
+	if !reflect.DeepEqual(svc.Calls, want) {
+		t.Errorf("calls:\n got  %+v\n want %+v", svc.Calls, want)
+	}
+}
+
+func TestParseOpenAPI(t *testing.T) {
+	f, err := os.Open("testdata/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	topo, err := ParseOpenAPI(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	no := false
+	want := map[string]RawService{
+		"orders": {Endpoints: []string{"GET /orders", "POST /orders", "GET /orders/{id}", "DELETE /orders/{id}"},
+			SupportsIdempotencyKey: true},
+		"gateway": {Calls: []RawCall{
+			{Target: "orders", Method: "GET", Endpoint: "/orders", Timeout: "800ms", Retries: 2, BackoffBase: "100ms", BackoffJitter: true},
+			{Target: "orders", Method: "POST", Endpoint: "/orders", Timeout: "2000ms", CircuitBreaker: true, Idempotent: &no},
+		}},
+		"checkout": {Calls: []RawCall{
+			{Target: "orders", Method: "GET", Endpoint: "/orders/{id}", Timeout: "1.5s", Retries: 3},
+		}},
+	}
+	if !reflect.DeepEqual(topo.Services, want) {
+		t.Errorf("services:\n got  %+v\n want %+v", topo.Services, want)
+	}
+}
+
+func TestParseOpenAPIRequiresServiceName(t *testing.T) {
+	src := `paths:
+  /health:
+    get:
+      x-timeout: 100ms
+`
+	if _, err := ParseOpenAPI(strings.NewReader(src)); err == nil {
+		t.Fatal("want error for a document without x-service or info.title")
+	}
+}
+
+func TestParseResilience4jLibraryDefaults(t *testing.T) {
+	src := `spring:
+  application:
+    name: orders
+resilience4j:
+  retry:
+    instances:
+      payments: {}
+  timelimiter:
+    instances:
+      payments: {}
+`
+	topo, err := ParseResilience4j(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []RawCall{{Target: "payments", Timeout: "1s", Retries: 2, BackoffBase: "500ms"}}
+	if got := topo.Services["orders"].Calls; !reflect.DeepEqual(got, want) {
+		t.Errorf("an instance with no keys takes Resilience4j's defaults:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParseResilience4jInvalidMaxAttempts(t *testing.T) {
+	src := `resilience4j:
+  retry:
+    instances:
+      x:
+        maxAttempts: 0
+`
+	if _, err := ParseResilience4j(strings.NewReader(src)); err == nil {
+		t.Error("expected error for maxAttempts 0")
+	}
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// r4jInstance holds the settings CascadeGuard reads from one Resilience4j
+// circuitbreaker, retry or timelimiter instance (or shared config).
+type r4jInstance struct {
+	BaseConfig           string `yaml:"baseConfig"`
+	MaxAttempts          *int   `yaml:"maxAttempts"`
+	WaitDuration         string `yaml:"waitDuration"`
+	EnableRandomizedWait *bool  `yaml:"enableRandomizedWait"`
+	TimeoutDuration      string `yaml:"timeoutDuration"`
+}
+
+type r4jModule struct {
+	Configs   map[string]r4jInstance `yaml:"configs"`
+	Instances map[string]r4jInstance `yaml:"instances"`
+}
+
+// r4jDefaultMaxAttempts is Resilience4j's own retry maxAttempts default.
+var r4jDefaultMaxAttempts = 3
+
+// Resilience4j's own defaults for the retry and timelimiter modules, which
+// apply when neither an instance nor its base config sets a value.
+var (
+	r4jRetryDefaults       = r4jInstance{MaxAttempts: &r4jDefaultMaxAttempts, WaitDuration: "500ms"}
+	r4jTimeLimiterDefaults = r4jInstance{TimeoutDuration: "1s"}
+)
+
+// resolve returns the named instance with unset fields filled in from its
+// baseConfig (or the "default" config), and then from defaults.
+func (m r4jModule) resolve(name string, defaults r4jInstance) (r4jInstance, bool) {
+	inst, ok := m.Instances[name]
+	if !ok {
+		return r4jInstance{}, false
+	}
+	baseName := inst.BaseConfig
+	if baseName == "" {
+		baseName = "default"
+	}
+	base := m.Configs[baseName]
+	if inst.MaxAttempts == nil {
+		inst.MaxAttempts = base.MaxAttempts
+	}
+	if inst.WaitDuration == "" {
+		inst.WaitDuration = base.WaitDuration
+	}
+	if inst.EnableRandomizedWait == nil {
+		inst.EnableRandomizedWait = base.EnableRandomizedWait
+	}
+	if inst.TimeoutDuration == "" {
+		inst.TimeoutDuration = base.TimeoutDuration
+	}
+	if inst.MaxAttempts == nil {
+		inst.MaxAttempts = defaults.MaxAttempts
+	}
+	if inst.WaitDuration == "" {
+		inst.WaitDuration = defaults.WaitDuration
+	}
+	if inst.TimeoutDuration == "" {
+		inst.TimeoutDuration = defaults.TimeoutDuration
+	}
+	return inst, true
+}
+
+type r4jDocument struct {
+	Spring struct {
+		Application struct {
+			Name string `yaml:"name"`
+		} `yaml:"application"`
+	} `yaml:"spring"`
+	Resilience4j struct {
+		CircuitBreaker r4jModule `yaml:"circuitbreaker"`
+		Retry          r4jModule `yaml:"retry"`
+		TimeLimiter    r4jModule `yaml:"timelimiter"`
+	} `yaml:"resilience4j"`
+}
+
+// Resilience4jSource is the calling service used by ParseResilience4j when
+// the document does not set spring.application.name.
+const Resilience4jSource = "resilience4j"
+
+// ParseResilience4j maps a Resilience4j (Spring Boot style) YAML config onto
+// a topology. Every circuitbreaker, retry or timelimiter instance name
+// becomes the target of one call from the configuring service, named by
+// spring.application.name (or Resilience4jSource). The timeout comes from
+// timelimiter timeoutDuration, retries from retry maxAttempts (which counts
+// the first call), the backoff base from waitDuration and jitter from
+// enableRandomizedWait; an instance under circuitbreaker sets
+// circuit_breaker. Instances inherit unset values from their baseConfig, or
+// from the "default" config, and then take Resilience4j's own defaults
+// (maxAttempts 3, waitDuration 500ms, timeoutDuration 1s).
+func ParseResilience4j(r io.Reader) (*RawTopology, error) {
+	var doc r4jDocument
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	r4j := doc.Resilience4j
+	names := make(map[string]bool)
+	for _, m := range []r4jModule{r4j.CircuitBreaker, r4j.Retry, r4j.TimeLimiter} {
+		for name := range m.Instances {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var calls []RawCall
+	for _, name := range sorted {
+		call := RawCall{Target: name}
+		_, call.CircuitBreaker = r4j.CircuitBreaker.Instances[name]
+		if retry, ok := r4j.Retry.resolve(name, r4jRetryDefaults); ok {
+			if retry.MaxAttempts != nil {
+				if *retry.MaxAttempts < 1 {
+					return nil, fmt.Errorf("retry %s: maxAttempts must be at least 1", name)
+				}
+				call.Retries = *retry.MaxAttempts - 1
+			}
+			call.BackoffBase = r4jDuration(retry.WaitDuration)
+			call.BackoffJitter = retry.EnableRandomizedWait != nil && *retry.EnableRandomizedWait
+		}
+		if tl, ok := r4j.TimeLimiter.resolve(name, r4jTimeLimiterDefaults); ok {
+			call.Timeout = r4jDuration(tl.TimeoutDuration)
+		}
+		calls = append(calls, call)
+	}
+
+	source := doc.Spring.Application.Name
+	if source == "" {
+		source = Resilience4jSource
+	}
+	topo := &RawTopology{Services: map[string]RawService{}}
+	if len(calls) > 0 {
+		topo.Services[source] = RawService{Calls: calls}
+	}
+	return topo, nil
+}
+
+// r4jDuration converts a Spring duration (a bare number of milliseconds,
+// ISO-8601 like "PT2S", or a simple form like "500ms") into a Go duration
+// string.
+func r4jDuration(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if _, err := strconv.Atoi(s); err == nil {
+		return s + "ms"
+	}
+	if upper := strings.ToUpper(s); strings.HasPrefix(upper, "PT") {
+		return strings.ToLower(upper[2:])
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches strings accepted by time.ParseDuration.
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema returns a JSON Schema describing RawTopology. It is derived from
+// the struct definitions by reflection so it cannot drift from the parser:
+// property names come from the yaml tags, and a `schema` tag marks fields
+// as "required" or as Go "duration" strings.
+func Schema() ([]byte, error) {
+	s := typeSchema(reflect.TypeOf(RawTopology{}))
+	s["$schema"] = schemaDraft
+	s["title"] = "CascadeGuard topology"
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			p := typeSchema(f.Type)
+			for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+				switch opt {
+				case "required":
+					required = append(required, name)
+				case "duration":
+					p["pattern"] = durationPattern
+				}
+			}
+			props[name] = p
+		}
+		s := map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
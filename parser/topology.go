@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RawTopology is the on-disk topology format as written by users. Values are
+// kept as authored (durations are strings); converting them into an
+// analyzable graph is the caller's job.
+type RawTopology struct {
+	// Includes lists further topology files whose services are merged into
+	// this one, relative to this file.
+	Includes   []string              `yaml:"includes"`
+	Services   map[string]RawService `yaml:"services" schema:"required"`
+	TierPolicy *RawTierPolicy        `yaml:"tier_policy"`
+}
+
+// RawTierPolicy overrides the default architectural constraints between
+// service tiers.
+type RawTierPolicy struct {
+	Allow                 map[string][]string `yaml:"allow"`
+	RequireCircuitBreaker []string            `yaml:"require_circuit_breaker"`
+}
+
+// RawService is one service entry under "services".
+type RawService struct {
+	Tier              string `yaml:"tier"`
+	Namespace         string `yaml:"namespace"`
+	Replicas          int    `yaml:"replicas"`
+	MinProcessingTime string `yaml:"min_processing_time" schema:"duration"`
+	AggregateTimeout  string `yaml:"aggregate_timeout" schema:"duration"`
+	// Autoscaling says whether the service adds instances under load;
+	// Capacity is the requests per second it can serve.
+	Autoscaling *bool `yaml:"autoscaling"`
+	Capacity    int   `yaml:"capacity"`
+	// DeterministicErrors says the service fails the same way every time
+	// for a given request (e.g. validation), so retrying it never helps.
+	DeterministicErrors bool `yaml:"deterministic_errors"`
+	// SupportsIdempotencyKey says its mutating endpoints accept an
+	// idempotency key, so a retried write is applied only once.
+	SupportsIdempotencyKey bool `yaml:"supports_idempotency_key"`
+	// Aliases are other names the service is called by, e.g. a proxy or
+	// DNS name in front of it; calls to an alias count as calls to the
+	// service.
+	Aliases []string `yaml:"aliases"`
+	// Endpoints lists the operations the service exposes, each a path
+	// optionally preceded by its method ("GET /users/{id}").
+	Endpoints []string  `yaml:"endpoints"`
+	Sources   []string  `yaml:"sources"`
+	Calls     []RawCall `yaml:"calls"`
+}
+
+// RawCall is one outbound dependency of a service.
+type RawCall struct {
+	Target          string   `yaml:"target" schema:"required"`
+	Timeout         string   `yaml:"timeout" schema:"duration"`
+	Retries         int      `yaml:"retries"`
+	CircuitBreaker  bool     `yaml:"circuit_breaker"`
+	Method          string   `yaml:"method"`
+	Endpoint        string   `yaml:"endpoint"`
+	Kind            string   `yaml:"kind"`
+	Idempotent      *bool    `yaml:"idempotent"`
+	BackoffJitter   bool     `yaml:"backoff_jitter"`
+	BackoffBase     string   `yaml:"backoff_base" schema:"duration"`
+	ProcessingTime  string   `yaml:"processing_time" schema:"duration"`
+	P99             string   `yaml:"p99" schema:"duration"`
+	ReadsFrom       []string `yaml:"reads_from"`
+	Secure          bool     `yaml:"secure"`
+	CrossesBoundary bool     `yaml:"crosses_boundary"`
+	MaxConcurrency  int      `yaml:"max_concurrency"`
+	Async           bool     `yaml:"async"`
+	InTransaction   bool     `yaml:"in_transaction"`
+	Label           string   `yaml:"label"`
+	// Confidence (0 to 1, default 1) is how sure the author is of this
+	// call's config; source-extracted topologies set it from the extractor.
+	Confidence float64 `yaml:"confidence"`
+	// CBHalfOpenRequests is how many trial requests the circuit breaker
+	// lets through while half-open.
+	CBHalfOpenRequests int `yaml:"cb_half_open_requests"`
+	// CBOpenTimeout is how long the circuit breaker stays open before it
+	// lets half-open trial requests through.
+	CBOpenTimeout string `yaml:"cb_open_timeout" schema:"duration"`
+	// CBCounts says what the circuit breaker counts as one failure:
+	// "per-call" (a whole call, retries included) or "per-attempt".
+	CBCounts string `yaml:"cb_counts"`
+	// CBFailureThreshold is how many failures open the circuit breaker;
+	// CBWindow is where they are counted: "per-request" (the breaker lives
+	// for one request) or "shared" (across requests).
+	CBFailureThreshold int    `yaml:"cb_failure_threshold"`
+	CBWindow           string `yaml:"cb_window"`
+	// Observable says whether the call is traced or metered, so that its
+	// retries and timeouts show up when they fire; nil if undeclared.
+	Observable *bool `yaml:"observable"`
+	// CallProbability (0 to 1, default 1) is how often a request to the
+	// caller makes this call; FailureProbability (0 to 1) is how often one
+	// attempt fails and is retried. Both feed the expected-load estimate.
+	CallProbability    float64 `yaml:"call_probability"`
+	FailureProbability float64 `yaml:"failure_probability"`
+	// HonorsRetryAfter says whether the client waits as long as a 429/503
+	// response's Retry-After header (or other backpressure signal) asks
+	// before retrying; nil if undeclared.
+	HonorsRetryAfter *bool `yaml:"honors_retry_after"`
+	// Criticality is how much the call matters to users: "low", "medium"
+	// or "high".
+	Criticality string `yaml:"criticality"`
+}
+
+// ParseTopology decodes a YAML topology document from r.
+func ParseTopology(r io.Reader) (*RawTopology, error) {
+	var topo RawTopology
+	if err := yaml.NewDecoder(r).Decode(&topo); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &topo, nil
+}
+
+// ServiceNames returns the names of the declared services in sorted order,
+// so conversions visit them the same way on every run.
+func (t *RawTopology) ServiceNames() []string {
+	names := make([]string, 0, len(t.Services))
+	for svc := range t.Services {
+		names = append(names, svc)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Aliases maps each declared alias to the service it names. An alias may
+// not be a service name or belong to two services.
+func (t *RawTopology) Aliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, svc := range t.ServiceNames() {
+		for _, alias := range t.Services[svc].Aliases {
+			if alias == "" {
+				return nil, fmt.Errorf("%s has an empty alias", svc)
+			}
+			if _, ok := t.Services[alias]; ok {
+				return nil, fmt.Errorf("%s alias %q is also a service name", svc, alias)
+			}
+			if other, ok := aliases[alias]; ok && other != svc {
+				return nil, fmt.Errorf("alias %q belongs to both %s and %s", alias, other, svc)
+			}
+			aliases[alias] = svc
+		}
+	}
+	return aliases, nil
+}
+
+// ResolveAlias returns the service name refers to: the service owning the
+// alias in aliases (see Aliases), or name itself when it is not an alias.
+func ResolveAlias(aliases map[string]string, name string) string {
+	if canonical, ok := aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// nonIdempotentMethods are the HTTP methods not safe to retry by default.
+var nonIdempotentMethods = map[string]bool{"POST": true, "PATCH": true, "DELETE": true}
+
+// Idempotent reports whether a call is safe to retry: an explicit
+// declaration wins, otherwise the HTTP method decides (an empty method
+// means GET).
+func Idempotent(method string, declared *bool) bool {
+	if declared != nil {
+		return *declared
+	}
+	return !nonIdempotentMethods[method]
+}
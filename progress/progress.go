@@ -0,0 +1,66 @@
+// Package progress reports how far a long-running analysis has got.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Stages reported by CascadeGuard.
+const (
+	Files = "files" // topology or source files processed
+	Paths = "paths" // call paths enumerated
+	Rules = "rules" // rules evaluated
+)
+
+// Reporter receives progress events. Add is called with the number of newly
+// completed items in a stage. Implementations must be safe for concurrent
+// use.
+type Reporter interface {
+	Add(stage string, n int)
+}
+
+// Nop discards all progress events.
+var Nop Reporter = nop{}
+
+type nop struct{}
+
+func (nop) Add(string, int) {}
+
+// Counter accumulates per-stage totals.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *Counter) Add(stage string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[stage] += n
+}
+
+// Count returns the total reported for stage so far.
+func (c *Counter) Count(stage string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[stage]
+}
+
+// Writer counts events and writes a running total line per event to W,
+// which is normally stderr so it never mixes with report output.
+type Writer struct {
+	W       io.Writer
+	counter Counter
+}
+
+func (w *Writer) Add(stage string, n int) {
+	w.counter.Add(stage, n)
+	fmt.Fprintf(w.W, "progress: %s %d\n", stage, w.counter.Count(stage))
+}
+
+// Count returns the total reported for stage so far.
+func (w *Writer) Count(stage string) int { return w.counter.Count(stage) }
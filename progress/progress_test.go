@@ -0,0 +1,51 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCounterAccounting(t *testing.T) {
+	var c Counter
+	c.Add(Paths, 3)
+	c.Add(Paths, 2)
+	c.Add(Files, 1)
+	if got := c.Count(Paths); got != 5 {
+		t.Errorf("paths: want 5, got %d", got)
+	}
+	if got := c.Count(Files); got != 1 {
+		t.Errorf("files: want 1, got %d", got)
+	}
+	if got := c.Count(Rules); got != 0 {
+		t.Errorf("rules: want 0, got %d", got)
+	}
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	var c Counter
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Add(Rules, 1)
+		}()
+	}
+	wg.Wait()
+	if got := c.Count(Rules); got != 50 {
+		t.Errorf("rules: want 50, got %d", got)
+	}
+}
+
+func TestWriterReportsRunningTotal(t *testing.T) {
+	var buf bytes.Buffer
+	w := &Writer{W: &buf}
+	w.Add(Files, 1)
+	w.Add(Files, 1)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[1] != "progress: files 2" {
+		t.Errorf("unexpected progress lines: %q", lines)
+	}
+}
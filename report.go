@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cascadeguard/cascadeguard/graph"
+	"github.com/cascadeguard/cascadeguard/output"
+)
+
+// outputSpec is one -output destination: a renderer name and the file it
+// writes to. An empty Path (or "-") means stdout.
+type outputSpec struct {
+	Format string
+	Path   string
+}
+
+// outputFormats lists the renderers selectable with -output.
+var outputFormats = map[string]bool{"text": true, "md": true, "sarif": true, "mermaid": true, "github": true, "svg": true, "issues": true}
+
+// parseOutputSpec parses "format[:file]", e.g. "sarif:results.sarif".
+func parseOutputSpec(s string) (outputSpec, error) {
+	format, path, _ := strings.Cut(s, ":")
+	if !outputFormats[format] {
+		return outputSpec{}, fmt.Errorf("unknown output format %q (want text, md, sarif, mermaid, github, svg or issues)", format)
+	}
+	return outputSpec{Format: format, Path: path}, nil
+}
+
+// outputFlags collects repeated -output flags.
+type outputFlags []outputSpec
+
+func (o *outputFlags) String() string {
+	parts := make([]string, 0, len(*o))
+	for _, s := range *o {
+		if s.Path == "" {
+			parts = append(parts, s.Format)
+		} else {
+			parts = append(parts, s.Format+":"+s.Path)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (o *outputFlags) Set(v string) error {
+	spec, err := parseOutputSpec(v)
+	if err != nil {
+		return err
+	}
+	*o = append(*o, spec)
+	return nil
+}
+
+// writeOutputs renders the analysis of the topology file source once per
+// spec, sending each to its file or to stdout. cg is the analyzed topology
+// as built by graph.BuildCallGraph, or nil when it was not built (stream
+// mode).
+func writeOutputs(specs []outputSpec, source string, edges []CallEdge, cg *graph.CallGraph, findings []Finding, stdout io.Writer) error {
+	for _, spec := range specs {
+		if spec.Path == "" || spec.Path == "-" {
+			if err := render(spec.Format, source, edges, cg, findings, stdout); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Create(spec.Path)
+		if err != nil {
+			return err
+		}
+		err = render(spec.Format, source, edges, cg, findings, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", spec.Path, err)
+		}
+	}
+	return nil
+}
+
+func render(format, source string, edges []CallEdge, cg *graph.CallGraph, findings []Finding, w io.Writer) error {
+	switch format {
+	case "text":
+		return renderText(edges, cg, findings, w)
+	case "md":
+		return output.RenderMarkdown(toViolations(findings), w)
+	case "sarif":
+		return output.RenderSARIF(toViolations(findings), w)
+	case "github":
+		vs := toViolations(findings)
+		for i := range vs {
+			vs[i].File = source
+		}
+		return output.RenderGitHubActions(vs, w)
+	case "issues":
+		vs := toViolations(findings)
+		for i := range vs {
+			vs[i].File = source
+		}
+		return output.RenderIssues(vs, w)
+	case "svg":
+		return output.RenderSVG(toOutputGraph(edges), toViolations(findings), w)
+	case "mermaid":
+		if err := output.RenderMermaid(toOutputGraph(edges), toViolations(findings), w); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// violationSubgraph keeps only the edges that appear as a consecutive hop in
+// at least one finding's path, preserving their original order.
+func violationSubgraph(edges []CallEdge, findings []Finding) []CallEdge {
+	type edgeKey struct{ src, tgt string }
+	involved := make(map[edgeKey]bool)
+	for _, f := range findings {
+		for i := 0; i+1 < len(f.Path); i++ {
+			involved[edgeKey{f.Path[i], f.Path[i+1]}] = true
+		}
+	}
+	var out []CallEdge
+	for _, e := range edges {
+		if involved[edgeKey{e.Source, e.Target}] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (f Finding) fingerprint() string {
+	return output.Fingerprint(output.Violation{Rule: f.Rule, Path: f.Path})
+}
+
+func toViolations(findings []Finding) []output.Violation {
+	vs := make([]output.Violation, 0, len(findings))
+	for _, f := range findings {
+		vs = append(vs, output.Violation{Rule: f.Rule, Severity: f.Severity, Message: f.Message, Path: f.Path, File: f.File, Age: f.Age, Help: ruleHelp(f.Rule)})
+	}
+	return vs
+}
+
+func toOutputGraph(edges []CallEdge) output.CallGraph {
+	g := output.CallGraph{Edges: make([]output.Edge, 0, len(edges))}
+	for _, e := range edges {
+		g.Edges = append(g.Edges, toOutputEdge(e))
+	}
+	return g
+}
+
+func toOutputEdge(e CallEdge) output.Edge {
+	return output.Edge{Source: e.Source, Target: e.Target,
+		Timeout: e.Timeout.String(), Retries: e.Retries, Label: e.Label}
+}
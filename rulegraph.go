@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/progress"
+	"github.com/cascadeguard/cascadeguard/rules"
+)
+
+// extraRules returns the detectors from the rules package that have no
+// built-in counterpart in analyzer.go, keyed by the rule ID they report.
+// They run against the Graph through the rules.CallGraph adapter below.
+func (g *Graph) extraRules() map[string]rules.Rule {
+	return map[string]rules.Rule{
+		"tier-violation":                  &rules.TierViolationRule{Policy: g.TierPolicy},
+		"read-after-write-retry":          &rules.ReadAfterWriteRetryRule{},
+		"uniform-config":                  &rules.UniformConfigRule{MinClusterSize: g.Config.UniformConfigMinCluster},
+		"bidirectional-timeout-asymmetry": &rules.BidirectionalTimeoutRule{MaxRatio: g.Config.BidirectionalMaxRatio},
+		"mutual-retry":                    &rules.MutualRetryRule{},
+		"self-call":                       &rules.SelfCallRule{},
+		"retries-exceed-replicas":         &rules.RetriesExceedReplicasRule{},
+		"retry-into-fixed-capacity":       &rules.RetryIntoFixedCapacityRule{},
+		"retry-deterministic-errors":      &rules.DeterministicErrorRetryRule{},
+		"idempotent-method-mismatch":      &rules.IdempotentMethodMismatchRule{Methods: g.Config.IdempotentMismatchMethods},
+		"inconsistent-idempotency":        &rules.InconsistentIdempotencyRule{ParamPatterns: g.Config.EndpointParamPatterns},
+		"conflicting-idempotency":         &rules.ConflictingIdempotencyRule{},
+		"unkeyed-mutating-retry":          &rules.MutatingRetryWithoutKeyRule{},
+		"unknown-target-endpoint":         &rules.UnknownTargetEndpointRule{ParamPatterns: g.Config.EndpointParamPatterns},
+		"retry-over-slow-downstream":      &rules.SlowDownstreamRetryRule{LongTimeout: time.Duration(g.Config.SlowDownstreamTimeout)},
+		"backend-request-cap":             &rules.BackendRequestCapRule{Cap: g.Config.BackendRequestCap},
+		"global-request-cap":              &rules.GlobalRequestCapRule{Cap: g.Config.GlobalRequestCap},
+		"retry-on-health-check":           &rules.RetryOnHealthCheckRule{},
+		"retry-ignores-backpressure":      &rules.IgnoredBackpressureRetryRule{},
+		"unobserved-resilience":           &rules.UnobservedResilienceRule{},
+		"jitter-no-backoff":               &rules.JitterWithoutBackoffRule{},
+		"entry-timeout-missing":           &rules.EntryTimeoutMissingRule{Entry: g.Entry, EntryTimeout: time.Duration(g.Config.EntryTimeout)},
+		"cb-counts-per-call":              &rules.CircuitBreakerCountsPerCallRule{MinRetries: g.Config.CBCountsPerCallMinRetries},
+		"cb-threshold-unreachable":        &rules.UnreachableCBThresholdRule{},
+		"cb-open-window-exceeds-entry":    &rules.CircuitBreakerOpenWindowRule{EntryTimeout: time.Duration(g.Config.EntryTimeout)},
+		"first-retry-impossible":          &rules.FirstRetryImpossibleRule{},
+		"wasted-leaf-retry":               &rules.WastedLeafRetryRule{EntryTimeout: time.Duration(g.Config.EntryTimeout)},
+		"budget-allocation":               &rules.BudgetAllocationRule{EntryTimeout: time.Duration(g.Config.EntryTimeout), MaxShare: g.Config.BudgetMaxShare},
+		"chokepoint":                      &rules.ChokepointRule{MinCallers: g.Config.ChokepointMinCallers, MinCallees: g.Config.ChokepointMinCallees},
+		"single-point-of-failure":         &rules.SinglePointOfFailureRule{},
+		"insecure-boundary-call":          &rules.InsecureBoundaryCallRule{},
+		"cb-half-open-probes":             &rules.CircuitBreakerHalfOpenRule{MaxRequests: g.Config.CBHalfOpenMaxRequests, RequireDeclared: g.Config.CBHalfOpenRequireDeclared},
+		"in-transaction-retry":            &rules.InTransactionRetryRule{},
+		"unbounded-retry":                 &rules.UnboundedRetryRule{},
+		"non-monotonic-budget":            &rules.NonMonotonicBudgetRule{},
+		"async-retry-mismatch":            &rules.AsyncRetryMismatchRule{},
+		"scatter-gather-timeout":          &rules.ScatterGatherTimeoutRule{MaxRatio: g.Config.ScatterGatherMaxRatio},
+		"fan-out-missing-timeout":         &rules.FanOutMissingTimeoutRule{},
+		"timeout-below-min-processing":    &rules.TimeoutBelowMinProcessingRule{},
+		"backoff-latency-inflation":       &rules.BackoffLatencyInflationRule{Budget: time.Duration(g.Config.P99Budget)},
+		"retry-tail-amplification":        &rules.RetryTailLatencyRule{Budget: time.Duration(g.Config.P99Budget)},
+		"timeout-no-headroom":             &rules.TimeoutHeadroomRule{OverheadFactor: g.Config.TimeoutOverheadFactor},
+		"suspicious-timeout-magnitude":    &rules.SuspiciousTimeoutMagnitudeRule{Max: time.Duration(g.Config.SuspiciousTimeoutMax), Min: time.Duration(g.Config.SuspiciousTimeoutMin)},
+		"timeout-matches-proxy":           &rules.TimeoutMatchesProxyRule{ProxyTimeouts: g.Config.proxyTimeouts(), Epsilon: time.Duration(g.Config.ProxyTimeoutEpsilon)},
+		"repeated-service-on-path":        &rules.RepeatedServiceOnPathRule{},
+		"write-between-retried-reads":     &rules.WriteBetweenRetriedReadsRule{},
+		"uniform-path-timeout":            &rules.UntunedUniformTimeoutRule{},
+		"chatty-chain":                    &rules.ChattyChainRule{MinHops: g.Config.ChattyChainMinHops, MaxMeanTimeout: time.Duration(g.Config.ChattyChainMaxMeanTimeout)},
+		"missing-aggregate-deadline":      &rules.MissingAggregateDeadlineRule{LargeTimeout: time.Duration(g.Config.AggregateLargeTimeout)},
+		"effective-timeout-inversion":     &rules.EffectiveTimeoutInversionRule{},
+	}
+}
+
+// optInRules are stricter detectors that only run when named with -enable,
+// keyed by the rule ID they report.
+var optInRules = map[string]func() rules.Rule{
+	"orphaned-retry":     func() rules.Rule { return &rules.OrphanedRetryRule{} },
+	"zero-backoff-retry": func() rules.Rule { return &rules.ZeroBackoffRetryRule{} },
+	"missing-bulkhead":   func() rules.Rule { return &rules.MissingBulkheadRule{} },
+}
+
+// ruleFindings runs the rules-package detectors and the plugins. The
+// detectors run once with Config and once per NamespaceConfig entry; each
+// finding is kept from the run matching the namespace of its caller (see
+// findingCaller).
+func (g *Graph) ruleFindings() []Finding {
+	namespaces := make([]string, 0, len(g.NamespaceConfig))
+	for ns := range g.NamespaceConfig {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	configured := func(v rules.Violation) string {
+		ns := g.Services[findingCaller(v)].Namespace
+		if _, ok := g.NamespaceConfig[ns]; ok {
+			return ns
+		}
+		return ""
+	}
+	f := g.runRules(g.detectors(), func(v rules.Violation) bool { return configured(v) == "" })
+	for _, ns := range namespaces {
+		scoped := *g
+		scoped.Config = g.NamespaceConfig[ns]
+		f = append(f, g.runRules(scoped.detectors(), func(v rules.Violation) bool { return configured(v) == ns })...)
+	}
+	var plugins []rules.Rule
+	for _, p := range g.Plugins {
+		argv := strings.Fields(p)
+		plugins = append(plugins, &rules.ExternalRule{Command: argv[0], Args: argv[1:]})
+	}
+	return append(f, g.runRules(plugins, func(rules.Violation) bool { return true })...)
+}
+
+// findingCaller is the service v is charged to: the source of its
+// SourceHint edge, the service its SourceHint names, or else the first
+// service on its path.
+func findingCaller(v rules.Violation) string {
+	if edge, ok := strings.CutPrefix(v.SourceHint, "edge "); ok {
+		src, _, _ := strings.Cut(edge, "->")
+		return src
+	}
+	if svc, ok := strings.CutPrefix(v.SourceHint, "service "); ok {
+		return svc
+	}
+	if len(v.Path) > 0 {
+		return v.Path[0]
+	}
+	return ""
+}
+
+// detectors returns the enabled rules-package detectors, configured from
+// g.Config and wrapped in their scopes, in rule ID order.
+func (g *Graph) detectors() []rules.Rule {
+	byID := g.extraRules()
+	for id := range g.Enabled {
+		if newRule, ok := optInRules[id]; ok {
+			byID[id] = newRule()
+		}
+	}
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rs := make([]rules.Rule, 0, len(ids))
+	for _, id := range ids {
+		r := byID[id]
+		if scope, ok := g.Scopes[id]; ok && scope != rules.ScopeAll {
+			r = &rules.ScopedRule{Rule: r, Scope: scope}
+		}
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// runRules checks g with each of rs and keeps the violations accepted by
+// keep.
+func (g *Graph) runRules(rs []rules.Rule, keep func(rules.Violation) bool) []Finding {
+	var f []Finding
+	for _, r := range rs {
+		vs := r.Check(g)
+		g.debug("rule evaluated", "rule", fmt.Sprintf("%T", r), "config", r,
+			"edges", len(g.Edges), "violations", len(vs))
+		for _, v := range vs {
+			if !keep(v) {
+				continue
+			}
+			g.debug("finding", "rule", v.Rule, "severity", v.Severity, "path", v.Path, "message", v.Message)
+			f = append(f, Finding{Rule: v.Rule, Severity: v.Severity, Message: v.Message, Path: v.Path})
+		}
+		g.progress().Add(progress.Rules, 1)
+	}
+	return f
+}
+
+// toRuleEdge converts a CLI call edge into the rules package representation.
+// Idempotency follows CallEdge.idempotent and, as in edgeRules, any retried
+// edge is treated as having a backoff.
+func toRuleEdge(e CallEdge) rules.Edge {
+	return rules.Edge{
+		Source:             e.Source,
+		Target:             e.Target,
+		Timeout:            e.Timeout,
+		MaxRetries:         e.Retries,
+		Method:             e.Method,
+		Endpoint:           e.Endpoint,
+		Kind:               e.Kind,
+		Idempotent:         e.idempotent(),
+		DeclaredIdempotent: e.Idempotent != nil && *e.Idempotent,
+		HasCircuitBreaker:  e.CircuitBreaker,
+		CBHalfOpenRequests: e.CBHalfOpenRequests,
+		CBOpenTimeout:      e.CBOpenTimeout,
+		CBCounts:           e.CBCounts,
+		CBFailureThreshold: e.CBFailureThreshold,
+		CBWindow:           e.CBWindow,
+		HasBackoff:         e.Retries > 0,
+		Jitter:             e.BackoffJitter,
+		BackoffBase:        e.BackoffBase,
+		ProcessingTime:     e.ProcessingTime,
+		P99:                e.P99,
+		ReadsFrom:          e.ReadsFrom,
+		Secure:             e.Secure,
+		CrossesBoundary:    e.CrossesBoundary,
+		MaxConcurrency:     e.MaxConcurrency,
+		Async:              e.Async,
+		InTransaction:      e.InTransaction,
+		IgnoresRetryAfter:  e.HonorsRetryAfter != nil && !*e.HonorsRetryAfter,
+		Unobservable:       e.Observable != nil && !*e.Observable,
+		CallProbability:    e.CallProbability,
+		FailureProbability: e.FailureProbability,
+	}
+}
+
+func toRuleEdges(edges []CallEdge) []rules.Edge {
+	out := make([]rules.Edge, 0, len(edges))
+	for _, e := range edges {
+		out = append(out, toRuleEdge(e))
+	}
+	return out
+}
+
+// ruleView holds a Graph's calls in the rules package representation. It is
+// built on first use and shared by every rule of an analysis, so each call
+// is converted once rather than on every lookup and path step.
+type ruleView struct {
+	all     []rules.Edge
+	out, in map[string][]rules.Edge
+	roots   []string
+}
+
+// asRules returns g's ruleView, building it on first use.
+func (g *Graph) asRules() *ruleView {
+	if g.view != nil {
+		return g.view
+	}
+	v := &ruleView{all: toRuleEdges(g.Edges), out: make(map[string][]rules.Edge), in: make(map[string][]rules.Edge)}
+	for _, e := range v.all {
+		v.out[e.Source] = append(v.out[e.Source], e)
+		v.in[e.Target] = append(v.in[e.Target], e)
+	}
+	g.view = v
+	v.roots, _ = g.roots() // uses the edges above
+	return v
+}
+
+// AllEdges implements rules.CallGraph.
+func (g *Graph) AllEdges() []rules.Edge { return g.asRules().all }
+
+// OutEdges implements rules.CallGraph.
+func (g *Graph) OutEdges(node string) []rules.Edge { return g.asRules().out[node] }
+
+// InEdges implements rules.CallGraph.
+func (g *Graph) InEdges(node string) []rules.Edge { return g.asRules().in[node] }
+
+// Node implements rules.CallGraph.
+func (g *Graph) Node(name string) rules.Node {
+	s := g.Services[name]
+	return rules.Node{Name: name, Namespace: s.Namespace, Tier: s.Tier, Replicas: s.Replicas,
+		MinProcessingTime: s.MinProcessingTime, AggregateTimeout: s.AggregateTimeout, Endpoints: s.Endpoints,
+		FixedCapacity: s.Autoscaling != nil && !*s.Autoscaling, Capacity: s.Capacity,
+		DeterministicErrors: s.DeterministicErrors, SupportsIdempotencyKey: s.SupportsIdempotencyKey}
+}
+
+// WalkPaths implements rules.CallGraph. It walks every root-to-leaf path,
+// stopping before a service would be revisited.
+func (g *Graph) WalkPaths(fn func(path []rules.Edge) bool) {
+	v := g.asRules()
+	buf := make([]rules.Edge, 0, len(v.all)) // no path is longer than the calls
+	for _, root := range v.roots {
+		if !v.walk(root, buf, map[string]bool{root: true}, fn) {
+			return
+		}
+	}
+}
+
+// countPaths returns the number of paths WalkPaths visits, counting no
+// further than limit when limit is positive.
+func (g *Graph) countPaths(limit int) int {
+	var n int
+	g.WalkPaths(func([]rules.Edge) bool {
+		n++
+		return limit <= 0 || n < limit
+	})
+	return n
+}
+
+// walk reports each path below node to fn and returns false once fn has
+// asked to stop.
+func (v *ruleView) walk(node string, cur []rules.Edge, visited map[string]bool, fn func([]rules.Edge) bool) bool {
+	var extended bool
+	for _, e := range v.out[node] {
+		if visited[e.Target] {
+			continue
+		}
+		extended = true
+		visited[e.Target] = true
+		ok := v.walk(e.Target, append(cur, e), visited, fn)
+		delete(visited, e.Target)
+		if !ok {
+			return false
+		}
+	}
+	return extended || len(cur) == 0 || fn(cur)
+}
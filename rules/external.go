@@ -0,0 +1,211 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExternalRule runs an out-of-process checker so organisations can maintain
+// their own rules separately from CascadeGuard. The checker receives the
+// graph as an ExternalGraph JSON document on stdin and must print an
+// ExternalResult JSON document on stdout, exiting 0. Anything it writes to
+// stderr is included in the error when it fails.
+type ExternalRule struct {
+	Command string
+	Args    []string
+	Env     []string      // extra KEY=value pairs on top of the current environment
+	Timeout time.Duration // default 30s
+}
+
+// ExternalGraph is the document sent to an external checker.
+type ExternalGraph struct {
+	Version int            `json:"version"` // currently 1
+	Nodes   []ExternalNode `json:"nodes"`
+	Edges   []ExternalEdge `json:"edges"`
+}
+
+// ExternalNode is a service in an ExternalGraph.
+type ExternalNode struct {
+	Name                   string   `json:"name"`
+	Tier                   string   `json:"tier,omitempty"`
+	Replicas               int      `json:"replicas,omitempty"`
+	MinProcessingTimeMs    int64    `json:"min_processing_time_ms,omitempty"`
+	AggregateTimeoutMs     int64    `json:"aggregate_timeout_ms,omitempty"`
+	Endpoints              []string `json:"endpoints,omitempty"`
+	FixedCapacity          bool     `json:"fixed_capacity,omitempty"`
+	Capacity               int      `json:"capacity,omitempty"`
+	DeterministicErrors    bool     `json:"deterministic_errors,omitempty"`
+	SupportsIdempotencyKey bool     `json:"supports_idempotency_key,omitempty"`
+}
+
+// ExternalEdge is a call in an ExternalGraph. Durations are milliseconds.
+type ExternalEdge struct {
+	Source             string   `json:"source"`
+	Target             string   `json:"target"`
+	TimeoutMs          int64    `json:"timeout_ms"`
+	MaxRetries         int      `json:"max_retries"`
+	Method             string   `json:"method,omitempty"`
+	Endpoint           string   `json:"endpoint,omitempty"`
+	Kind               string   `json:"kind,omitempty"`
+	Idempotent         bool     `json:"idempotent"`
+	HasCircuitBreaker  bool     `json:"has_circuit_breaker"`
+	CBHalfOpenRequests int      `json:"cb_half_open_requests,omitempty"`
+	CBOpenTimeoutMs    int64    `json:"cb_open_timeout_ms,omitempty"`
+	CBCounts           string   `json:"cb_counts,omitempty"`
+	CBFailureThreshold int      `json:"cb_failure_threshold,omitempty"`
+	CBWindow           string   `json:"cb_window,omitempty"`
+	HasBackoff         bool     `json:"has_backoff"`
+	Jitter             bool     `json:"jitter"`
+	BackoffBaseMs      int64    `json:"backoff_base_ms"`
+	ProcessingTimeMs   int64    `json:"processing_time_ms,omitempty"`
+	P99Ms              int64    `json:"p99_ms,omitempty"`
+	ReadsFrom          []string `json:"reads_from,omitempty"`
+	Secure             bool     `json:"secure"`
+	CrossesBoundary    bool     `json:"crosses_boundary"`
+	MaxConcurrency     int      `json:"max_concurrency"`
+	Async              bool     `json:"async"`
+	InTransaction      bool     `json:"in_transaction"`
+	IgnoresRetryAfter  bool     `json:"ignores_retry_after,omitempty"`
+	Unobservable       bool     `json:"unobservable,omitempty"`
+}
+
+// ExternalResult is the document an external checker returns.
+type ExternalResult struct {
+	Violations []ExternalViolation `json:"violations"`
+}
+
+// ExternalViolation is one finding reported by an external checker.
+type ExternalViolation struct {
+	Rule       string   `json:"rule"`
+	Severity   string   `json:"severity"`
+	Message    string   `json:"message"`
+	Path       []string `json:"path"`
+	SourceHint string   `json:"source_hint,omitempty"`
+}
+
+// NewExternalGraph converts cg into the document sent to external checkers.
+// Nodes are every service that appears on an edge, in sorted order.
+func NewExternalGraph(cg CallGraph) ExternalGraph {
+	doc := ExternalGraph{Version: 1, Nodes: []ExternalNode{}, Edges: []ExternalEdge{}}
+	seen := make(map[string]bool)
+	for _, e := range cg.AllEdges() {
+		seen[e.Source], seen[e.Target] = true, true
+		doc.Edges = append(doc.Edges, ExternalEdge{
+			Source:             e.Source,
+			Target:             e.Target,
+			TimeoutMs:          e.Timeout.Milliseconds(),
+			MaxRetries:         e.MaxRetries,
+			Method:             e.Method,
+			Endpoint:           e.Endpoint,
+			Kind:               e.Kind,
+			Idempotent:         e.Idempotent,
+			HasCircuitBreaker:  e.HasCircuitBreaker,
+			CBHalfOpenRequests: e.CBHalfOpenRequests,
+			CBOpenTimeoutMs:    e.CBOpenTimeout.Milliseconds(),
+			CBCounts:           e.CBCounts,
+			CBFailureThreshold: e.CBFailureThreshold,
+			CBWindow:           e.CBWindow,
+			HasBackoff:         e.HasBackoff,
+			Jitter:             e.Jitter,
+			BackoffBaseMs:      e.BackoffBase.Milliseconds(),
+			ProcessingTimeMs:   e.ProcessingTime.Milliseconds(),
+			P99Ms:              e.P99.Milliseconds(),
+			ReadsFrom:          e.ReadsFrom,
+			Secure:             e.Secure,
+			CrossesBoundary:    e.CrossesBoundary,
+			MaxConcurrency:     e.MaxConcurrency,
+			Async:              e.Async,
+			InTransaction:      e.InTransaction,
+			IgnoresRetryAfter:  e.IgnoresRetryAfter,
+			Unobservable:       e.Unobservable,
+		})
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := cg.Node(name)
+		doc.Nodes = append(doc.Nodes, ExternalNode{Name: name, Tier: n.Tier, Replicas: n.Replicas,
+			MinProcessingTimeMs:    n.MinProcessingTime.Milliseconds(),
+			AggregateTimeoutMs:     n.AggregateTimeout.Milliseconds(),
+			Endpoints:              n.Endpoints,
+			FixedCapacity:          n.FixedCapacity,
+			Capacity:               n.Capacity,
+			DeterministicErrors:    n.DeterministicErrors,
+			SupportsIdempotencyKey: n.SupportsIdempotencyKey})
+	}
+	return doc
+}
+
+// Run sends the graph to the checker and returns the violations it reports.
+func (r *ExternalRule) Run(cg CallGraph) ([]Violation, error) {
+	in, err := json.Marshal(NewExternalGraph(cg))
+	if err != nil {
+		return nil, err
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, r.Command, r.Args...)
+	cmd.Env = append(os.Environ(), r.Env...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", r.Command, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", r.Command, err)
+	}
+
+	var res ExternalResult
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return nil, fmt.Errorf("%s: invalid result: %w", r.Command, err)
+	}
+	violations := make([]Violation, 0, len(res.Violations))
+	for i, v := range res.Violations {
+		if v.Rule == "" {
+			return nil, fmt.Errorf("%s: violation %d has no rule", r.Command, i)
+		}
+		switch v.Severity {
+		case "info", "warning", "error":
+		default:
+			return nil, fmt.Errorf("%s: violation %d has invalid severity %q", r.Command, i, v.Severity)
+		}
+		violations = append(violations, Violation{
+			Rule:       v.Rule,
+			Severity:   v.Severity,
+			Path:       v.Path,
+			Message:    v.Message,
+			SourceHint: v.SourceHint,
+		})
+	}
+	return violations, nil
+}
+
+// Check implements Rule. A checker that fails or returns an invalid result
+// is reported as an "external-rule" error rather than silently ignored.
+func (r *ExternalRule) Check(cg CallGraph) []Violation {
+	vs, err := r.Run(cg)
+	if err != nil {
+		return []Violation{{
+			Rule:     "external-rule",
+			Severity: "error",
+			Message:  fmt.Sprintf("external checker failed: %v", err),
+		}}
+	}
+	return vs
+}
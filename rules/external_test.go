@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExternalCheckerStub is not a real test: when re-executed by
+// stubRule with CASCADEGUARD_STUB set, the test binary acts as an external
+// checker speaking the JSON protocol.
+func TestExternalCheckerStub(t *testing.T) {
+	mode := os.Getenv("CASCADEGUARD_STUB")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	var g ExternalGraph
+	if err := json.NewDecoder(os.Stdin).Decode(&g); err != nil {
+		fmt.Fprintln(os.Stderr, "bad input:", err)
+		os.Exit(3)
+	}
+	switch mode {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "checker exploded")
+		os.Exit(1)
+	case "garbage":
+		fmt.Print("not json")
+		os.Exit(0)
+	}
+	// "ok": flag every edge whose timeout is over 2s.
+	res := ExternalResult{Violations: []ExternalViolation{}}
+	for _, e := range g.Edges {
+		if e.TimeoutMs > 2000 {
+			res.Violations = append(res.Violations, ExternalViolation{
+				Rule:     "org-max-timeout",
+				Severity: "warning",
+				Message:  fmt.Sprintf("%s->%s timeout %dms over org limit (%d nodes seen)", e.Source, e.Target, e.TimeoutMs, len(g.Nodes)),
+				Path:     []string{e.Source, e.Target},
+			})
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(res)
+	os.Exit(0)
+}
+
+func stubRule(mode string) *ExternalRule {
+	return &ExternalRule{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestExternalCheckerStub$"},
+		Env:     []string{"CASCADEGUARD_STUB=" + mode},
+		Timeout: 10 * time.Second,
+	}
+}
+
+func TestExternalRuleProtocol(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "A", Target: "B", Timeout: 5 * time.Second},
+		Edge{Source: "B", Target: "C", Timeout: time.Second},
+	)
+	vs, err := stubRule("ok").Run(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vs) != 1 {
+		t.Fatalf("want 1 violation, got %+v", vs)
+	}
+	v := vs[0]
+	if v.Rule != "org-max-timeout" || v.Severity != "warning" || strings.Join(v.Path, ",") != "A,B" {
+		t.Errorf("unexpected violation %+v", v)
+	}
+	if !strings.Contains(v.Message, "5000ms") || !strings.Contains(v.Message, "3 nodes") {
+		t.Errorf("graph not passed through intact: %s", v.Message)
+	}
+}
+
+func TestExternalRuleFailures(t *testing.T) {
+	g := newMockGraph(Edge{Source: "A", Target: "B"})
+	for _, mode := range []string{"fail", "garbage"} {
+		t.Run(mode, func(t *testing.T) {
+			if _, err := stubRule(mode).Run(g); err == nil {
+				t.Fatal("expected error")
+			}
+			vs := stubRule(mode).Check(g)
+			if !hasSeverity(vs, "external-rule", "error") {
+				t.Errorf("failure should surface as an external-rule error, got %+v", vs)
+			}
+		})
+	}
+	if _, err := (&ExternalRule{Command: "cascadeguard-no-such-checker"}).Run(g); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
+
+func TestNewExternalGraph(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "B", Target: "A", Timeout: 1500 * time.Millisecond, BackoffBase: 100 * time.Millisecond},
+	).withNodes(Node{Name: "A", Tier: "data", Replicas: 3})
+	doc := NewExternalGraph(g)
+	if doc.Version != 1 || len(doc.Nodes) != 2 || doc.Nodes[0].Name != "A" || doc.Nodes[0].Replicas != 3 {
+		t.Errorf("unexpected nodes: %+v", doc)
+	}
+	if e := doc.Edges[0]; e.TimeoutMs != 1500 || e.BackoffBaseMs != 100 {
+		t.Errorf("unexpected edge: %+v", e)
+	}
+}
+
+var _ Rule = (*ExternalRule)(nil)
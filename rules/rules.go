@@ -2,19 +2,66 @@ package rules
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/cascadeguard/cascadeguard/graph"
 )
 
 // Edge represents a single directed call between two services.
 type Edge struct {
-	Source            string
-	Target            string
-	Timeout           time.Duration
-	MaxRetries        int
-	Idempotent        bool
-	HasCircuitBreaker bool
-	HasBackoff        bool
-	Jitter            bool
+	Source     string
+	Target     string
+	Timeout    time.Duration
+	MaxRetries int
+	Method     string // HTTP method, if known
+	Endpoint   string // request path, e.g. "/users/{id}", if known
+	Kind       string // call purpose, e.g. "health" for health/liveness checks
+	Idempotent bool
+	// DeclaredIdempotent is set when the topology explicitly marks the call
+	// idempotent, as opposed to idempotency inferred from the method.
+	DeclaredIdempotent bool
+	HasCircuitBreaker  bool
+	CBHalfOpenRequests int           // trial requests the breaker allows while half-open; 0 if undeclared
+	CBOpenTimeout      time.Duration // how long the breaker stays open before probing; 0 if undeclared
+	CBCounts           string        // CBCountsPerCall, CBCountsPerAttempt or "" if undeclared
+	CBFailureThreshold int           // failures that open the breaker; 0 if undeclared
+	CBWindow           string        // CBWindowPerRequest, CBWindowShared or "" if undeclared
+	HasBackoff         bool
+	Jitter             bool
+	BackoffBase        time.Duration // first retry delay; doubles on each further retry
+	ProcessingTime     time.Duration // target's typical handling time for this call; 0 if undeclared
+	P99                time.Duration // observed 99th percentile latency of this call; 0 if undeclared
+	ReadsFrom          []string      // services whose data this call reads
+	Secure             bool          // encrypted and authenticated (e.g. mTLS)
+	CrossesBoundary    bool          // leaves the trust/network boundary
+	MaxConcurrency     int           // in-flight request limit (bulkhead); 0 means unbounded
+	Async              bool          // fire-and-forget, e.g. published to a queue
+	InTransaction      bool          // made while the caller holds a database transaction open
+	IgnoresRetryAfter  bool          // declared not to honor Retry-After or other backpressure signals
+	Unobservable       bool          // declared neither traced nor metered
+	CallProbability    float64       // chance the source makes the call per request; 0 means always
+	FailureProbability float64       // chance one attempt fails and is retried; 0 if undeclared
+}
+
+// Node carries the per-service attributes rules may need. Unknown services
+// are represented by the zero value (apart from Name).
+type Node struct {
+	Name                   string
+	Namespace              string        // owning team or domain; empty if undeclared
+	Tier                   string        // architectural tier, e.g. "edge", "core", "data"
+	Replicas               int           // declared instance count; 0 if unknown
+	MinProcessingTime      time.Duration // known floor on request handling time; 0 if unknown
+	AggregateTimeout       time.Duration // overall deadline when fanning out; 0 if none
+	Endpoints              []string      // exposed operations, "[METHOD ]/path"; nil if undeclared
+	FixedCapacity          bool          // declared not to autoscale
+	Capacity               int           // requests per second it can serve; 0 if unknown
+	DeterministicErrors    bool          // its errors are not transient, so retries cannot succeed
+	SupportsIdempotencyKey bool          // its mutating endpoints deduplicate requests by idempotency key
 }
 
 // CallGraph is the minimal interface that rules need to inspect a service
@@ -22,7 +69,12 @@ type Edge struct {
 type CallGraph interface {
 	AllEdges() []Edge
 	OutEdges(node string) []Edge
-	Paths() [][]Edge
+	InEdges(node string) []Edge
+	Node(name string) Node
+	// WalkPaths calls fn with each root-to-leaf path, without materializing
+	// them all, and stops as soon as fn returns false. The slice passed to
+	// fn is reused; copy it to keep it past the call.
+	WalkPaths(fn func(path []Edge) bool)
 }
 
 // Violation describes a single anti-pattern finding.
@@ -52,6 +104,85 @@ func pathNodes(edges []Edge) []string {
 	return nodes
 }
 
+// countCalls returns how many distinct caller->callee pairs in cg have an
+// edge matching match. Path rules that report each call once compare it with
+// the calls reported so far to stop walking paths early.
+func countCalls(cg CallGraph, match func(Edge) bool) int {
+	calls := make(map[[2]string]bool)
+	for _, e := range cg.AllEdges() {
+		if match(e) {
+			calls[[2]string{e.Source, e.Target}] = true
+		}
+	}
+	return len(calls)
+}
+
+// retryBudget is the worst-case time an edge can spend before giving up:
+// every attempt runs to its timeout, with exponential backoff waits
+// (BackoffBase, 2×, 4×, ...) between attempts.
+func retryBudget(e Edge) time.Duration {
+	return attemptsTime(e.Timeout, e.BackoffBase, e.MaxRetries)
+}
+
+// maxDuration is where duration arithmetic in the rules saturates.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// attemptsTime is the time 1+retries attempts taking attempt each spend,
+// with exponential backoff waits (base, 2×, 4×, ...) between them. It
+// saturates at maxDuration rather than overflowing on large retry counts.
+func attemptsTime(attempt, base time.Duration, retries int) time.Duration {
+	total := maxDuration
+	if attempt <= 0 || int64(retries) <= int64(maxDuration/attempt)-1 {
+		total = attempt * time.Duration(1+retries)
+	}
+	wait := base
+	for i := 0; i < retries && wait > 0 && total < maxDuration; i++ {
+		total = addDuration(total, wait)
+		wait = addDuration(wait, wait)
+	}
+	return total
+}
+
+// addDuration returns a+b for non-negative a and b, saturating at
+// maxDuration.
+func addDuration(a, b time.Duration) time.Duration {
+	if a > maxDuration-b {
+		return maxDuration
+	}
+	return a + b
+}
+
+// ToCallGraph copies cg into a graph.CallGraph for the graph package's
+// whole-topology computations. It is the one conversion between the two
+// representations: rules and the CLI both go through it, so they always see
+// the same calls.
+func ToCallGraph(cg CallGraph) *graph.CallGraph {
+	g := graph.NewCallGraph()
+	for _, e := range cg.AllEdges() {
+		for _, name := range []string{e.Source, e.Target} {
+			n := cg.Node(name)
+			g.AddNode(graph.Node{Name: name, Namespace: n.Namespace, Tier: n.Tier, Replicas: n.Replicas})
+		}
+		g.AddEdge(graph.Edge{
+			From:               e.Source,
+			To:                 e.Target,
+			Timeout:            e.Timeout,
+			MaxRetries:         e.MaxRetries,
+			HasCircuitBreaker:  e.HasCircuitBreaker,
+			Idempotent:         e.Idempotent,
+			MaxConcurrency:     e.MaxConcurrency,
+			CallProbability:    e.CallProbability,
+			FailureProbability: e.FailureProbability,
+			Backoff: graph.BackoffConfig{
+				InitialInterval: e.BackoffBase,
+				Multiplier:      2,
+				HasJitter:       e.Jitter,
+			},
+		})
+	}
+	return g
+}
+
 // ---------------------------------------------------------------------------
 // Rule 1: TimeoutInversionRule
 // ---------------------------------------------------------------------------
@@ -85,7 +216,10 @@ func (r *TimeoutInversionRule) Check(graph CallGraph) []Violation {
 // ---------------------------------------------------------------------------
 
 // RetryAmplificationRule checks that the multiplicative retry factor along
-// any root-to-leaf path does not exceed configurable thresholds.
+// any root-to-leaf path does not exceed configurable thresholds. Each
+// violation names the edge with the most attempts (1+MaxRetries) as the
+// primary contributor, the first place to cut retries; ties go to the edge
+// nearest the root.
 type RetryAmplificationRule struct {
 	ErrorThreshold   int // product > this → error   (default 10)
 	WarningThreshold int // product > this → warning  (default 5)
@@ -102,27 +236,35 @@ func (r *RetryAmplificationRule) Check(graph CallGraph) []Violation {
 	}
 
 	var violations []Violation
-	for _, path := range graph.Paths() {
+	graph.WalkPaths(func(path []Edge) bool {
 		product := 1
+		var primary Edge
 		for _, e := range path {
 			product *= (1 + e.MaxRetries)
+			if primary.Source == "" || e.MaxRetries > primary.MaxRetries {
+				primary = e
+			}
 		}
+		sev, threshold := "", 0
 		if product > errT {
-			violations = append(violations, Violation{
-				Rule:     "retry-amplification",
-				Severity: "error",
-				Path:     pathNodes(path),
-				Message:  fmt.Sprintf("retry amplification factor %d exceeds error threshold %d", product, errT),
-			})
+			sev, threshold = "error", errT
 		} else if product > warnT {
-			violations = append(violations, Violation{
-				Rule:     "retry-amplification",
-				Severity: "warning",
-				Path:     pathNodes(path),
-				Message:  fmt.Sprintf("retry amplification factor %d exceeds warning threshold %d", product, warnT),
-			})
+			sev, threshold = "warning", warnT
 		}
-	}
+		if sev == "" {
+			return true
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-amplification",
+			Severity: sev,
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"retry amplification factor %d exceeds %s threshold %d; primary contributor %s->%s (%d attempts)",
+				product, sev, threshold, primary.Source, primary.Target, 1+primary.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", primary.Source, primary.Target),
+		})
+		return true
+	})
 	return violations
 }
 
@@ -220,7 +362,7 @@ func (r *EndToEndTimeoutExceedRule) Check(graph CallGraph) []Violation {
 		return nil
 	}
 	var violations []Violation
-	for _, path := range graph.Paths() {
+	graph.WalkPaths(func(path []Edge) bool {
 		var worstCase time.Duration
 		for _, e := range path {
 			worstCase += e.Timeout * time.Duration(1+e.MaxRetries)
@@ -235,6 +377,2464 @@ func (r *EndToEndTimeoutExceedRule) Check(graph CallGraph) []Violation {
 					worstCase, r.EntryTimeout),
 			})
 		}
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 7: ReadAfterWriteRetryRule
+// ---------------------------------------------------------------------------
+
+// ReadAfterWriteRetryRule approximates read-after-write hazards: a path that
+// performs a non-idempotent write and later retries an idempotent read of
+// related data may observe a partially applied write. A read is related to a
+// write when it calls the same target or lists the write's target in
+// ReadsFrom.
+type ReadAfterWriteRetryRule struct{}
+
+func (r *ReadAfterWriteRetryRule) Check(graph CallGraph) []Violation {
+	type edgeKey struct{ write, read string }
+	seen := make(map[edgeKey]bool)
+	var violations []Violation
+	graph.WalkPaths(func(path []Edge) bool {
+		for i, w := range path {
+			if w.Idempotent {
+				continue
+			}
+			for _, rd := range path[i+1:] {
+				if !rd.Idempotent || rd.MaxRetries == 0 || !readsFrom(rd, w.Target) {
+					continue
+				}
+				k := edgeKey{w.Source + "->" + w.Target, rd.Source + "->" + rd.Target}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				violations = append(violations, Violation{
+					Rule:     "read-after-write-retry",
+					Severity: "warning",
+					Path:     pathNodes(path),
+					Message: fmt.Sprintf(
+						"%s->%s retries %d times reading data written by %s->%s (may observe a partial write)",
+						rd.Source, rd.Target, rd.MaxRetries, w.Source, w.Target),
+					SourceHint: fmt.Sprintf("edge %s->%s", rd.Source, rd.Target),
+				})
+			}
+		}
+		return true
+	})
+	return violations
+}
+
+// readsFrom reports whether e reads data owned by service.
+func readsFrom(e Edge, service string) bool {
+	if e.Target == service {
+		return true
+	}
+	for _, s := range e.ReadsFrom {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------------------------------------------------------
+// Rule 8: UniformConfigRule
+// ---------------------------------------------------------------------------
+
+// UniformConfigRule is advisory: it notes clusters of circuit-breaker edges
+// that share byte-identical resilience config (timeout, retries, backoff,
+// jitter, breaker failure threshold and open timeout), which usually means
+// the values were copy-pasted rather than tuned per dependency. Edges
+// without a circuit breaker are ignored.
+type UniformConfigRule struct {
+	MinClusterSize int // cluster size that triggers a note (default 5)
+}
+
+func (r *UniformConfigRule) Check(graph CallGraph) []Violation {
+	minSize := r.MinClusterSize
+	if minSize == 0 {
+		minSize = 5
+	}
+	type configKey struct {
+		timeout         time.Duration
+		retries         int
+		backoff, jitter bool
+		threshold       int
+		openTimeout     time.Duration
+	}
+	clusters := make(map[configKey][]string)
+	var order []configKey
+	for _, e := range graph.AllEdges() {
+		if !e.HasCircuitBreaker {
+			continue
+		}
+		k := configKey{e.Timeout, e.MaxRetries, e.HasBackoff, e.Jitter, e.CBFailureThreshold, e.CBOpenTimeout}
+		if _, ok := clusters[k]; !ok {
+			order = append(order, k)
+		}
+		clusters[k] = append(clusters[k], e.Source+"->"+e.Target)
+	}
+
+	var violations []Violation
+	for _, k := range order {
+		edges := clusters[k]
+		if len(edges) < minSize {
+			continue
+		}
+		sort.Strings(edges)
+		threshold, openTimeout := "undeclared", "undeclared"
+		if k.threshold > 0 {
+			threshold = strconv.Itoa(k.threshold)
+		}
+		if k.openTimeout > 0 {
+			openTimeout = k.openTimeout.String()
+		}
+		violations = append(violations, Violation{
+			Rule:     "uniform-config",
+			Severity: "info",
+			Message: fmt.Sprintf(
+				"%d circuit-breaker edges share identical resilience config (timeout %v, retries %d, backoff %v, jitter %v, breaker threshold %s, open timeout %s); likely copy-pasted, review per dependency: %s",
+				len(edges), k.timeout, k.retries, k.backoff, k.jitter, threshold, openTimeout, strings.Join(edges, ", ")),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 9: BidirectionalTimeoutRule
+// ---------------------------------------------------------------------------
+
+// BidirectionalTimeoutRule warns when services call each other (A->B and
+// B->A, typically a request and its callback) with wildly different
+// timeouts: the longer-lived direction can outlive the request context that
+// triggered it.
+type BidirectionalTimeoutRule struct {
+	MaxRatio float64 // longer/shorter timeout ratio that triggers (default 10)
+}
+
+func (r *BidirectionalTimeoutRule) Check(graph CallGraph) []Violation {
+	maxRatio := r.MaxRatio
+	if maxRatio == 0 {
+		maxRatio = 10
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Source >= e.Target || e.Timeout == 0 {
+			continue // visit each unordered pair once
+		}
+		for _, back := range graph.InEdges(e.Source) {
+			if back.Source != e.Target || back.Timeout == 0 {
+				continue
+			}
+			short, long := e, back
+			if short.Timeout > long.Timeout {
+				short, long = long, short
+			}
+			ratio := float64(long.Timeout) / float64(short.Timeout)
+			if ratio < maxRatio {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "bidirectional-timeout-asymmetry",
+				Severity: "warning",
+				Path:     []string{e.Source, e.Target, e.Source},
+				Message: fmt.Sprintf(
+					"%s->%s timeout %v but %s->%s timeout %v (%.0fx asymmetry; the longer call can outlive the request)",
+					short.Source, short.Target, short.Timeout, long.Source, long.Target, long.Timeout, ratio),
+				SourceHint: fmt.Sprintf("edge %s->%s", long.Source, long.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 10: RetriesExceedReplicasRule
+// ---------------------------------------------------------------------------
+
+// RetriesExceedReplicasRule warns when an edge makes more attempts
+// (1 + MaxRetries) than the target has replicas: the extra attempts can only
+// land on instances that already failed. Targets without a declared replica
+// count are skipped.
+type RetriesExceedReplicasRule struct{}
+
+func (r *RetriesExceedReplicasRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		replicas := graph.Node(e.Target).Replicas
+		if replicas == 0 || e.MaxRetries+1 <= replicas {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "retries-exceed-replicas",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s makes %d attempts but %s has only %d replica(s); extra retries hit already-failed instances",
+				e.Source, e.Target, e.MaxRetries+1, e.Target, replicas),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 11: OrphanedRetryRule
+// ---------------------------------------------------------------------------
+
+// OrphanedRetryRule flags retried edges that can keep retrying after the
+// caller's own caller has already timed out: once the upstream deadline
+// passes the request is dead, so the remaining attempts are wasted load (and
+// possibly orphaned writes). The downstream budget includes backoff waits.
+// Calls whose attempts alone outlast the caller are left to
+// EffectiveTimeoutInversionRule, which runs by default.
+type OrphanedRetryRule struct{}
+
+func (r *OrphanedRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, d := range graph.AllEdges() {
+		if d.MaxRetries == 0 || d.Timeout == 0 {
+			continue
+		}
+		budget := retryBudget(d)
+		for _, u := range graph.InEdges(d.Source) {
+			if u.Timeout == 0 || budget <= u.Timeout || effectivelyInverted(u, d) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "orphaned-retry",
+				Severity: "warning",
+				Path:     []string{u.Source, d.Source, d.Target},
+				Message: fmt.Sprintf(
+					"%s->%s can spend %v retrying but %s->%s gives up after %v; later attempts serve a cancelled request",
+					d.Source, d.Target, budget, u.Source, u.Target, u.Timeout),
+				SourceHint: fmt.Sprintf("edge %s->%s", d.Source, d.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 12: TierViolationRule
+// ---------------------------------------------------------------------------
+
+// TierPolicy describes which tiers may call which. Calls within a tier are
+// always allowed.
+type TierPolicy struct {
+	Allow                 map[string][]string // caller tier -> callable tiers
+	RequireCircuitBreaker []string            // tiers whose inbound cross-tier calls need a circuit breaker
+}
+
+// DefaultTierPolicy is the classic three-tier layering: edge services call
+// core services, core services call data services, and every call into the
+// data tier is protected by a circuit breaker.
+var DefaultTierPolicy = TierPolicy{
+	Allow: map[string][]string{
+		"edge": {"core"},
+		"core": {"data"},
+	},
+	RequireCircuitBreaker: []string{"data"},
+}
+
+// TierViolationRule enforces architectural constraints between service
+// tiers. Edges where either side has no declared tier are skipped. A zero
+// Policy means DefaultTierPolicy.
+type TierViolationRule struct {
+	Policy TierPolicy
+}
+
+func (r *TierViolationRule) Check(graph CallGraph) []Violation {
+	policy := r.Policy
+	if policy.Allow == nil && policy.RequireCircuitBreaker == nil {
+		policy = DefaultTierPolicy
+	}
+	needsCB := make(map[string]bool)
+	for _, t := range policy.RequireCircuitBreaker {
+		needsCB[t] = true
+	}
+
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		from, to := graph.Node(e.Source).Tier, graph.Node(e.Target).Tier
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		if !containsString(policy.Allow[from], to) {
+			violations = append(violations, Violation{
+				Rule:       "tier-violation",
+				Severity:   "error",
+				Path:       []string{e.Source, e.Target},
+				Message:    fmt.Sprintf("%s (%s tier) must not call %s (%s tier) directly", e.Source, from, e.Target, to),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+			continue
+		}
+		if needsCB[to] && !e.HasCircuitBreaker {
+			violations = append(violations, Violation{
+				Rule:       "tier-violation",
+				Severity:   "warning",
+				Path:       []string{e.Source, e.Target},
+				Message:    fmt.Sprintf("%s->%s crosses into the %s tier without a circuit breaker", e.Source, e.Target, to),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------------------------------------------------------
+// Rule 13: IdempotentMethodMismatchRule
+// ---------------------------------------------------------------------------
+
+// IdempotentMethodMismatchRule warns when a call is explicitly declared
+// idempotent but uses a method that is not idempotent by default (POST,
+// PATCH). That is sometimes deliberate (an idempotency key) but usually a
+// mistake that silences NonIdempotentRetryRule. Methods outside Methods,
+// such as PUT or DELETE with an idempotency key, are accepted.
+type IdempotentMethodMismatchRule struct {
+	Methods []string // methods that need scrutiny when marked idempotent (default POST, PATCH)
+}
+
+func (r *IdempotentMethodMismatchRule) Check(graph CallGraph) []Violation {
+	methods := r.Methods
+	if methods == nil {
+		methods = []string{"POST", "PATCH"}
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.DeclaredIdempotent || !containsString(methods, strings.ToUpper(e.Method)) {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "idempotent-method-mismatch",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s is marked idempotent but uses %s, which is not idempotent by default; confirm an idempotency key is used",
+				e.Source, e.Target, e.Method),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 14: SlowDownstreamRetryRule
+// ---------------------------------------------------------------------------
+
+// SlowDownstreamRetryRule flags retried edges whose target makes a single
+// unretried call with a long timeout. Each upstream attempt can then wait
+// the full downstream timeout, so the slow hop dominates the caller's
+// budget once per retry.
+type SlowDownstreamRetryRule struct {
+	LongTimeout time.Duration // downstream timeout considered long (default 30s)
+}
+
+func (r *SlowDownstreamRetryRule) Check(graph CallGraph) []Violation {
+	long := r.LongTimeout
+	if long == 0 {
+		long = 30 * time.Second
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 {
+			continue
+		}
+		for _, d := range graph.OutEdges(e.Target) {
+			if d.MaxRetries > 0 || d.Timeout < long {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "retry-over-slow-downstream",
+				Severity: "warning",
+				Path:     []string{e.Source, e.Target, d.Target},
+				Message: fmt.Sprintf(
+					"%s->%s retries %d times but %s->%s waits up to %v per attempt; each retry can wait %v",
+					e.Source, e.Target, e.MaxRetries, d.Source, d.Target, d.Timeout, d.Timeout),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 15: BackendRequestCapRule
+// ---------------------------------------------------------------------------
+
+// BackendRequestCapRule errors when a single root request can generate more
+// than Cap requests to any one backend once fan-out and retries are
+// multiplied out (see graph.CallGraph.MaxBackendRequests). This is the
+// "retry storm magnitude" for that backend.
+type BackendRequestCapRule struct {
+	Cap int // maximum worst-case requests per backend (default 50)
+}
+
+func (r *BackendRequestCapRule) Check(cg CallGraph) []Violation {
+	limit := r.Cap
+	if limit == 0 {
+		limit = 50
+	}
+	counts := ToCallGraph(cg).MaxBackendRequests()
+	backends := make([]string, 0, len(counts))
+	for svc := range counts {
+		backends = append(backends, svc)
+	}
+	sort.Strings(backends)
+
+	var violations []Violation
+	for _, svc := range backends {
+		if counts[svc] <= limit {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "backend-request-cap",
+			Severity: "error",
+			Path:     []string{svc},
+			Message: fmt.Sprintf(
+				"one root request can send up to %d requests to %s (cap %d)",
+				counts[svc], svc, limit),
+			SourceHint: fmt.Sprintf("service %s", svc),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 16: RetryOnHealthCheckRule
+// ---------------------------------------------------------------------------
+
+// RetryOnHealthCheckRule warns on retries for health-check edges
+// (Kind == "health"). Retrying a probe masks an unhealthy instance and adds
+// load to a service that is already degraded.
+type RetryOnHealthCheckRule struct{}
+
+func (r *RetryOnHealthCheckRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Kind != "health" || e.MaxRetries == 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-on-health-check",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s is a health check but retries %d times (masks unhealthy instances, adds load)",
+				e.Source, e.Target, e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 17: JitterWithoutBackoffRule
+// ---------------------------------------------------------------------------
+
+// JitterWithoutBackoffRule notes edges that configure jitter but no backoff,
+// the mirror image of BackoffWithoutJitterRule. Jitter has nothing to spread
+// without a backoff, so the setting usually signals config drift.
+type JitterWithoutBackoffRule struct{}
+
+func (r *JitterWithoutBackoffRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Jitter && !e.HasBackoff {
+			violations = append(violations, Violation{
+				Rule:     "jitter-no-backoff",
+				Severity: "info",
+				Path:     []string{e.Source, e.Target},
+				Message: fmt.Sprintf(
+					"%s->%s has jitter but no backoff (jitter has no effect)",
+					e.Source, e.Target),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 18: EntryTimeoutMissingRule
+// ---------------------------------------------------------------------------
+
+// EntryTimeoutMissingRule errors on outbound calls of the entry service that
+// have no timeout. Without one the whole chain below the entry is unbounded
+// however well the downstream hops are configured. Entry names the entry
+// service; when empty, every root of the graph is treated as an entry. A
+// non-zero EntryTimeout declares an overall budget for the entry, which
+// bounds the chain and silences the rule.
+type EntryTimeoutMissingRule struct {
+	Entry        string
+	EntryTimeout time.Duration
+}
+
+func (r *EntryTimeoutMissingRule) Check(graph CallGraph) []Violation {
+	if r.EntryTimeout > 0 {
+		return nil
+	}
+	entries := []string{r.Entry}
+	if r.Entry == "" {
+		entries = ToCallGraph(graph).Roots()
+	}
+	var violations []Violation
+	for _, entry := range entries {
+		for _, e := range graph.OutEdges(entry) {
+			if e.Timeout > 0 {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "entry-timeout-missing",
+				Severity: "error",
+				Path:     []string{e.Source, e.Target},
+				Message: fmt.Sprintf(
+					"entry call %s->%s has no timeout (the whole chain below it is unbounded)",
+					e.Source, e.Target),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 19: SinglePointOfFailureRule
+// ---------------------------------------------------------------------------
+
+// SinglePointOfFailureRule warns on articulation points of the topology
+// (see graph.CallGraph.ArticulationPoints) that are not redundant, i.e.
+// declare a single replica. Losing such a service cuts every caller on one
+// side off from every service on the other, a structural risk no per-edge
+// rule can see. As in RetriesExceedReplicasRule, services without a declared
+// replica count are skipped.
+type SinglePointOfFailureRule struct{}
+
+func (r *SinglePointOfFailureRule) Check(cg CallGraph) []Violation {
+	var violations []Violation
+	for _, svc := range ToCallGraph(cg).ArticulationPoints() {
+		if cg.Node(svc).Replicas != 1 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "single-point-of-failure",
+			Severity: "warning",
+			Path:     []string{svc},
+			Message: fmt.Sprintf(
+				"%s is the only link between parts of the topology but runs a single replica",
+				svc),
+			SourceHint: fmt.Sprintf("service %s", svc),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 20: InsecureBoundaryCallRule
+// ---------------------------------------------------------------------------
+
+// InsecureBoundaryCallRule errors on edges that cross a trust or network
+// boundary without being secure. Boundary calls are both the flakiest and
+// the most exposed, so they must be encrypted and authenticated.
+type InsecureBoundaryCallRule struct{}
+
+func (r *InsecureBoundaryCallRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.CrossesBoundary || e.Secure {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "insecure-boundary-call",
+			Severity: "error",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s crosses a trust boundary but is not secure (encrypt and authenticate it)",
+				e.Source, e.Target),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 21: ZeroBackoffRetryRule
+// ---------------------------------------------------------------------------
+
+// ZeroBackoffRetryRule warns on retried edges whose BackoffBase is zero
+// (unset or explicitly 0): every retry fires immediately and hits a failing
+// downstream hardest, whether or not jitter is configured.
+type ZeroBackoffRetryRule struct{}
+
+func (r *ZeroBackoffRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 || e.BackoffBase > 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "zero-backoff-retry",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times with no backoff delay (set a backoff base of at least 100ms)",
+				e.Source, e.Target, e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 22: UnboundedRetryRule
+// ---------------------------------------------------------------------------
+
+// UnboundedRetryRule errors on edges that retry a call with no timeout.
+// Each attempt can hang forever and the retries multiply that, which is
+// qualitatively worse than a missing timeout or an aggressive retry alone.
+type UnboundedRetryRule struct{}
+
+func (r *UnboundedRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 || e.Timeout > 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "unbounded-retry",
+			Severity: "error",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times but has no timeout (each attempt can hang indefinitely)",
+				e.Source, e.Target, e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 23: NonMonotonicBudgetRule
+// ---------------------------------------------------------------------------
+
+// NonMonotonicBudgetRule walks each path and flags the first hop whose
+// timeout is not strictly less than the timeout of the hop before it: the
+// downstream call can use up (or outlast) the whole remaining upstream
+// budget. Hops without a timeout are skipped. A hop shared by several paths
+// is reported once.
+type NonMonotonicBudgetRule struct{}
+
+func (r *NonMonotonicBudgetRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	seen := make(map[[4]string]bool)
+	graph.WalkPaths(func(path []Edge) bool {
+		for i := 1; i < len(path); i++ {
+			up, down := path[i-1], path[i]
+			if up.Timeout == 0 || down.Timeout == 0 || down.Timeout < up.Timeout {
+				continue
+			}
+			key := [4]string{up.Source, up.Target, down.Source, down.Target}
+			if !seen[key] {
+				seen[key] = true
+				violations = append(violations, Violation{
+					Rule:     "non-monotonic-budget",
+					Severity: "warning",
+					Path:     pathNodes(path[:i+1]),
+					Message: fmt.Sprintf(
+						"timeout budget stops shrinking at hop %d: %s->%s %v is not below %s->%s %v",
+						i+1, down.Source, down.Target, down.Timeout, up.Source, up.Target, up.Timeout),
+					SourceHint: fmt.Sprintf("edge %s->%s", down.Source, down.Target),
+				})
+			}
+			break
+		}
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 24: MissingBulkheadRule
+// ---------------------------------------------------------------------------
+
+// MissingBulkheadRule warns on retried edges without a concurrency limit.
+// During a downstream slowdown the retries pile up in flight and can exhaust
+// the caller's connection pool; a bulkhead bounds that.
+type MissingBulkheadRule struct{}
+
+func (r *MissingBulkheadRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 || e.MaxConcurrency > 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "missing-bulkhead",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times with no concurrency limit (add a bounded concurrency limiter/bulkhead)",
+				e.Source, e.Target, e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 25: AsyncRetryMismatchRule
+// ---------------------------------------------------------------------------
+
+// AsyncRetryMismatchRule notes retries configured on async (fire-and-forget)
+// edges. Synchronous retry semantics do not apply behind a queue, so a retry
+// count there is usually a mistake or duplicates the queue's own redelivery.
+type AsyncRetryMismatchRule struct{}
+
+func (r *AsyncRetryMismatchRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.Async || e.MaxRetries == 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "async-retry-mismatch",
+			Severity: "info",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s is async but configures %d retries (redelivery is the queue's job)",
+				e.Source, e.Target, e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 26: ScatterGatherTimeoutRule
+// ---------------------------------------------------------------------------
+
+// ScatterGatherTimeoutRule warns on fan-out nodes (two or more out-edges
+// with a timeout) whose sibling timeouts diverge by MaxRatio or more. When
+// the node awaits all of its downstreams, the slowest sibling sets the
+// effective latency and the others' tight timeouts buy nothing.
+type ScatterGatherTimeoutRule struct {
+	MaxRatio float64 // longest/shortest sibling timeout ratio that triggers (default 10)
+}
+
+func (r *ScatterGatherTimeoutRule) Check(graph CallGraph) []Violation {
+	maxRatio := r.MaxRatio
+	if maxRatio == 0 {
+		maxRatio = 10
+	}
+	var sources []string
+	seen := make(map[string]bool)
+	for _, e := range graph.AllEdges() {
+		if !seen[e.Source] {
+			seen[e.Source] = true
+			sources = append(sources, e.Source)
+		}
+	}
+	sort.Strings(sources)
+
+	var violations []Violation
+	for _, svc := range sources {
+		var shortest, longest Edge
+		siblings := 0
+		for _, e := range graph.OutEdges(svc) {
+			if e.Timeout == 0 {
+				continue
+			}
+			if siblings == 0 || e.Timeout < shortest.Timeout {
+				shortest = e
+			}
+			if siblings == 0 || e.Timeout > longest.Timeout {
+				longest = e
+			}
+			siblings++
+		}
+		if siblings < 2 {
+			continue
+		}
+		ratio := float64(longest.Timeout) / float64(shortest.Timeout)
+		if ratio < maxRatio {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "scatter-gather-timeout",
+			Severity: "warning",
+			Path:     []string{svc, longest.Target},
+			Message: fmt.Sprintf(
+				"%s fans out to %d services but %s->%s timeout %v is %.0fx %s->%s %v (the slowest sibling dominates)",
+				svc, siblings, svc, longest.Target, longest.Timeout, ratio, svc, shortest.Target, shortest.Timeout),
+			SourceHint: fmt.Sprintf("edge %s->%s", svc, longest.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 27: TimeoutBelowMinProcessingRule
+// ---------------------------------------------------------------------------
+
+// TimeoutBelowMinProcessingRule errors when an edge's timeout is below the
+// target's declared minimum processing time: the call can never succeed.
+// Targets without a declared minimum are skipped.
+type TimeoutBelowMinProcessingRule struct{}
+
+func (r *TimeoutBelowMinProcessingRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		floor := graph.Node(e.Target).MinProcessingTime
+		if e.Timeout == 0 || floor == 0 || e.Timeout >= floor {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "timeout-below-min-processing",
+			Severity: "error",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s timeout %v is below %s's minimum processing time %v (the call can never succeed)",
+				e.Source, e.Target, e.Timeout, e.Target, floor),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 28: RepeatedServiceOnPathRule
+// ---------------------------------------------------------------------------
+
+// RepeatedServiceOnPathRule warns on services where the call chains of a
+// single root request reconverge: services called by two or more services
+// the request reaches, e.g. the bottom of a diamond. Each path on its own
+// is acyclic, so per-path rules miss that one logical request loads the
+// service several times. Services below a reconvergence point are loaded
+// repeatedly too, but only because of it, so only the service where the
+// chains join is reported. Each such service is reported once, for the
+// root reaching it through the most chains. Calls that close a cycle are
+// ignored.
+type RepeatedServiceOnPathRule struct{}
+
+func (r *RepeatedServiceOnPathRule) Check(graph CallGraph) []Violation {
+	type join struct {
+		root    string
+		chains  int
+		callers []string
+	}
+	best := make(map[string]join)
+	for _, root := range ToCallGraph(graph).Roots() {
+		chains, callers := reconvergence(graph, root)
+		for svc, from := range callers {
+			if len(from) < 2 {
+				continue
+			}
+			cur, ok := best[svc]
+			if n := chains[svc]; !ok || n > cur.chains || n == cur.chains && root < cur.root {
+				best[svc] = join{root: root, chains: n, callers: from}
+			}
+		}
+	}
+	services := make([]string, 0, len(best))
+	for svc := range best {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	var violations []Violation
+	for _, svc := range services {
+		j := best[svc]
+		violations = append(violations, Violation{
+			Rule:     "repeated-service-on-path",
+			Severity: "warning",
+			Path:     []string{svc},
+			Message: fmt.Sprintf(
+				"one request from %s reaches %s through %d distinct call chains joining from %s (the service is loaded repeatedly)",
+				j.root, svc, j.chains, strings.Join(j.callers, ", ")),
+			SourceHint: fmt.Sprintf("service %s", svc),
+		})
+	}
+	return violations
+}
+
+// reconvergence returns, for each service a request to root reaches, the
+// number of distinct call chains reaching it (saturating at math.MaxInt)
+// and its callers among the services reached, in sorted order. Calls that
+// close a cycle are left out, so the chains are those of the acyclic part
+// of the graph below root; counting them takes one pass rather than a walk
+// of every path.
+func reconvergence(graph CallGraph, root string) (map[string]int, map[string][]string) {
+	onStack, done := make(map[string]bool), make(map[string]bool)
+	closesCycle := make(map[[2]string]bool)
+	var order []string // reverse topological order of the acyclic part
+	var visit func(node string)
+	visit = func(node string) {
+		onStack[node] = true
+		for _, e := range graph.OutEdges(node) {
+			switch {
+			case onStack[e.Target]:
+				closesCycle[[2]string{node, e.Target}] = true
+			case !done[e.Target]:
+				visit(e.Target)
+			}
+		}
+		onStack[node], done[node] = false, true
+		order = append(order, node)
+	}
+	visit(root)
+
+	chains := map[string]int{root: 1}
+	callers := make(map[string][]string)
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		called := make(map[string]bool)
+		for _, e := range graph.OutEdges(node) {
+			if closesCycle[[2]string{node, e.Target}] || called[e.Target] {
+				continue
+			}
+			called[e.Target] = true
+			chains[e.Target] = saturatingAdd(chains[e.Target], chains[node])
+			callers[e.Target] = append(callers[e.Target], node)
+		}
+	}
+	for _, from := range callers {
+		sort.Strings(from)
+	}
+	return chains, callers
+}
+
+// saturatingAdd returns a+b for non-negative a and b, or math.MaxInt when
+// the sum would overflow.
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// ---------------------------------------------------------------------------
+// Rule 29: MissingAggregateDeadlineRule
+// ---------------------------------------------------------------------------
+
+// MissingAggregateDeadlineRule warns on fan-out services (calls to two or
+// more distinct services) that declare no AggregateTimeout while one of
+// their calls has a timeout of LargeTimeout or more. Relying on per-child
+// timeouts alone, a single slow child still blocks the aggregate for its
+// full timeout.
+type MissingAggregateDeadlineRule struct {
+	LargeTimeout time.Duration // child timeout that makes a deadline necessary (default 5s)
+}
+
+func (r *MissingAggregateDeadlineRule) Check(graph CallGraph) []Violation {
+	large := r.LargeTimeout
+	if large == 0 {
+		large = 5 * time.Second
+	}
+	var sources []string
+	seen := make(map[string]bool)
+	for _, e := range graph.AllEdges() {
+		if !seen[e.Source] {
+			seen[e.Source] = true
+			sources = append(sources, e.Source)
+		}
+	}
+	sort.Strings(sources)
+
+	var violations []Violation
+	for _, svc := range sources {
+		if graph.Node(svc).AggregateTimeout > 0 {
+			continue
+		}
+		targets := make(map[string]bool)
+		var slowest Edge
+		for _, e := range graph.OutEdges(svc) {
+			targets[e.Target] = true
+			if e.Timeout > slowest.Timeout {
+				slowest = e
+			}
+		}
+		if len(targets) < 2 || slowest.Timeout < large {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "missing-aggregate-deadline",
+			Severity: "warning",
+			Path:     []string{svc},
+			Message: fmt.Sprintf(
+				"%s fans out to %d services without an aggregate_timeout; %s->%s alone can block it for %v",
+				svc, len(targets), svc, slowest.Target, slowest.Timeout),
+			SourceHint: fmt.Sprintf("service %s", svc),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 30: GlobalRequestCapRule
+// ---------------------------------------------------------------------------
+
+// GlobalRequestCapRule errors when a single request to an entry service can
+// generate more than Cap outbound requests in total across everything it
+// reaches, with every retry firing: "one user click could generate up to N
+// backend requests". Unlike BackendRequestCapRule it sums over all
+// backends, so broad fan-out with modest retries is caught too.
+type GlobalRequestCapRule struct {
+	Cap int // maximum acceptable total worst-case requests per root request (default 200)
+}
+
+func (r *GlobalRequestCapRule) Check(cg CallGraph) []Violation {
+	limit := r.Cap
+	if limit == 0 {
+		limit = 200
+	}
+	g := ToCallGraph(cg)
+	var violations []Violation
+	for _, root := range g.Roots() {
+		n := g.TotalWorstCaseRequests(root)
+		if n <= limit {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "global-request-cap",
+			Severity: "error",
+			Path:     []string{root},
+			Message: fmt.Sprintf(
+				"one request to %s can generate up to %d backend requests in the worst case (cap %d)",
+				root, n, limit),
+			SourceHint: fmt.Sprintf("service %s", root),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 31: InconsistentIdempotencyRule
+// ---------------------------------------------------------------------------
+
+// uuidSegment matches a UUID path segment.
+var uuidSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// NormalizePath reduces a request path to its shape so that calls to the
+// same logical operation compare equal: the query string and trailing
+// slash are dropped, the path is lower-cased, and parameter segments
+// ({id}, :id, numbers, UUIDs, or any segment matching one of params)
+// become "{}". For example "/Users/42/" and "/users/{id}" both normalize
+// to "/users/{}".
+func NormalizePath(path string, params ...*regexp.Regexp) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if isParamSegment(s, params) {
+			segments[i] = "{}"
+		} else {
+			segments[i] = strings.ToLower(s)
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func isParamSegment(s string, params []*regexp.Regexp) bool {
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") || strings.HasPrefix(s, ":") {
+		return true
+	}
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil || uuidSegment.MatchString(s) {
+		return true
+	}
+	for _, re := range params {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// InconsistentIdempotencyRule warns when calls to the same logical
+// operation (same method and NormalizePath endpoint) disagree on whether it
+// is idempotent, typically because two services expose the operation and
+// only one is declared idempotent. One of the declarations is a bug: either
+// retries are unsafe where the call is marked idempotent, or they are being
+// withheld needlessly. Calls without an Endpoint are ignored.
+type InconsistentIdempotencyRule struct {
+	// ParamPatterns are extra regular expressions, matched against whole
+	// path segments, for parameters NormalizePath does not recognise
+	// (e.g. `^[a-z]{2}-[A-Z]{2}$` for locales).
+	ParamPatterns []string
+}
+
+func (r *InconsistentIdempotencyRule) Check(graph CallGraph) []Violation {
+	var params []*regexp.Regexp
+	for _, p := range r.ParamPatterns {
+		if re, err := regexp.Compile(p); err == nil {
+			params = append(params, re)
+		}
+	}
+	type operation struct{ method, path string }
+	groups := make(map[operation][]Edge)
+	var ops []operation
+	for _, e := range graph.AllEdges() {
+		if e.Endpoint == "" {
+			continue
+		}
+		op := operation{strings.ToUpper(e.Method), NormalizePath(e.Endpoint, params...)}
+		if _, ok := groups[op]; !ok {
+			ops = append(ops, op)
+		}
+		groups[op] = append(groups[op], e)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+
+	var violations []Violation
+	for _, op := range ops {
+		var yes, no *Edge
+		for i, e := range groups[op] {
+			if e.Idempotent && yes == nil {
+				yes = &groups[op][i]
+			} else if !e.Idempotent && no == nil {
+				no = &groups[op][i]
+			}
+		}
+		if yes == nil || no == nil {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "inconsistent-idempotency",
+			Severity: "warning",
+			Path:     []string{no.Source, no.Target},
+			Message: fmt.Sprintf(
+				"%s %s is idempotent on %s->%s (%s) but not on %s->%s (%s)",
+				op.method, op.path, yes.Source, yes.Target, yes.Endpoint, no.Source, no.Target, no.Endpoint),
+			SourceHint: fmt.Sprintf("edge %s->%s", no.Source, no.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 32: TimeoutMatchesProxyRule
+// ---------------------------------------------------------------------------
+
+// TimeoutMatchesProxyRule warns when a call's timeout equals, to within
+// Epsilon, a timeout of the infrastructure in front of it (load balancer,
+// reverse proxy). The two race: sometimes the app gives up first and
+// returns its own error, sometimes the proxy cuts the connection and the
+// client sees a bare 504. The app timeout should sit clearly below.
+type TimeoutMatchesProxyRule struct {
+	ProxyTimeouts []time.Duration // known infra timeouts (default 60s: nginx proxy_read_timeout, AWS ELB idle timeout)
+	Epsilon       time.Duration   // how close counts as a match (default 1s)
+}
+
+func (r *TimeoutMatchesProxyRule) Check(graph CallGraph) []Violation {
+	proxies := r.ProxyTimeouts
+	if proxies == nil {
+		proxies = []time.Duration{60 * time.Second}
+	}
+	eps := r.Epsilon
+	if eps == 0 {
+		eps = time.Second
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Timeout <= 0 {
+			continue
+		}
+		for _, p := range proxies {
+			diff := e.Timeout - p
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > eps {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "timeout-matches-proxy",
+				Severity: "warning",
+				Path:     []string{e.Source, e.Target},
+				Message: fmt.Sprintf(
+					"%s->%s timeout %v matches the %v proxy/load-balancer timeout; set it clearly below so the app, not the proxy, times out",
+					e.Source, e.Target, e.Timeout, p),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+			break
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 33: InTransactionRetryRule
+// ---------------------------------------------------------------------------
+
+// InTransactionRetryRule errors on retried calls made inside a database
+// transaction. Every attempt and backoff wait keeps the transaction's locks
+// held, so a slow dependency turns into lock contention and deadlocks in
+// the caller's database. Retry outside the transaction instead.
+type InTransactionRetryRule struct{}
+
+func (r *InTransactionRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.InTransaction || e.MaxRetries == 0 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "in-transaction-retry",
+			Severity: "error",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times inside a database transaction (locks held for up to %v)",
+				e.Source, e.Target, e.MaxRetries, retryBudget(e)),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 34: SuspiciousTimeoutMagnitudeRule
+// ---------------------------------------------------------------------------
+
+// SuspiciousTimeoutMagnitudeRule warns on synchronous calls whose timeout
+// is implausibly large or small, which usually means a unit slip: "500"
+// meant as milliseconds but read as seconds, or "3000" meant as
+// milliseconds but written with an s. Async calls and calls without a
+// timeout are skipped.
+type SuspiciousTimeoutMagnitudeRule struct {
+	Max time.Duration // largest plausible timeout (default 5m)
+	Min time.Duration // smallest plausible timeout (default 10ms)
+}
+
+func (r *SuspiciousTimeoutMagnitudeRule) Check(graph CallGraph) []Violation {
+	max, min := r.Max, r.Min
+	if max == 0 {
+		max = 5 * time.Minute
+	}
+	if min == 0 {
+		min = 10 * time.Millisecond
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Async || e.Timeout <= 0 {
+			continue
+		}
+		var why string
+		switch {
+		case e.Timeout > max:
+			why = fmt.Sprintf("above %v", max)
+		case e.Timeout < min:
+			why = fmt.Sprintf("below %v", min)
+		default:
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "suspicious-timeout-magnitude",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s timeout %v is %s for a synchronous call; check the unit",
+				e.Source, e.Target, e.Timeout, why),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 35: CircuitBreakerHalfOpenRule
+// ---------------------------------------------------------------------------
+
+// CircuitBreakerHalfOpenRule warns when a circuit breaker lets too many
+// trial requests through while half-open: a burst of probes can knock a
+// still-recovering downstream over again and restart the cascade. The limit
+// is a tenth of the call's MaxConcurrency (at least 1) when that is
+// declared, since it approximates normal capacity, and MaxRequests
+// otherwise. With RequireDeclared, breakers that leave the probe count
+// unset (and so depend on a library default) are reported too.
+type CircuitBreakerHalfOpenRule struct {
+	MaxRequests     int // limit without a declared max_concurrency (default 10)
+	RequireDeclared bool
+}
+
+func (r *CircuitBreakerHalfOpenRule) Check(graph CallGraph) []Violation {
+	max := r.MaxRequests
+	if max == 0 {
+		max = 10
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.HasCircuitBreaker {
+			continue
+		}
+		limit := max
+		if e.MaxConcurrency > 0 {
+			limit = e.MaxConcurrency / 10
+			if limit < 1 {
+				limit = 1
+			}
+		}
+		var msg string
+		switch {
+		case e.CBHalfOpenRequests > limit:
+			msg = fmt.Sprintf("%s->%s circuit breaker allows %d half-open probes (limit %d); a probe burst can re-trip the recovering downstream",
+				e.Source, e.Target, e.CBHalfOpenRequests, limit)
+		case e.CBHalfOpenRequests == 0 && r.RequireDeclared:
+			msg = fmt.Sprintf("%s->%s circuit breaker does not declare cb_half_open_requests", e.Source, e.Target)
+		default:
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:       "cb-half-open-probes",
+			Severity:   "warning",
+			Path:       []string{e.Source, e.Target},
+			Message:    msg,
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 36: TimeoutHeadroomRule
+// ---------------------------------------------------------------------------
+
+// TimeoutHeadroomRule warns when a call's timeout leaves too little room
+// above its declared ProcessingTime for network transit and
+// (de)serialization: Timeout < ProcessingTime × (1 + OverheadFactor). Such
+// a call times out whenever transit is slightly slow, so it flaps. Calls
+// without a declared processing time are skipped.
+type TimeoutHeadroomRule struct {
+	OverheadFactor float64 // required headroom as a fraction of processing time (default 0.2)
+}
+
+func (r *TimeoutHeadroomRule) Check(graph CallGraph) []Violation {
+	factor := r.OverheadFactor
+	if factor == 0 {
+		factor = 0.2
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Timeout == 0 || e.ProcessingTime == 0 {
+			continue
+		}
+		need := time.Duration(float64(e.ProcessingTime) * (1 + factor))
+		if e.Timeout >= need {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "timeout-no-headroom",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s timeout %v leaves under %.0f%% headroom over processing time %v for network and serialization (want >= %v)",
+				e.Source, e.Target, e.Timeout, factor*100, e.ProcessingTime, need),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 37: BackoffLatencyInflationRule
+// ---------------------------------------------------------------------------
+
+// BackoffLatencyInflationRule estimates a path's p99 latency when its
+// retries fire and warns when it exceeds Budget. Worst-case rules assume
+// every attempt times out; this models the common bad case instead: the
+// first hop's declared P99, plus, for every retried hop, one attempt that
+// runs to its timeout and the first backoff wait before the retry
+// succeeds. A long backoff can push p99 over budget even when timeout ×
+// attempts fits. Paths whose first hop declares no P99 are skipped, as are
+// retried hops without a timeout.
+type BackoffLatencyInflationRule struct {
+	Budget time.Duration // per-path p99 budget (default 1s)
+}
+
+func (r *BackoffLatencyInflationRule) Check(graph CallGraph) []Violation {
+	budget := r.Budget
+	if budget == 0 {
+		budget = time.Second
+	}
+	var violations []Violation
+	graph.WalkPaths(func(path []Edge) bool {
+		if len(path) == 0 || path[0].P99 == 0 {
+			return true
+		}
+		estimate := path[0].P99
+		var worst Edge
+		var worstCost time.Duration
+		for _, e := range path {
+			if e.MaxRetries == 0 || e.Timeout == 0 {
+				continue
+			}
+			cost := e.Timeout + e.BackoffBase
+			estimate += cost
+			if cost > worstCost {
+				worst, worstCost = e, cost
+			}
+		}
+		if estimate <= budget {
+			return true
+		}
+		hint := ""
+		if worstCost > 0 {
+			hint = fmt.Sprintf("edge %s->%s", worst.Source, worst.Target)
+		}
+		violations = append(violations, Violation{
+			Rule:     "backoff-latency-inflation",
+			Severity: "warning",
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"estimated p99 with one retry per retried hop is %v (p99 %v plus slow attempts and backoff), over the %v budget",
+				estimate, path[0].P99, budget),
+			SourceHint: hint,
+		})
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 38: CircuitBreakerOpenWindowRule
+// ---------------------------------------------------------------------------
+
+// CircuitBreakerOpenWindowRule warns on circuit breakers whose open window
+// (CBOpenTimeout) is longer than the end-to-end entry timeout. Every request
+// that arrives while the breaker is open fails fast, and the breaker only
+// re-probes after many whole request budgets have gone by, so it cannot
+// track the health of the dependency at the pace requests need. The entry
+// timeout is EntryTimeout when set, otherwise the timeout of the first hop
+// of each path through the breaker. Breakers without a declared open
+// window are skipped.
+type CircuitBreakerOpenWindowRule struct {
+	EntryTimeout time.Duration
+}
+
+func (r *CircuitBreakerOpenWindowRule) Check(graph CallGraph) []Violation {
+	breakers := countCalls(graph, func(e Edge) bool { return e.HasCircuitBreaker && e.CBOpenTimeout > 0 })
+	if breakers == 0 {
+		return nil
+	}
+	var violations []Violation
+	seen := make(map[[2]string]bool)
+	graph.WalkPaths(func(path []Edge) bool {
+		if len(path) == 0 {
+			return true
+		}
+		entry := r.EntryTimeout
+		if entry == 0 {
+			entry = path[0].Timeout
+		}
+		if entry == 0 {
+			return true
+		}
+		for _, e := range path {
+			key := [2]string{e.Source, e.Target}
+			if !e.HasCircuitBreaker || e.CBOpenTimeout <= entry || seen[key] {
+				continue
+			}
+			seen[key] = true
+			violations = append(violations, Violation{
+				Rule:     "cb-open-window-exceeds-entry",
+				Severity: "warning",
+				Path:     []string{e.Source, e.Target},
+				Message: fmt.Sprintf(
+					"%s->%s circuit breaker stays open for %v, longer than the %v entry timeout",
+					e.Source, e.Target, e.CBOpenTimeout, entry),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+		return len(seen) < breakers
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 39: CircuitBreakerCountsPerCallRule
+// ---------------------------------------------------------------------------
+
+// Values of Edge.CBCounts.
+const (
+	CBCountsPerCall    = "per-call"
+	CBCountsPerAttempt = "per-attempt"
+)
+
+// CircuitBreakerCountsPerCallRule warns on calls whose circuit breaker
+// wraps the retries and so counts a whole call as one failure
+// (CBCounts == CBCountsPerCall) while the call retries at least MinRetries
+// times. The dependency then absorbs 1+MaxRetries attempts for every
+// failure the breaker records, so it trips far later than its threshold
+// suggests, or never. Breakers that count per attempt, or whose counting
+// is undeclared, are skipped.
+type CircuitBreakerCountsPerCallRule struct {
+	MinRetries int // retries at which per-call counting is reported (default 2)
+}
+
+func (r *CircuitBreakerCountsPerCallRule) Check(graph CallGraph) []Violation {
+	minRetries := r.MinRetries
+	if minRetries == 0 {
+		minRetries = 2
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.HasCircuitBreaker || e.CBCounts != CBCountsPerCall || e.MaxRetries < minRetries {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "cb-counts-per-call",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s circuit breaker counts per call but the call makes up to %d attempts; it sees 1 failure per %d failed attempts and may never trip",
+				e.Source, e.Target, 1+e.MaxRetries, 1+e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 40: UnknownTargetEndpointRule
+// ---------------------------------------------------------------------------
+
+// UnknownTargetEndpointRule cross-references each call's method and
+// Endpoint with the endpoints its target declares, and warns when none
+// matches: the call is misrouted or the topology is stale. Paths are
+// compared with NormalizePath; a declared endpoint without a method
+// matches any method. Calls without an Endpoint and targets that declare
+// no endpoints are skipped.
+type UnknownTargetEndpointRule struct {
+	ParamPatterns []string // as in InconsistentIdempotencyRule
+}
+
+func (r *UnknownTargetEndpointRule) Check(graph CallGraph) []Violation {
+	var params []*regexp.Regexp
+	for _, p := range r.ParamPatterns {
+		if re, err := regexp.Compile(p); err == nil {
+			params = append(params, re)
+		}
+	}
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		exposed := graph.Node(e.Target).Endpoints
+		if e.Endpoint == "" || len(exposed) == 0 {
+			continue
+		}
+		path := NormalizePath(e.Endpoint, params...)
+		matched := false
+		for _, ep := range exposed {
+			method, epPath, hasMethod := strings.Cut(strings.TrimSpace(ep), " ")
+			if !hasMethod {
+				method, epPath = "", method
+			}
+			if NormalizePath(strings.TrimSpace(epPath), params...) == path &&
+				(method == "" || strings.EqualFold(method, e.Method)) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "unknown-target-endpoint",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s calls %s %s but %s declares no matching endpoint (misrouted call or stale topology)",
+				e.Source, e.Target, strings.ToUpper(e.Method), e.Endpoint, e.Target),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 41: WriteBetweenRetriedReadsRule
+// ---------------------------------------------------------------------------
+
+// WriteBetweenRetriedReadsRule warns on paths shaped read-retry →
+// write-no-retry → read-retry: three consecutive hops where a retried
+// idempotent call leads to a single non-idempotent call that is not retried
+// itself, followed by another retried idempotent call. The write looks safe
+// because it is never retried, but a transient failure after it makes the
+// retried read above re-drive the whole sequence, and the write's side
+// effects happen again. Each such triple is reported once.
+type WriteBetweenRetriedReadsRule struct{}
+
+func (r *WriteBetweenRetriedReadsRule) Check(graph CallGraph) []Violation {
+	retriedRead := func(e Edge) bool { return e.Idempotent && e.MaxRetries > 0 }
+	var violations []Violation
+	seen := make(map[[4]string]bool)
+	graph.WalkPaths(func(path []Edge) bool {
+		for i := 0; i+2 < len(path); i++ {
+			before, write, after := path[i], path[i+1], path[i+2]
+			if !retriedRead(before) || write.Idempotent || write.MaxRetries > 0 || !retriedRead(after) {
+				continue
+			}
+			key := [4]string{before.Source, write.Source, write.Target, after.Target}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			violations = append(violations, Violation{
+				Rule:     "write-between-retried-reads",
+				Severity: "warning",
+				Path:     []string{before.Source, write.Source, write.Target, after.Target},
+				Message: fmt.Sprintf(
+					"non-idempotent %s->%s sits between retried reads %s->%s and %s->%s; a retry of %s->%s re-drives the write",
+					write.Source, write.Target, before.Source, before.Target, after.Source, after.Target,
+					before.Source, before.Target),
+				SourceHint: fmt.Sprintf("edge %s->%s", write.Source, write.Target),
+			})
+		}
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 42: RetryTailLatencyRule
+// ---------------------------------------------------------------------------
+
+// RetryTailLatencyRule is the latency counterpart of retry amplification: it
+// estimates a path's tail latency when every retried hop uses all its
+// attempts and each attempt lands at the hop's P99, and warns when that tail
+// exceeds Budget. An attempt costs the hop's P99, capped at its timeout, and
+// the backoff waits between attempts double from BackoffBase. Hops without a
+// declared P99 add nothing, and paths where no hop declares one are
+// skipped, as are paths whose tail is over budget without any retry.
+type RetryTailLatencyRule struct {
+	Budget time.Duration // per-path p99 budget (default 1s)
+}
+
+func (r *RetryTailLatencyRule) Check(graph CallGraph) []Violation {
+	budget := r.Budget
+	if budget == 0 {
+		budget = time.Second
+	}
+	var violations []Violation
+	graph.WalkPaths(func(path []Edge) bool {
+		var single, tail, worstExtra time.Duration
+		var worst Edge
+		for _, e := range path {
+			if e.P99 == 0 {
+				continue
+			}
+			attempt := e.P99
+			if e.Timeout > 0 && e.Timeout < attempt {
+				attempt = e.Timeout
+			}
+			hop := attemptsTime(attempt, e.BackoffBase, e.MaxRetries)
+			single += attempt
+			tail = addDuration(tail, hop)
+			if extra := hop - attempt; extra > worstExtra {
+				worst, worstExtra = e, extra
+			}
+		}
+		if worstExtra == 0 || tail <= budget {
+			return true
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-tail-amplification",
+			Severity: "warning",
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"tail latency with retries is %v against %v for single attempts (%.1fx), over the %v budget; %s->%s adds the most (%v)",
+				tail, single, float64(tail)/float64(single), budget, worst.Source, worst.Target, worstExtra),
+			SourceHint: fmt.Sprintf("edge %s->%s", worst.Source, worst.Target),
+		})
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 43: UntunedUniformTimeoutRule
+// ---------------------------------------------------------------------------
+
+// UntunedUniformTimeoutRule notes paths of three or more hops on which every
+// call has the same timeout. A budget that should shrink hop by hop but is
+// copied unchanged onto each call suggests nobody allocated it per hop.
+// Paths with a hop lacking a timeout are skipped.
+type UntunedUniformTimeoutRule struct{}
+
+func (r *UntunedUniformTimeoutRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	graph.WalkPaths(func(path []Edge) bool {
+		if len(path) < 3 || path[0].Timeout == 0 {
+			return true
+		}
+		uniform := true
+		for _, e := range path[1:] {
+			if e.Timeout != path[0].Timeout {
+				uniform = false
+				break
+			}
+		}
+		if !uniform {
+			return true
+		}
+		violations = append(violations, Violation{
+			Rule:     "uniform-path-timeout",
+			Severity: "info",
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"all %d hops use the same %v timeout; allocate a shrinking budget per hop",
+				len(path), path[0].Timeout),
+		})
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 44: RetryIntoFixedCapacityRule
+// ---------------------------------------------------------------------------
+
+// RetryIntoFixedCapacityRule warns on retried edges into services declared
+// not to autoscale. During a traffic spike such a target saturates, its
+// callers' retries multiply the load it already cannot serve, and it cannot
+// add instances to absorb them. Targets that autoscale or leave it
+// undeclared are skipped.
+type RetryIntoFixedCapacityRule struct{}
+
+func (r *RetryIntoFixedCapacityRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 {
+			continue
+		}
+		target := graph.Node(e.Target)
+		if !target.FixedCapacity {
+			continue
+		}
+		capacity := "fixed capacity"
+		if target.Capacity > 0 {
+			capacity = fmt.Sprintf("a fixed capacity of %d req/s", target.Capacity)
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-into-fixed-capacity",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times into %s, which does not autoscale and has %s; a spike turns into up to %dx load on it",
+				e.Source, e.Target, e.MaxRetries, e.Target, capacity, 1+e.MaxRetries),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 45: ChattyChainRule
+// ---------------------------------------------------------------------------
+
+// ChattyChainRule notes paths of at least MinHops calls whose mean timeout
+// is at most MaxMeanTimeout: many hops that each do trivial work, so network
+// round-trips and serialization dominate the request (a "distributed
+// monolith"). Paths with a hop lacking a timeout are skipped, since their
+// work is unknown.
+type ChattyChainRule struct {
+	MinHops        int           // shortest path reported (default 6)
+	MaxMeanTimeout time.Duration // mean per-hop timeout at or below which hops count as trivial (default 50ms)
+}
+
+func (r *ChattyChainRule) Check(graph CallGraph) []Violation {
+	minHops := r.MinHops
+	if minHops == 0 {
+		minHops = 6
+	}
+	maxMean := r.MaxMeanTimeout
+	if maxMean == 0 {
+		maxMean = 50 * time.Millisecond
+	}
+	var violations []Violation
+	graph.WalkPaths(func(path []Edge) bool {
+		if len(path) < minHops {
+			return true
+		}
+		var total time.Duration
+		for _, e := range path {
+			if e.Timeout == 0 {
+				total = -1
+				break
+			}
+			total += e.Timeout
+		}
+		if total < 0 {
+			return true
+		}
+		mean := total / time.Duration(len(path))
+		if mean > maxMean {
+			return true
+		}
+		violations = append(violations, Violation{
+			Rule:     "chatty-chain",
+			Severity: "info",
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"%d hops with a mean timeout of %v (%v in all): per-call overhead likely dominates; consider merging services or batching calls",
+				len(path), mean, total),
+		})
+		return true
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 46: MutualRetryRule
+// ---------------------------------------------------------------------------
+
+// MutualRetryRule errors on pairs of services that retry calls to each
+// other (A->B and B->A both retried), as in callback patterns. A failure on
+// one side makes each retry of the other re-enter it, so the attempts
+// multiply back and forth across the pair. Each pair is reported once.
+type MutualRetryRule struct{}
+
+func (r *MutualRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Source >= e.Target || e.MaxRetries == 0 {
+			continue // visit each unordered pair once
+		}
+		for _, back := range graph.InEdges(e.Source) {
+			if back.Source != e.Target || back.MaxRetries == 0 {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "mutual-retry",
+				Severity: "error",
+				Path:     []string{e.Source, e.Target, e.Source},
+				Message: fmt.Sprintf(
+					"%s->%s retries %d times and %s->%s retries %d times; a failure bounces retries between them (up to %dx attempts per round trip)",
+					e.Source, e.Target, e.MaxRetries, back.Source, back.Target, back.MaxRetries, (1+e.MaxRetries)*(1+back.MaxRetries)),
+				SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 47: WastedLeafRetryRule
+// ---------------------------------------------------------------------------
+
+// WastedLeafRetryRule warns on the last hop of a path when some of its
+// retries cannot start before the request's deadline has passed. The
+// deadline left at the leaf is the tightest timeout above it (or
+// EntryTimeout when set, if tighter), less the declared processing_time of
+// each upstream call. Retry k starts after k attempts that ran to the leaf
+// timeout plus the backoff waits before it; retries starting at or after the
+// remaining deadline serve a request nobody is waiting for. Unlike
+// orphaned-retry, which compares a call with its direct caller, this uses
+// the whole path's budget. Each leaf call is reported once.
+type WastedLeafRetryRule struct {
+	EntryTimeout time.Duration // end-to-end deadline; 0 uses the path's timeouts only
+}
+
+func (r *WastedLeafRetryRule) Check(graph CallGraph) []Violation {
+	retried := countCalls(graph, func(e Edge) bool { return e.MaxRetries > 0 && e.Timeout > 0 })
+	if retried == 0 {
+		return nil
+	}
+	var violations []Violation
+	seen := make(map[[2]string]bool)
+	graph.WalkPaths(func(path []Edge) bool {
+		if len(path) < 2 {
+			return true
+		}
+		leaf := path[len(path)-1]
+		if leaf.MaxRetries == 0 || leaf.Timeout == 0 || seen[[2]string{leaf.Source, leaf.Target}] {
+			return true
+		}
+		deadline := r.EntryTimeout
+		var spent time.Duration
+		for _, e := range path[:len(path)-1] {
+			if e.Timeout > 0 && (deadline == 0 || e.Timeout < deadline) {
+				deadline = e.Timeout
+			}
+			spent += e.ProcessingTime
+		}
+		if deadline == 0 {
+			return true
+		}
+		remaining := deadline - spent
+		start, wait, wasted := time.Duration(0), leaf.BackoffBase, 0
+		for k := 1; k <= leaf.MaxRetries; k++ {
+			start += leaf.Timeout + wait
+			wait *= 2
+			if start >= remaining {
+				wasted = leaf.MaxRetries - k + 1
+				break
+			}
+		}
+		if wasted == 0 {
+			return true
+		}
+		seen[[2]string{leaf.Source, leaf.Target}] = true
+		violations = append(violations, Violation{
+			Rule:     "wasted-leaf-retry",
+			Severity: "warning",
+			Path:     pathNodes(path),
+			Message: fmt.Sprintf(
+				"%d of %d retries on leaf call %s->%s start after the %v left of the path's %v deadline; they are wasted work",
+				wasted, leaf.MaxRetries, leaf.Source, leaf.Target, remaining, deadline),
+			SourceHint: fmt.Sprintf("edge %s->%s", leaf.Source, leaf.Target),
+		})
+		return len(seen) < retried
+	})
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 48: FirstRetryImpossibleRule
+// ---------------------------------------------------------------------------
+
+// FirstRetryImpossibleRule warns on retried edges whose first backoff wait
+// alone outlasts what is left of the caller's budget once the first attempt
+// has run to its timeout: the upstream call has given up before even the
+// first retry starts, so every retry is dead configuration. Edges whose
+// first attempt already exhausts the upstream timeout are left to
+// timeout-inversion.
+type FirstRetryImpossibleRule struct{}
+
+func (r *FirstRetryImpossibleRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, d := range graph.AllEdges() {
+		if d.MaxRetries == 0 || d.Timeout == 0 || d.BackoffBase == 0 {
+			continue
+		}
+		for _, u := range graph.InEdges(d.Source) {
+			remaining := u.Timeout - d.Timeout
+			if u.Timeout == 0 || remaining <= 0 || d.BackoffBase < remaining {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "first-retry-impossible",
+				Severity: "warning",
+				Path:     []string{u.Source, d.Source, d.Target},
+				Message: fmt.Sprintf(
+					"%s->%s waits %v before its first retry, but after a %v attempt only %v of %s->%s's %v timeout is left; no retry can run",
+					d.Source, d.Target, d.BackoffBase, d.Timeout, remaining, u.Source, u.Target, u.Timeout),
+				SourceHint: fmt.Sprintf("edge %s->%s", d.Source, d.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 49: EffectiveTimeoutInversionRule
+// ---------------------------------------------------------------------------
+
+// EffectiveTimeoutInversionRule flags the timeout inversions that retries
+// create: a downstream call whose timeout is within its caller's, but whose
+// attempts together (Timeout × (1+MaxRetries)) are not. Nominal inversions,
+// where one attempt already outlasts the caller, are left to
+// timeout-inversion. Backoff waits are not counted; when only they push the
+// retries past the caller, the opt-in orphaned-retry rule reports it.
+type EffectiveTimeoutInversionRule struct{}
+
+func (r *EffectiveTimeoutInversionRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, d := range graph.AllEdges() {
+		if d.MaxRetries == 0 || d.Timeout == 0 {
+			continue
+		}
+		effective := attemptsTime(d.Timeout, 0, d.MaxRetries)
+		for _, u := range graph.InEdges(d.Source) {
+			if !effectivelyInverted(u, d) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "effective-timeout-inversion",
+				Severity: "warning",
+				Path:     []string{u.Source, d.Source, d.Target},
+				Message: fmt.Sprintf(
+					"%s->%s timeout %v fits %s->%s's %v, but its %d attempts take up to %v",
+					d.Source, d.Target, d.Timeout, u.Source, u.Target, u.Timeout, 1+d.MaxRetries, effective),
+				SourceHint: fmt.Sprintf("edge %s->%s", d.Source, d.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// effectivelyInverted reports whether d's timeout fits within that of u, the
+// call into d's caller, while d's attempts together do not.
+func effectivelyInverted(u, d Edge) bool {
+	if u.Timeout == 0 || d.Timeout == 0 || d.MaxRetries == 0 || d.Timeout > u.Timeout {
+		return false
+	}
+	return attemptsTime(d.Timeout, 0, d.MaxRetries) > u.Timeout
+}
+
+// ---------------------------------------------------------------------------
+// Rule 50: ChokepointRule
+// ---------------------------------------------------------------------------
+
+// ChokepointRule warns on services with at least MinCallers distinct
+// callers and at least MinCallees distinct downstreams. Such a service sits
+// in the middle of the mesh: trouble in any of its dependencies reaches all
+// of its callers through it, and its own degradation stalls all of them at
+// once. High fan-in or fan-out alone is not reported.
+type ChokepointRule struct {
+	MinCallers int // distinct callers (default 4)
+	MinCallees int // distinct downstreams (default 4)
+}
+
+func (r *ChokepointRule) Check(graph CallGraph) []Violation {
+	minIn, minOut := r.MinCallers, r.MinCallees
+	if minIn == 0 {
+		minIn = 4
+	}
+	if minOut == 0 {
+		minOut = 4
+	}
+	callers := make(map[string]map[string]bool)
+	callees := make(map[string]map[string]bool)
+	for _, e := range graph.AllEdges() {
+		if callers[e.Target] == nil {
+			callers[e.Target] = make(map[string]bool)
+		}
+		callers[e.Target][e.Source] = true
+		if callees[e.Source] == nil {
+			callees[e.Source] = make(map[string]bool)
+		}
+		callees[e.Source][e.Target] = true
+	}
+	svcs := make([]string, 0, len(callers))
+	for svc := range callers {
+		svcs = append(svcs, svc)
+	}
+	sort.Strings(svcs)
+
+	var violations []Violation
+	for _, svc := range svcs {
+		in, out := len(callers[svc]), len(callees[svc])
+		if in < minIn || out < minOut {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "chokepoint",
+			Severity: "warning",
+			Path:     []string{svc},
+			Message: fmt.Sprintf(
+				"%s is a chokepoint: %d callers (fan-in) and %d downstreams (fan-out); its degradation spreads across the mesh",
+				svc, in, out),
+			SourceHint: fmt.Sprintf("service %s", svc),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 51: DeterministicErrorRetryRule
+// ---------------------------------------------------------------------------
+
+// DeterministicErrorRetryRule warns on retried edges into services declared
+// deterministic_errors: true. Such a service (a validation service, say)
+// rejects the same request the same way every time, so each retry repeats
+// a failure it cannot turn into a success and only adds load and latency.
+type DeterministicErrorRetryRule struct{}
+
+func (r *DeterministicErrorRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 || !graph.Node(e.Target).DeterministicErrors {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-deterministic-errors",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times, but %s returns only deterministic errors; every retry fails the same way",
+				e.Source, e.Target, e.MaxRetries, e.Target),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 52: ConflictingIdempotencyRule
+// ---------------------------------------------------------------------------
+
+// ConflictingIdempotencyRule warns when the callers of one service disagree
+// on whether calling it is idempotent: one retries it as idempotent while
+// another treats it as non-idempotent and does not retry. Only one of them
+// can be right about the target. Two calls are compared unless they name
+// different methods, or both name an endpoint (InconsistentIdempotencyRule
+// covers those). One violation is reported per target, on the first
+// retrying call.
+type ConflictingIdempotencyRule struct{}
+
+func (r *ConflictingIdempotencyRule) Check(graph CallGraph) []Violation {
+	byTarget := make(map[string][]Edge)
+	var targets []string
+	for _, e := range graph.AllEdges() {
+		if _, ok := byTarget[e.Target]; !ok {
+			targets = append(targets, e.Target)
+		}
+		byTarget[e.Target] = append(byTarget[e.Target], e)
+	}
+	sort.Strings(targets)
+
+	comparable := func(a, b Edge) bool {
+		if a.Method != "" && b.Method != "" && !strings.EqualFold(a.Method, b.Method) {
+			return false
+		}
+		return a.Endpoint == "" || b.Endpoint == ""
+	}
+	var violations []Violation
+	for _, target := range targets {
+		edges := byTarget[target]
+	search:
+		for _, yes := range edges {
+			if !yes.Idempotent || yes.MaxRetries == 0 {
+				continue
+			}
+			for _, no := range edges {
+				if no.Idempotent || no.MaxRetries > 0 || no.Source == yes.Source || !comparable(yes, no) {
+					continue
+				}
+				violations = append(violations, Violation{
+					Rule:     "conflicting-idempotency",
+					Severity: "warning",
+					Path:     []string{yes.Source, target},
+					Message: fmt.Sprintf(
+						"%s->%s retries %s as idempotent, but %s->%s treats it as non-idempotent and does not retry; one of them is wrong about %s",
+						yes.Source, target, target, no.Source, target, target),
+					SourceHint: fmt.Sprintf("edge %s->%s", yes.Source, target),
+				})
+				break search
+			}
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 53: SelfCallRule
+// ---------------------------------------------------------------------------
+
+// SelfCallRule reports services that call themselves, typically through an
+// alias or proxy that resolved back to the caller. Each request can re-enter
+// the service without bound, and with retries every level of recursion
+// multiplies the attempts of the level above: an error when the self-call
+// retries, a warning otherwise.
+type SelfCallRule struct{}
+
+func (r *SelfCallRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.Source != e.Target {
+			continue
+		}
+		v := Violation{
+			Rule:       "self-call",
+			Severity:   "warning",
+			Path:       []string{e.Source, e.Target},
+			Message:    fmt.Sprintf("%s calls itself; each request can recurse through it without bound", e.Source),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		}
+		if e.MaxRetries > 0 {
+			v.Severity = "error"
+			v.Message = fmt.Sprintf(
+				"%s calls itself with %d retries; every level of recursion multiplies attempts by %d, without bound",
+				e.Source, e.MaxRetries, 1+e.MaxRetries)
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 54: IgnoredBackpressureRetryRule
+// ---------------------------------------------------------------------------
+
+// IgnoredBackpressureRetryRule warns on retried edges declared
+// honors_retry_after: false. When an overloaded target answers 429 or 503
+// with a Retry-After header, such a client retries on its own schedule
+// instead, adding load exactly when the target asked for less. Edges that
+// leave it undeclared are skipped.
+type IgnoredBackpressureRetryRule struct{}
+
+func (r *IgnoredBackpressureRetryRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if e.MaxRetries == 0 || !e.IgnoresRetryAfter {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "retry-ignores-backpressure",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %d times without honoring Retry-After; when %s sheds load with 429/503 the retries keep it overloaded",
+				e.Source, e.Target, e.MaxRetries, e.Target),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 55: FanOutMissingTimeoutRule
+// ---------------------------------------------------------------------------
+
+// FanOutMissingTimeoutRule errors on calls without a timeout made by fan-out
+// nodes (two or more synchronous out-edges). The aggregation waits for every
+// sibling, so one untimed child can block it indefinitely and the others'
+// timeouts protect nothing; the blast radius is the whole scatter-gather
+// rather than one call. Async calls are not awaited and are skipped. Each
+// untimed sibling is reported.
+type FanOutMissingTimeoutRule struct{}
+
+func (r *FanOutMissingTimeoutRule) Check(graph CallGraph) []Violation {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, e := range graph.AllEdges() {
+		if !seen[e.Source] {
+			seen[e.Source] = true
+			sources = append(sources, e.Source)
+		}
+	}
+	sort.Strings(sources)
+
+	var violations []Violation
+	for _, svc := range sources {
+		var siblings []Edge
+		for _, e := range graph.OutEdges(svc) {
+			if !e.Async {
+				siblings = append(siblings, e)
+			}
+		}
+		if len(siblings) < 2 {
+			continue
+		}
+		for _, e := range siblings {
+			if e.Timeout != 0 {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     "fan-out-missing-timeout",
+				Severity: "error",
+				Path:     []string{svc, e.Target},
+				Message: fmt.Sprintf(
+					"%s fans out to %d services but %s->%s has no timeout; it can block the whole aggregation indefinitely",
+					svc, len(siblings), svc, e.Target),
+				SourceHint: fmt.Sprintf("edge %s->%s", svc, e.Target),
+			})
+		}
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 56: UnobservedResilienceRule
+// ---------------------------------------------------------------------------
+
+// UnobservedResilienceRule notes edges with retries or a timeout that are
+// declared observable: false. Nobody will see when those retries fire or the
+// timeout hits, so the config cannot be tuned or trusted. Edges that leave
+// observability undeclared are skipped.
+type UnobservedResilienceRule struct{}
+
+func (r *UnobservedResilienceRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.Unobservable || e.MaxRetries == 0 && e.Timeout == 0 {
+			continue
+		}
+		var config []string
+		if e.MaxRetries > 0 {
+			config = append(config, fmt.Sprintf("%d retries", e.MaxRetries))
+		}
+		if e.Timeout > 0 {
+			config = append(config, fmt.Sprintf("a %v timeout", e.Timeout))
+		}
+		violations = append(violations, Violation{
+			Rule:     "unobserved-resilience",
+			Severity: "info",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s has %s but no metrics or tracing; you will not know when they fire",
+				e.Source, e.Target, strings.Join(config, " and ")),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 57: BudgetAllocationRule
+// ---------------------------------------------------------------------------
+
+// BudgetAllocationRule warns when one hop of the critical path (the path
+// with the longest worst-case latency, timeout × attempts summed per hop)
+// takes more than MaxShare of the budget while the other hops share the
+// rest. The budget is EntryTimeout when set, else the path's own worst
+// case. Such a split is rarely deliberate: the dominant hop was usually
+// given a generous default and the others were squeezed to fit. The
+// message lists each hop's share. Paths of one hop, or with a hop lacking
+// a timeout, are skipped.
+type BudgetAllocationRule struct {
+	EntryTimeout time.Duration // end-to-end budget; 0 uses the critical path's worst case
+	MaxShare     float64       // largest fraction of the budget one hop may take (default 0.7)
+}
+
+func (r *BudgetAllocationRule) Check(graph CallGraph) []Violation {
+	maxShare := r.MaxShare
+	if maxShare == 0 {
+		maxShare = 0.7
+	}
+	var critical []Edge
+	var longest time.Duration
+	graph.WalkPaths(func(path []Edge) bool {
+		var total time.Duration
+		for _, e := range path {
+			if e.Timeout == 0 {
+				total = -1
+				break
+			}
+			total += e.Timeout * time.Duration(1+e.MaxRetries)
+		}
+		if total > longest {
+			critical, longest = append(critical[:0], path...), total
+		}
+		return true
+	})
+	if len(critical) < 2 {
+		return nil
+	}
+	budget := r.EntryTimeout
+	if budget == 0 {
+		budget = longest
+	}
+	shares := make([]string, len(critical))
+	top := 0
+	var topShare float64
+	for i, e := range critical {
+		share := float64(e.Timeout*time.Duration(1+e.MaxRetries)) / float64(budget)
+		shares[i] = fmt.Sprintf("%s->%s %.0f%%", e.Source, e.Target, share*100)
+		if share > topShare {
+			top, topShare = i, share
+		}
+	}
+	if topShare <= maxShare {
+		return nil
+	}
+	e := critical[top]
+	return []Violation{{
+		Rule:     "budget-allocation",
+		Severity: "warning",
+		Path:     pathNodes(critical),
+		Message: fmt.Sprintf(
+			"%s->%s takes %.0f%% of the %v budget on the critical path, leaving the other %d hops the rest (%s)",
+			e.Source, e.Target, topShare*100, budget, len(critical)-1, strings.Join(shares, ", ")),
+		SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+	}}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 58: UnreachableCBThresholdRule
+// ---------------------------------------------------------------------------
+
+// Values of Edge.CBWindow.
+const (
+	CBWindowPerRequest = "per-request"
+	CBWindowShared     = "shared"
+)
+
+// UnreachableCBThresholdRule warns on circuit breakers whose failure
+// threshold one request can never reach. A breaker with CBWindow
+// CBWindowPerRequest starts afresh on every request, so the most failures
+// it can see are the request's attempts (1+MaxRetries), or just one when
+// it counts per call. A higher CBFailureThreshold means the breaker never
+// opens and gives no protection. Breakers with a shared or undeclared
+// window, or no declared threshold, are skipped.
+type UnreachableCBThresholdRule struct{}
+
+func (r *UnreachableCBThresholdRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		if !e.HasCircuitBreaker || e.CBFailureThreshold == 0 || e.CBWindow != CBWindowPerRequest {
+			continue
+		}
+		reachable := 1 + e.MaxRetries
+		counted := fmt.Sprintf("%d attempts per request", reachable)
+		if e.CBCounts == CBCountsPerCall {
+			reachable, counted = 1, "1 failure per request (it counts per call)"
+		}
+		if e.CBFailureThreshold <= reachable {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "cb-threshold-unreachable",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s circuit breaker opens after %d failures but its per-request window sees at most %s; it never opens",
+				e.Source, e.Target, e.CBFailureThreshold, counted),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
+	}
+	return violations
+}
+
+// ---------------------------------------------------------------------------
+// Rule 59: MutatingRetryWithoutKeyRule
+// ---------------------------------------------------------------------------
+
+// mutatingMethods are the HTTP methods whose requests change server state.
+var mutatingMethods = map[string]bool{"POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// MutatingRetryWithoutKeyRule warns on retried calls to a mutating endpoint
+// (a POST, PUT, PATCH or DELETE with an Endpoint) of a service that does not
+// support idempotency keys. Unlike non-idempotent-retry, an idempotent:
+// true declaration does not clear the call: a retry after a lost response
+// repeats the write unless the target can recognise it by its key. Calls
+// without an Endpoint are skipped.
+type MutatingRetryWithoutKeyRule struct{}
+
+func (r *MutatingRetryWithoutKeyRule) Check(graph CallGraph) []Violation {
+	var violations []Violation
+	for _, e := range graph.AllEdges() {
+		method := strings.ToUpper(e.Method)
+		if e.MaxRetries == 0 || e.Endpoint == "" || !mutatingMethods[method] {
+			continue
+		}
+		if graph.Node(e.Target).SupportsIdempotencyKey {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:     "unkeyed-mutating-retry",
+			Severity: "warning",
+			Path:     []string{e.Source, e.Target},
+			Message: fmt.Sprintf(
+				"%s->%s retries %s %s %d times, but %s does not support idempotency keys; a retry after a lost response repeats the write",
+				e.Source, e.Target, method, e.Endpoint, e.MaxRetries, e.Target),
+			SourceHint: fmt.Sprintf("edge %s->%s", e.Source, e.Target),
+		})
 	}
 	return violations
 }
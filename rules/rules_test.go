@@ -1,7 +1,11 @@
 package rules
 
 import (
+	"fmt"
+	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -13,21 +17,39 @@ import (
 type mockGraph struct {
 	edges []Edge
 	adj   map[string][]Edge
+	in    map[string][]Edge
+	nodes map[string]Node
 }
 
 func newMockGraph(edges ...Edge) *mockGraph {
 	adj := make(map[string][]Edge)
+	in := make(map[string][]Edge)
 	for _, e := range edges {
 		adj[e.Source] = append(adj[e.Source], e)
+		in[e.Target] = append(in[e.Target], e)
 	}
-	return &mockGraph{edges: edges, adj: adj}
+	return &mockGraph{edges: edges, adj: adj, in: in, nodes: make(map[string]Node)}
+}
+
+// withNodes registers per-service attributes and returns the graph.
+func (g *mockGraph) withNodes(nodes ...Node) *mockGraph {
+	for _, n := range nodes {
+		g.nodes[n.Name] = n
+	}
+	return g
 }
 
 func (g *mockGraph) AllEdges() []Edge            { return g.edges }
-func (g *mockGraph) OutEdges(node string) []Edge  { return g.adj[node] }
+func (g *mockGraph) OutEdges(node string) []Edge { return g.adj[node] }
+func (g *mockGraph) InEdges(node string) []Edge  { return g.in[node] }
+func (g *mockGraph) Node(name string) Node {
+	n := g.nodes[name]
+	n.Name = name
+	return n
+}
 
-// Paths enumerates all root-to-leaf paths via DFS.
-func (g *mockGraph) Paths() [][]Edge {
+// WalkPaths enumerates all root-to-leaf paths via DFS.
+func (g *mockGraph) WalkPaths(fn func(path []Edge) bool) {
 	targets := make(map[string]bool)
 	sources := make(map[string]bool)
 	for _, e := range g.edges {
@@ -48,23 +70,18 @@ func (g *mockGraph) Paths() [][]Edge {
 	// Sort for deterministic output across runs.
 	sort.Strings(roots)
 
-	var paths [][]Edge
 	for _, root := range roots {
 		visited := map[string]bool{root: true}
-		g.dfs(root, nil, &paths, visited)
+		if !g.dfs(root, nil, fn, visited) {
+			return
+		}
 	}
-	return paths
 }
 
-func (g *mockGraph) dfs(node string, current []Edge, paths *[][]Edge, visited map[string]bool) {
+func (g *mockGraph) dfs(node string, current []Edge, fn func([]Edge) bool, visited map[string]bool) bool {
 	out := g.adj[node]
 	if len(out) == 0 {
-		if len(current) > 0 {
-			cp := make([]Edge, len(current))
-			copy(cp, current)
-			*paths = append(*paths, cp)
-		}
-		return
+		return len(current) == 0 || fn(current)
 	}
 	for _, e := range out {
 		if visited[e.Target] {
@@ -74,9 +91,23 @@ func (g *mockGraph) dfs(node string, current []Edge, paths *[][]Edge, visited ma
 		next := make([]Edge, len(current)+1)
 		copy(next, current)
 		next[len(current)] = e
-		g.dfs(e.Target, next, paths, visited)
+		ok := g.dfs(e.Target, next, fn, visited)
 		delete(visited, e.Target)
+		if !ok {
+			return false
+		}
 	}
+	return true
+}
+
+// paths collects every path cg walks.
+func paths(cg CallGraph) [][]Edge {
+	var all [][]Edge
+	cg.WalkPaths(func(path []Edge) bool {
+		all = append(all, append([]Edge(nil), path...))
+		return true
+	})
+	return all
 }
 
 // ---------------------------------------------------------------------------
@@ -107,9 +138,9 @@ func hasSeverity(vs []Violation, rule, severity string) bool {
 
 func TestTimeoutInversionRule(t *testing.T) {
 	tests := []struct {
-		name   string
-		edges  []Edge
-		want   bool
+		name  string
+		edges []Edge
+		want  bool
 	}{
 		{
 			name: "downstream timeout > upstream — triggers",
@@ -239,13 +270,42 @@ func TestRetryAmplificationRule(t *testing.T) {
 	}
 }
 
+func TestRetryAmplificationPrimaryContributor(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "A", Target: "B", MaxRetries: 1, Timeout: time.Second},
+		Edge{Source: "B", Target: "C", MaxRetries: 4, Timeout: time.Second},
+		Edge{Source: "C", Target: "D", MaxRetries: 2, Timeout: time.Second},
+	)
+	vs := (&RetryAmplificationRule{}).Check(g)
+	if len(vs) != 1 {
+		t.Fatalf("want 1 violation, got %+v", vs)
+	}
+	if !strings.Contains(vs[0].Message, "primary contributor B->C (5 attempts)") {
+		t.Errorf("message should name B->C as primary contributor: %s", vs[0].Message)
+	}
+	if vs[0].SourceHint != "edge B->C" {
+		t.Errorf("SourceHint = %q, want edge B->C", vs[0].SourceHint)
+	}
+}
+
+func TestRetryAmplificationPrimaryContributorTie(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "A", Target: "B", MaxRetries: 3, Timeout: time.Second},
+		Edge{Source: "B", Target: "C", MaxRetries: 3, Timeout: time.Second},
+	)
+	vs := (&RetryAmplificationRule{}).Check(g)
+	if len(vs) != 1 || !strings.Contains(vs[0].Message, "primary contributor A->B") {
+		t.Errorf("tie should go to the edge nearest the root: %+v", vs)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Rule 3: NonIdempotentRetryRule
 // ---------------------------------------------------------------------------
 
 func TestNonIdempotentRetryRule(t *testing.T) {
 	tests := []struct {
-		name string
+		name  string
 		edges []Edge
 		want  bool
 	}{
@@ -291,7 +351,7 @@ func TestNonIdempotentRetryRule(t *testing.T) {
 
 func TestRetryWithoutCircuitBreakerRule(t *testing.T) {
 	tests := []struct {
-		name string
+		name  string
 		edges []Edge
 		want  bool
 	}{
@@ -337,7 +397,7 @@ func TestRetryWithoutCircuitBreakerRule(t *testing.T) {
 
 func TestBackoffWithoutJitterRule(t *testing.T) {
 	tests := []struct {
-		name string
+		name  string
 		edges []Edge
 		want  bool
 	}{
@@ -440,6 +500,2582 @@ func TestEndToEndTimeoutExceedRule(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Rule 7: ReadAfterWriteRetryRule
+// ---------------------------------------------------------------------------
+
+func TestReadAfterWriteRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "write then retried read of written data — triggers",
+			edges: []Edge{
+				{Source: "A", Target: "orders", Idempotent: false},
+				{Source: "orders", Target: "orders-view", Idempotent: true, MaxRetries: 2, ReadsFrom: []string{"orders"}},
+			},
+			want: true,
+		},
+		{
+			name: "write then unretried read — clean",
+			edges: []Edge{
+				{Source: "A", Target: "orders", Idempotent: false},
+				{Source: "orders", Target: "orders-view", Idempotent: true, MaxRetries: 0, ReadsFrom: []string{"orders"}},
+			},
+			want: false,
+		},
+		{
+			name: "write then retried read of unrelated data — clean",
+			edges: []Edge{
+				{Source: "A", Target: "orders", Idempotent: false},
+				{Source: "orders", Target: "catalog", Idempotent: true, MaxRetries: 2},
+			},
+			want: false,
+		},
+	}
+
+	rule := &ReadAfterWriteRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edges...)
+			vs := rule.Check(g)
+			got := hasRule(vs, "read-after-write-retry")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 8: UniformConfigRule
+// ---------------------------------------------------------------------------
+
+func TestUniformConfigRule(t *testing.T) {
+	same := func(src, tgt string) Edge {
+		return Edge{Source: src, Target: tgt, Timeout: 2 * time.Second, MaxRetries: 3, HasCircuitBreaker: true}
+	}
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "5 identical-config edges — notes",
+			edges: []Edge{
+				same("A", "B"), same("A", "C"), same("B", "D"), same("C", "E"), same("E", "F"),
+			},
+			want: true,
+		},
+		{
+			name: "varied config — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second, MaxRetries: 3, HasCircuitBreaker: true},
+				{Source: "A", Target: "C", Timeout: 1 * time.Second, MaxRetries: 3, HasCircuitBreaker: true},
+				{Source: "B", Target: "D", Timeout: 2 * time.Second, MaxRetries: 1, HasCircuitBreaker: true},
+				{Source: "C", Target: "E", Timeout: 500 * time.Millisecond, MaxRetries: 2},
+				{Source: "E", Target: "F", Timeout: 2 * time.Second, MaxRetries: 3},
+			},
+			want: false,
+		},
+		{
+			name: "unconfigured edges — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"}, {Source: "A", Target: "C"}, {Source: "B", Target: "D"},
+				{Source: "C", Target: "E"}, {Source: "E", Target: "F"},
+			},
+			want: false,
+		},
+		{
+			name: "identical config without circuit breakers — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second, MaxRetries: 2},
+				{Source: "A", Target: "C", Timeout: time.Second, MaxRetries: 2},
+				{Source: "B", Target: "D", Timeout: time.Second, MaxRetries: 2},
+				{Source: "C", Target: "E", Timeout: time.Second, MaxRetries: 2},
+				{Source: "E", Target: "F", Timeout: time.Second, MaxRetries: 2},
+			},
+			want: false,
+		},
+	}
+
+	rule := &UniformConfigRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edges...)
+			vs := rule.Check(g)
+			got := hasSeverity(vs, "uniform-config", "info")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+func TestUniformConfigRuleNamesEveryGroupingField(t *testing.T) {
+	var edges []Edge
+	for _, tgt := range []string{"B", "C", "D", "E", "F"} {
+		e := Edge{Source: "A", Target: tgt, Timeout: 2 * time.Second, MaxRetries: 3, HasCircuitBreaker: true,
+			HasBackoff: true, CBFailureThreshold: 5}
+		jittered := e
+		jittered.Source, jittered.Jitter = "Z", true
+		edges = append(edges, e, jittered)
+	}
+	vs := (&UniformConfigRule{}).Check(newMockGraph(edges...))
+	if len(vs) != 2 {
+		t.Fatalf("want one note per jitter setting, got %+v", vs)
+	}
+	if vs[0].Message == vs[1].Message {
+		t.Errorf("clusters differing only in jitter should read differently: %q", vs[0].Message)
+	}
+	for _, want := range []string{"backoff true", "jitter false", "breaker threshold 5", "open timeout undeclared"} {
+		if !strings.Contains(vs[0].Message, want) {
+			t.Errorf("message should contain %q: %s", want, vs[0].Message)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 9: BidirectionalTimeoutRule
+// ---------------------------------------------------------------------------
+
+func TestBidirectionalTimeoutRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "symmetric pair — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "A", Timeout: time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "30x asymmetric pair — warns",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "A", Timeout: 30 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "one-directional call — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "C", Timeout: 30 * time.Second},
+			},
+			want: false,
+		},
+	}
+
+	rule := &BidirectionalTimeoutRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edges...)
+			vs := rule.Check(g)
+			got := hasRule(vs, "bidirectional-timeout-asymmetry")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 10: RetriesExceedReplicasRule
+// ---------------------------------------------------------------------------
+
+func TestRetriesExceedReplicasRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		replicas int
+		want     bool
+	}{
+		{name: "3 attempts over 5 replicas — clean", retries: 2, replicas: 5, want: false},
+		{name: "3 attempts over 3 replicas — clean", retries: 2, replicas: 3, want: false},
+		{name: "5 attempts over 2 replicas — warns", retries: 4, replicas: 2, want: true},
+		{name: "replicas unknown — clean", retries: 4, replicas: 0, want: false},
+	}
+
+	rule := &RetriesExceedReplicasRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(Edge{Source: "A", Target: "B", MaxRetries: tc.retries}).
+				withNodes(Node{Name: "B", Replicas: tc.replicas})
+			vs := rule.Check(g)
+			got := hasRule(vs, "retries-exceed-replicas")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 11: OrphanedRetryRule
+// ---------------------------------------------------------------------------
+
+func TestOrphanedRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			// B->C: 500ms × 3 + 100ms + 200ms backoff = 1.8s < 2s
+			name: "downstream retries finish within upstream budget — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: 500 * time.Millisecond, MaxRetries: 2, BackoffBase: 100 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			// B->C: 1s × 3 = 3s > 2s without backoff
+			name: "attempts alone outlive upstream timeout — left to effective-timeout-inversion",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second, MaxRetries: 2},
+			},
+			want: false,
+		},
+		{
+			// B->C: one 3s attempt already outlives A->B's 1s
+			name: "nominal inversion with retries — warns",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "C", Timeout: 3 * time.Second, MaxRetries: 1},
+			},
+			want: true,
+		},
+		{
+			// B->C: 600ms × 3 + 100ms + 200ms backoff = 2.1s > 2s
+			name: "backoff pushes retries past upstream timeout — warns",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: 600 * time.Millisecond, MaxRetries: 2, BackoffBase: 100 * time.Millisecond},
+			},
+			want: true,
+		},
+	}
+
+	rule := &OrphanedRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edges...)
+			vs := rule.Check(g)
+			got := hasRule(vs, "orphaned-retry")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+func TestRetryBudgetSaturates(t *testing.T) {
+	// The last of 70 doubling 100ms backoff waits alone is 100ms × 2^69,
+	// far past what a time.Duration holds.
+	long := Edge{Source: "B", Target: "C", Timeout: 100 * time.Millisecond, MaxRetries: 70, BackoffBase: 100 * time.Millisecond}
+	if got := retryBudget(long); got != maxDuration {
+		t.Errorf("70 retries: want the budget to saturate at %v, got %v", maxDuration, got)
+	}
+	if got := retryBudget(Edge{Timeout: maxDuration / 2, MaxRetries: 3}); got != maxDuration {
+		t.Errorf("huge timeout × attempts: want %v, got %v", maxDuration, got)
+	}
+	if got := retryBudget(Edge{Timeout: time.Second, MaxRetries: 2, BackoffBase: 100 * time.Millisecond}); got != 3300*time.Millisecond {
+		t.Errorf("want 3×1s + 100ms + 200ms = 3.3s, got %v", got)
+	}
+	vs := (&EffectiveTimeoutInversionRule{}).Check(newMockGraph(Edge{Source: "A", Target: "B", Timeout: 2 * time.Second}, long))
+	if !hasRule(vs, "effective-timeout-inversion") {
+		t.Errorf("70 retries must still outlive a 2s caller; violations=%+v", vs)
+	}
+	vs = (&OrphanedRetryRule{}).Check(newMockGraph(Edge{Source: "A", Target: "B", Timeout: 2 * time.Second},
+		Edge{Source: "B", Target: "C", Timeout: 10 * time.Millisecond, MaxRetries: 70, BackoffBase: 100 * time.Millisecond}))
+	if !hasRule(vs, "orphaned-retry") {
+		t.Errorf("70 backoff waits must still outlive a 2s caller; violations=%+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 12: TierViolationRule
+// ---------------------------------------------------------------------------
+
+func TestTierViolationRule(t *testing.T) {
+	tiers := []Node{{Name: "web", Tier: "edge"}, {Name: "orders", Tier: "core"}, {Name: "db", Tier: "data"}}
+	tests := []struct {
+		name    string
+		edge    Edge
+		wantSev string // "" means no violation
+	}{
+		{name: "edge->core allowed — clean", edge: Edge{Source: "web", Target: "orders"}},
+		{name: "edge->data disallowed — errors", edge: Edge{Source: "web", Target: "db", HasCircuitBreaker: true}, wantSev: "error"},
+		{name: "core->data without circuit breaker — warns", edge: Edge{Source: "orders", Target: "db"}, wantSev: "warning"},
+		{name: "core->data with circuit breaker — clean", edge: Edge{Source: "orders", Target: "db", HasCircuitBreaker: true}},
+		{name: "untiered target — clean", edge: Edge{Source: "web", Target: "legacy"}},
+	}
+
+	rule := &TierViolationRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edge).withNodes(tiers...)
+			vs := rule.Check(g)
+			if tc.wantSev == "" {
+				if hasRule(vs, "tier-violation") {
+					t.Errorf("expected no violation; violations=%+v", vs)
+				}
+				return
+			}
+			if !hasSeverity(vs, "tier-violation", tc.wantSev) {
+				t.Errorf("expected %s; violations=%+v", tc.wantSev, vs)
+			}
+		})
+	}
+}
+
+func TestTierViolationRuleCustomPolicy(t *testing.T) {
+	rule := &TierViolationRule{Policy: TierPolicy{Allow: map[string][]string{"edge": {"core", "data"}}}}
+	g := newMockGraph(Edge{Source: "web", Target: "db"}).
+		withNodes(Node{Name: "web", Tier: "edge"}, Node{Name: "db", Tier: "data"})
+	if vs := rule.Check(g); hasRule(vs, "tier-violation") {
+		t.Errorf("custom policy allows edge->data; violations=%+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 13: IdempotentMethodMismatchRule
+// ---------------------------------------------------------------------------
+
+func TestIdempotentMethodMismatchRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		rule IdempotentMethodMismatchRule
+		want bool
+	}{
+		{name: "POST marked idempotent — warns", edge: Edge{Source: "A", Target: "B", Method: "POST", DeclaredIdempotent: true}, want: true},
+		{name: "PUT marked idempotent — clean", edge: Edge{Source: "A", Target: "B", Method: "PUT", DeclaredIdempotent: true}, want: false},
+		{name: "POST not declared — clean", edge: Edge{Source: "A", Target: "B", Method: "POST"}, want: false},
+		{
+			name: "DELETE flagged by custom method list — warns",
+			edge: Edge{Source: "A", Target: "B", Method: "DELETE", DeclaredIdempotent: true},
+			rule: IdempotentMethodMismatchRule{Methods: []string{"POST", "PATCH", "DELETE"}},
+			want: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := tc.rule
+			vs := rule.Check(newMockGraph(tc.edge))
+			got := hasRule(vs, "idempotent-method-mismatch")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 14: SlowDownstreamRetryRule
+// ---------------------------------------------------------------------------
+
+func TestSlowDownstreamRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "retried edge over 60s unretried downstream — triggers",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second, MaxRetries: 2},
+				{Source: "B", Target: "C", Timeout: 60 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "retried edge over short downstream — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second, MaxRetries: 2},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "unretried edge over 60s downstream — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 60 * time.Second},
+			},
+			want: false,
+		},
+	}
+
+	rule := &SlowDownstreamRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edges...))
+			got := hasRule(vs, "retry-over-slow-downstream")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 15: BackendRequestCapRule
+// ---------------------------------------------------------------------------
+
+func TestBackendRequestCapRule(t *testing.T) {
+	// gateway fans out to 4 services (3 attempts each), each calling db with
+	// 5 attempts: db sees 4 × 3 × 5 = 60 requests.
+	var edges []Edge
+	for _, svc := range []string{"A", "B", "C", "D"} {
+		edges = append(edges,
+			Edge{Source: "gateway", Target: svc, MaxRetries: 2},
+			Edge{Source: svc, Target: "db", MaxRetries: 4})
+	}
+	vs := (&BackendRequestCapRule{}).Check(newMockGraph(edges...))
+	if len(vs) != 1 || vs[0].Path[0] != "db" {
+		t.Fatalf("expected exactly one violation for db; violations=%+v", vs)
+	}
+
+	if vs := (&BackendRequestCapRule{Cap: 60}).Check(newMockGraph(edges...)); hasRule(vs, "backend-request-cap") {
+		t.Errorf("60 requests at cap 60 should be clean; violations=%+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 16: RetryOnHealthCheckRule
+// ---------------------------------------------------------------------------
+
+func TestRetryOnHealthCheckRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "retried health check — warns", edge: Edge{Source: "lb", Target: "api", Kind: "health", MaxRetries: 2}, want: true},
+		{name: "unretried health check — clean", edge: Edge{Source: "lb", Target: "api", Kind: "health"}, want: false},
+		{name: "retried normal call — clean", edge: Edge{Source: "lb", Target: "api", MaxRetries: 2}, want: false},
+	}
+
+	rule := &RetryOnHealthCheckRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			got := hasRule(vs, "retry-on-health-check")
+			if got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 17: JitterWithoutBackoffRule
+// ---------------------------------------------------------------------------
+
+func TestJitterWithoutBackoffRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "jitter without backoff — notes",
+			edges: []Edge{
+				{Source: "A", Target: "B", HasBackoff: false, Jitter: true},
+			},
+			want: true,
+		},
+		{
+			name: "backoff with jitter — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", HasBackoff: true, Jitter: true},
+			},
+			want: false,
+		},
+		{
+			name: "no backoff and no jitter — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", HasBackoff: false, Jitter: false},
+			},
+			want: false,
+		},
+	}
+
+	rule := &JitterWithoutBackoffRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edges...))
+			if got := hasRule(vs, "jitter-no-backoff"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if tc.want && !hasSeverity(vs, "jitter-no-backoff", "info") {
+				t.Errorf("expected info severity, got %+v", vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 18: EntryTimeoutMissingRule
+// ---------------------------------------------------------------------------
+
+func TestEntryTimeoutMissingRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  EntryTimeoutMissingRule
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "entry without timeout — errors",
+			edges: []Edge{
+				{Source: "gateway", Target: "api"},
+				{Source: "api", Target: "db", Timeout: time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "entry with timeout — clean",
+			edges: []Edge{
+				{Source: "gateway", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db", Timeout: time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "missing timeout below the entry — not this rule",
+			edges: []Edge{
+				{Source: "gateway", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db"},
+			},
+			want: false,
+		},
+		{
+			name: "declared entry budget — clean",
+			rule: EntryTimeoutMissingRule{EntryTimeout: 5 * time.Second},
+			edges: []Edge{
+				{Source: "gateway", Target: "api"},
+			},
+			want: false,
+		},
+		{
+			name: "explicit entry without timeout — errors",
+			rule: EntryTimeoutMissingRule{Entry: "api"},
+			edges: []Edge{
+				{Source: "gateway", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db"},
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := tc.rule
+			vs := rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "entry-timeout-missing", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 19: SinglePointOfFailureRule
+// ---------------------------------------------------------------------------
+
+func TestSinglePointOfFailureRule(t *testing.T) {
+	chain := []Edge{{Source: "A", Target: "B"}, {Source: "B", Target: "C"}}
+	diamond := []Edge{
+		{Source: "A", Target: "B"}, {Source: "A", Target: "D"},
+		{Source: "B", Target: "C"}, {Source: "D", Target: "C"},
+	}
+	tests := []struct {
+		name  string
+		graph *mockGraph
+		want  []string
+	}{
+		{name: "single-replica middle of a chain — warns", graph: newMockGraph(chain...).withNodes(Node{Name: "B", Replicas: 1}), want: []string{"B"}},
+		{name: "redundant middle of a chain — clean", graph: newMockGraph(chain...).withNodes(Node{Name: "B", Replicas: 3})},
+		{name: "undeclared replicas — skipped", graph: newMockGraph(chain...)},
+		{name: "diamond — clean", graph: newMockGraph(diamond...).withNodes(
+			Node{Name: "B", Replicas: 1}, Node{Name: "C", Replicas: 1}, Node{Name: "D", Replicas: 1})},
+	}
+
+	rule := &SinglePointOfFailureRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(tc.graph)
+			var got []string
+			for _, v := range vs {
+				if v.Rule == "single-point-of-failure" {
+					got = append(got, v.Path[0])
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 20: InsecureBoundaryCallRule
+// ---------------------------------------------------------------------------
+
+func TestInsecureBoundaryCallRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "insecure boundary call — errors", edge: Edge{Source: "api", Target: "partner", CrossesBoundary: true}, want: true},
+		{name: "secure boundary call — clean", edge: Edge{Source: "api", Target: "partner", CrossesBoundary: true, Secure: true}, want: false},
+		{name: "insecure internal call — clean", edge: Edge{Source: "api", Target: "db"}, want: false},
+	}
+
+	rule := &InsecureBoundaryCallRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "insecure-boundary-call", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 21: ZeroBackoffRetryRule
+// ---------------------------------------------------------------------------
+
+func TestZeroBackoffRetryRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "retries without backoff base — warns", edge: Edge{Source: "A", Target: "B", MaxRetries: 3}, want: true},
+		{name: "retries with backoff base — clean", edge: Edge{Source: "A", Target: "B", MaxRetries: 3, BackoffBase: 200 * time.Millisecond}, want: false},
+		{name: "no retries — clean", edge: Edge{Source: "A", Target: "B"}, want: false},
+	}
+
+	rule := &ZeroBackoffRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "zero-backoff-retry", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 22: UnboundedRetryRule
+// ---------------------------------------------------------------------------
+
+func TestUnboundedRetryRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "retries without timeout — errors", edge: Edge{Source: "A", Target: "B", MaxRetries: 2}, want: true},
+		{name: "retries with timeout — clean", edge: Edge{Source: "A", Target: "B", MaxRetries: 2, Timeout: time.Second}, want: false},
+		{name: "no retries and no timeout — not this rule", edge: Edge{Source: "A", Target: "B"}, want: false},
+	}
+
+	rule := &UnboundedRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "unbounded-retry", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 23: NonMonotonicBudgetRule
+// ---------------------------------------------------------------------------
+
+func TestNonMonotonicBudgetRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    []Edge
+		wantHint string // "" means clean
+	}{
+		{
+			name: "monotonically shrinking — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 3 * time.Second},
+				{Source: "C", Target: "D", Timeout: time.Second},
+			},
+		},
+		{
+			name: "plateau mid-path — warns at that hop",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 3 * time.Second},
+				{Source: "C", Target: "D", Timeout: 3 * time.Second},
+			},
+			wantHint: "edge C->D",
+		},
+		{
+			name: "growth mid-path — warns at the first bad hop only",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 6 * time.Second},
+				{Source: "C", Target: "D", Timeout: 7 * time.Second},
+			},
+			wantHint: "edge B->C",
+		},
+	}
+
+	rule := &NonMonotonicBudgetRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edges...))
+			if tc.wantHint == "" {
+				if len(vs) != 0 {
+					t.Errorf("expected clean, got %+v", vs)
+				}
+				return
+			}
+			if len(vs) != 1 || vs[0].Rule != "non-monotonic-budget" || vs[0].SourceHint != tc.wantHint {
+				t.Errorf("want one violation at %s, got %+v", tc.wantHint, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 24: MissingBulkheadRule
+// ---------------------------------------------------------------------------
+
+func TestMissingBulkheadRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "retried without concurrency cap — warns", edge: Edge{Source: "A", Target: "B", MaxRetries: 2}, want: true},
+		{name: "retried with concurrency cap — clean", edge: Edge{Source: "A", Target: "B", MaxRetries: 2, MaxConcurrency: 50}, want: false},
+		{name: "not retried — clean", edge: Edge{Source: "A", Target: "B"}, want: false},
+	}
+
+	rule := &MissingBulkheadRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "missing-bulkhead", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 25: AsyncRetryMismatchRule
+// ---------------------------------------------------------------------------
+
+func TestAsyncRetryMismatchRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{name: "async edge with retries — notes", edge: Edge{Source: "orders", Target: "events", Async: true, MaxRetries: 3}, want: true},
+		{name: "sync edge with retries — clean", edge: Edge{Source: "orders", Target: "events", MaxRetries: 3}, want: false},
+		{name: "async edge without retries — clean", edge: Edge{Source: "orders", Target: "events", Async: true}, want: false},
+	}
+
+	rule := &AsyncRetryMismatchRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "async-retry-mismatch", "info"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 26: ScatterGatherTimeoutRule
+// ---------------------------------------------------------------------------
+
+func TestScatterGatherTimeoutRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "coherent siblings — clean",
+			edges: []Edge{
+				{Source: "agg", Target: "a", Timeout: time.Second},
+				{Source: "agg", Target: "b", Timeout: 2 * time.Second},
+				{Source: "agg", Target: "c", Timeout: 1500 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			name: "10x outlier — warns",
+			edges: []Edge{
+				{Source: "agg", Target: "a", Timeout: 500 * time.Millisecond},
+				{Source: "agg", Target: "b", Timeout: 600 * time.Millisecond},
+				{Source: "agg", Target: "slow", Timeout: 5 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "single downstream — clean",
+			edges: []Edge{
+				{Source: "agg", Target: "a", Timeout: 30 * time.Second},
+				{Source: "a", Target: "b", Timeout: time.Second},
+			},
+			want: false,
+		},
+	}
+
+	rule := &ScatterGatherTimeoutRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edges...))
+			if got := hasRule(vs, "scatter-gather-timeout"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if tc.want && len(vs) > 0 && vs[0].SourceHint != "edge agg->slow" {
+				t.Errorf("want the outlier edge reported, got %s", vs[0].SourceHint)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 27: TimeoutBelowMinProcessingRule
+// ---------------------------------------------------------------------------
+
+func TestTimeoutBelowMinProcessingRule(t *testing.T) {
+	report := Node{Name: "report", MinProcessingTime: 2 * time.Second}
+	tests := []struct {
+		name  string
+		graph *mockGraph
+		want  bool
+	}{
+		{
+			name:  "timeout below floor — errors",
+			graph: newMockGraph(Edge{Source: "api", Target: "report", Timeout: time.Second}).withNodes(report),
+			want:  true,
+		},
+		{
+			name:  "timeout above floor — clean",
+			graph: newMockGraph(Edge{Source: "api", Target: "report", Timeout: 5 * time.Second}).withNodes(report),
+			want:  false,
+		},
+		{
+			name:  "no declared floor — clean",
+			graph: newMockGraph(Edge{Source: "api", Target: "report", Timeout: time.Second}),
+			want:  false,
+		},
+	}
+
+	rule := &TimeoutBelowMinProcessingRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(tc.graph)
+			if got := hasSeverity(vs, "timeout-below-min-processing", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 28: RepeatedServiceOnPathRule
+// ---------------------------------------------------------------------------
+
+func TestRepeatedServiceOnPathRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  []string
+	}{
+		{
+			name: "diamond — bottom reached twice",
+			edges: []Edge{
+				{Source: "A", Target: "B"}, {Source: "A", Target: "D"},
+				{Source: "B", Target: "C"}, {Source: "D", Target: "C"},
+			},
+			want: []string{"C"},
+		},
+		{
+			// E and F are reached twice too, but only because C is.
+			name: "diamond with a tail — only the join",
+			edges: []Edge{
+				{Source: "A", Target: "B"}, {Source: "A", Target: "D"},
+				{Source: "B", Target: "C"}, {Source: "D", Target: "C"},
+				{Source: "C", Target: "E"}, {Source: "E", Target: "F"},
+			},
+			want: []string{"C"},
+		},
+		{
+			name: "tree — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"}, {Source: "B", Target: "C"}, {Source: "B", Target: "D"},
+			},
+		},
+		{
+			name: "cycle back to a reached service — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"}, {Source: "B", Target: "C"}, {Source: "C", Target: "B"},
+			},
+		},
+	}
+
+	rule := &RepeatedServiceOnPathRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edges...))
+			var got []string
+			for _, v := range vs {
+				got = append(got, v.Path[0])
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if len(vs) > 0 && !strings.Contains(vs[0].Message, "2 distinct call chains joining from B, D") {
+				t.Errorf("unexpected message: %s", vs[0].Message)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 29: MissingAggregateDeadlineRule
+// ---------------------------------------------------------------------------
+
+func TestMissingAggregateDeadlineRule(t *testing.T) {
+	fanOut := []Edge{
+		{Source: "agg", Target: "a", Timeout: time.Second},
+		{Source: "agg", Target: "b", Timeout: 10 * time.Second},
+	}
+	tests := []struct {
+		name  string
+		graph *mockGraph
+		want  bool
+	}{
+		{name: "aggregator without deadline — warns", graph: newMockGraph(fanOut...), want: true},
+		{
+			name:  "aggregator with deadline — clean",
+			graph: newMockGraph(fanOut...).withNodes(Node{Name: "agg", AggregateTimeout: 3 * time.Second}),
+			want:  false,
+		},
+		{
+			name: "children with short timeouts — clean",
+			graph: newMockGraph(
+				Edge{Source: "agg", Target: "a", Timeout: time.Second},
+				Edge{Source: "agg", Target: "b", Timeout: 2 * time.Second},
+			),
+			want: false,
+		},
+		{
+			name:  "single child — clean",
+			graph: newMockGraph(Edge{Source: "agg", Target: "b", Timeout: 10 * time.Second}),
+			want:  false,
+		},
+	}
+
+	rule := &MissingAggregateDeadlineRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(tc.graph)
+			if got := hasSeverity(vs, "missing-aggregate-deadline", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 30: GlobalRequestCapRule
+// ---------------------------------------------------------------------------
+
+func TestGlobalRequestCapRule(t *testing.T) {
+	// gateway fans out to 4 services with 3 attempts each; each fans out to
+	// 3 backends with 4 attempts: 4*3 + 4*3*3*4 = 156 requests, although no
+	// single backend receives more than 48.
+	var edges []Edge
+	for _, mid := range []string{"a", "b", "c", "d"} {
+		edges = append(edges, Edge{Source: "gateway", Target: mid, MaxRetries: 2})
+		for _, leaf := range []string{"db", "cache", "search"} {
+			edges = append(edges, Edge{Source: mid, Target: leaf, MaxRetries: 3})
+		}
+	}
+	tests := []struct {
+		name string
+		cap  int
+		want bool
+	}{
+		{name: "over cap — errors", cap: 100, want: true},
+		{name: "at cap — clean", cap: 156, want: false},
+		{name: "default cap — clean", cap: 0, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&GlobalRequestCapRule{Cap: tc.cap}).Check(newMockGraph(edges...))
+			if got := hasSeverity(vs, "global-request-cap", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if tc.want && (len(vs) != 1 || !strings.Contains(vs[0].Message, "up to 156")) {
+				t.Errorf("want one finding for gateway reporting 156, got %+v", vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 31: InconsistentIdempotencyRule
+// ---------------------------------------------------------------------------
+
+func TestNormalizePath(t *testing.T) {
+	locale := regexp.MustCompile(`^[a-z]{2}-[a-z]{2}$`)
+	tests := map[string]string{
+		"/users/{id}":         "/users/{}",
+		"/Users/42/":          "/users/{}",
+		"/users/:userId/cart": "/users/{}/cart",
+		"/orders/3f2504e0-4f89-11d3-9a0c-0305e82c3301?expand=items": "/orders/{}",
+		"/en-gb/products": "/{}/products",
+		"/":               "/",
+	}
+	for in, want := range tests {
+		if got := NormalizePath(in, locale); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInconsistentIdempotencyRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph *mockGraph
+		want  bool
+	}{
+		{
+			name: "same-shaped endpoints with conflicting idempotency — warns",
+			graph: newMockGraph(
+				Edge{Source: "web", Target: "users", Method: "PUT", Endpoint: "/users/{id}/email", Idempotent: true},
+				Edge{Source: "mobile", Target: "accounts", Method: "put", Endpoint: "/users/:uid/email", Idempotent: false},
+			),
+			want: true,
+		},
+		{
+			name: "same-shaped endpoints with consistent idempotency — clean",
+			graph: newMockGraph(
+				Edge{Source: "web", Target: "users", Method: "PUT", Endpoint: "/users/{id}/email", Idempotent: true},
+				Edge{Source: "mobile", Target: "accounts", Method: "PUT", Endpoint: "/users/17/email", Idempotent: true},
+			),
+			want: false,
+		},
+		{
+			name: "different methods — clean",
+			graph: newMockGraph(
+				Edge{Source: "web", Target: "users", Method: "PUT", Endpoint: "/users/{id}", Idempotent: true},
+				Edge{Source: "web", Target: "users", Method: "POST", Endpoint: "/users/{id}", Idempotent: false},
+			),
+			want: false,
+		},
+		{
+			name: "no endpoints — clean",
+			graph: newMockGraph(
+				Edge{Source: "web", Target: "users", Method: "PUT", Idempotent: true},
+				Edge{Source: "mobile", Target: "accounts", Method: "PUT", Idempotent: false},
+			),
+			want: false,
+		},
+	}
+
+	rule := &InconsistentIdempotencyRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(tc.graph)
+			if got := hasSeverity(vs, "inconsistent-idempotency", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+func TestInconsistentIdempotencyParamPatterns(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "web", Target: "catalog", Method: "PUT", Endpoint: "/en-gb/products", Idempotent: true},
+		Edge{Source: "app", Target: "catalog", Method: "PUT", Endpoint: "/fr-fr/products", Idempotent: false},
+	)
+	if vs := (&InconsistentIdempotencyRule{}).Check(g); len(vs) != 0 {
+		t.Errorf("locales are distinct paths by default, got %+v", vs)
+	}
+	rule := &InconsistentIdempotencyRule{ParamPatterns: []string{`^[a-z]{2}-[a-z]{2}$`}}
+	if vs := rule.Check(g); len(vs) != 1 {
+		t.Errorf("want one violation once locales are parameters, got %+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 32: TimeoutMatchesProxyRule
+// ---------------------------------------------------------------------------
+
+func TestTimeoutMatchesProxyRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    *TimeoutMatchesProxyRule
+		timeout time.Duration
+		want    bool
+	}{
+		{name: "exact match of default 60s — warns", rule: &TimeoutMatchesProxyRule{}, timeout: 60 * time.Second, want: true},
+		{name: "within epsilon — warns", rule: &TimeoutMatchesProxyRule{}, timeout: 59500 * time.Millisecond, want: true},
+		{name: "comfortably below — clean", rule: &TimeoutMatchesProxyRule{}, timeout: 50 * time.Second, want: false},
+		{
+			name:    "declared proxy timeout — warns",
+			rule:    &TimeoutMatchesProxyRule{ProxyTimeouts: []time.Duration{30 * time.Second}},
+			timeout: 30 * time.Second,
+			want:    true,
+		},
+		{
+			name:    "declared proxies replace the default — clean",
+			rule:    &TimeoutMatchesProxyRule{ProxyTimeouts: []time.Duration{30 * time.Second}},
+			timeout: 60 * time.Second,
+			want:    false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(Edge{Source: "web", Target: "api", Timeout: tc.timeout})
+			vs := tc.rule.Check(g)
+			if got := hasSeverity(vs, "timeout-matches-proxy", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 33: InTransactionRetryRule
+// ---------------------------------------------------------------------------
+
+func TestInTransactionRetryRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{
+			name: "retried in-transaction call — errors",
+			edge: Edge{Source: "orders", Target: "inventory", Timeout: time.Second, MaxRetries: 2, InTransaction: true},
+			want: true,
+		},
+		{
+			name: "retried call outside a transaction — clean",
+			edge: Edge{Source: "orders", Target: "inventory", Timeout: time.Second, MaxRetries: 2},
+			want: false,
+		},
+		{
+			name: "unretried in-transaction call — clean",
+			edge: Edge{Source: "orders", Target: "inventory", Timeout: time.Second, InTransaction: true},
+			want: false,
+		},
+	}
+
+	rule := &InTransactionRetryRule{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "in-transaction-retry", "error"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 34: SuspiciousTimeoutMagnitudeRule
+// ---------------------------------------------------------------------------
+
+func TestSuspiciousTimeoutMagnitudeRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *SuspiciousTimeoutMagnitudeRule
+		edge Edge
+		want bool
+	}{
+		{
+			name: "10-minute HTTP timeout — warns",
+			rule: &SuspiciousTimeoutMagnitudeRule{},
+			edge: Edge{Source: "web", Target: "api", Timeout: 10 * time.Minute},
+			want: true,
+		},
+		{
+			name: "3s HTTP timeout — clean",
+			rule: &SuspiciousTimeoutMagnitudeRule{},
+			edge: Edge{Source: "web", Target: "api", Timeout: 3 * time.Second},
+			want: false,
+		},
+		{
+			name: "3µs timeout — warns",
+			rule: &SuspiciousTimeoutMagnitudeRule{},
+			edge: Edge{Source: "web", Target: "api", Timeout: 3 * time.Microsecond},
+			want: true,
+		},
+		{
+			name: "async call — clean",
+			rule: &SuspiciousTimeoutMagnitudeRule{},
+			edge: Edge{Source: "web", Target: "queue", Timeout: 10 * time.Minute, Async: true},
+			want: false,
+		},
+		{
+			name: "raised bound — clean",
+			rule: &SuspiciousTimeoutMagnitudeRule{Max: 15 * time.Minute},
+			edge: Edge{Source: "batch", Target: "export", Timeout: 10 * time.Minute},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "suspicious-timeout-magnitude", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 35: CircuitBreakerHalfOpenRule
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerHalfOpenRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *CircuitBreakerHalfOpenRule
+		edge Edge
+		want bool
+	}{
+		{
+			name: "sane half-open count — clean",
+			rule: &CircuitBreakerHalfOpenRule{},
+			edge: Edge{Source: "api", Target: "db", HasCircuitBreaker: true, CBHalfOpenRequests: 3},
+			want: false,
+		},
+		{
+			name: "excessive half-open count — warns",
+			rule: &CircuitBreakerHalfOpenRule{},
+			edge: Edge{Source: "api", Target: "db", HasCircuitBreaker: true, CBHalfOpenRequests: 50},
+			want: true,
+		},
+		{
+			name: "large relative to max_concurrency — warns",
+			rule: &CircuitBreakerHalfOpenRule{},
+			edge: Edge{Source: "api", Target: "db", HasCircuitBreaker: true, CBHalfOpenRequests: 5, MaxConcurrency: 20},
+			want: true,
+		},
+		{
+			name: "unset — clean by default",
+			rule: &CircuitBreakerHalfOpenRule{},
+			edge: Edge{Source: "api", Target: "db", HasCircuitBreaker: true},
+			want: false,
+		},
+		{
+			name: "unset with RequireDeclared — warns",
+			rule: &CircuitBreakerHalfOpenRule{RequireDeclared: true},
+			edge: Edge{Source: "api", Target: "db", HasCircuitBreaker: true},
+			want: true,
+		},
+		{
+			name: "no circuit breaker — clean",
+			rule: &CircuitBreakerHalfOpenRule{RequireDeclared: true},
+			edge: Edge{Source: "api", Target: "db", CBHalfOpenRequests: 50},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "cb-half-open-probes", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 36: TimeoutHeadroomRule
+// ---------------------------------------------------------------------------
+
+func TestTimeoutHeadroomRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *TimeoutHeadroomRule
+		edge Edge
+		want bool
+	}{
+		{
+			name: "timeout equal to processing time — warns",
+			rule: &TimeoutHeadroomRule{},
+			edge: Edge{Source: "api", Target: "search", Timeout: time.Second, ProcessingTime: time.Second},
+			want: true,
+		},
+		{
+			name: "10% headroom below the 20% default — warns",
+			rule: &TimeoutHeadroomRule{},
+			edge: Edge{Source: "api", Target: "search", Timeout: 1100 * time.Millisecond, ProcessingTime: time.Second},
+			want: true,
+		},
+		{
+			name: "adequate headroom — clean",
+			rule: &TimeoutHeadroomRule{},
+			edge: Edge{Source: "api", Target: "search", Timeout: 1500 * time.Millisecond, ProcessingTime: time.Second},
+			want: false,
+		},
+		{
+			name: "custom overhead factor — warns",
+			rule: &TimeoutHeadroomRule{OverheadFactor: 1},
+			edge: Edge{Source: "api", Target: "search", Timeout: 1500 * time.Millisecond, ProcessingTime: time.Second},
+			want: true,
+		},
+		{
+			name: "no declared processing time — clean",
+			rule: &TimeoutHeadroomRule{},
+			edge: Edge{Source: "api", Target: "search", Timeout: time.Millisecond},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "timeout-no-headroom", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 37: BackoffLatencyInflationRule
+// ---------------------------------------------------------------------------
+
+func TestBackoffLatencyInflationRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  *BackoffLatencyInflationRule
+		edges []Edge
+		want  bool
+	}{
+		{
+			// Worst case 2 × 400ms = 800ms fits the 1s budget, but a slow
+			// attempt plus a 500ms backoff on top of the 300ms p99 does not.
+			name: "worst case within budget, backoff pushes p99 over — warns",
+			rule: &BackoffLatencyInflationRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 400 * time.Millisecond, MaxRetries: 1, BackoffBase: 500 * time.Millisecond, P99: 300 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "short backoff keeps p99 within budget — clean",
+			rule: &BackoffLatencyInflationRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 400 * time.Millisecond, MaxRetries: 1, BackoffBase: 50 * time.Millisecond, P99: 300 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			name: "inflation from a downstream retried hop — warns",
+			rule: &BackoffLatencyInflationRule{Budget: 800 * time.Millisecond},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second, P99: 200 * time.Millisecond},
+				{Source: "B", Target: "C", Timeout: 300 * time.Millisecond, MaxRetries: 2, BackoffBase: 400 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "no declared p99 — clean",
+			rule: &BackoffLatencyInflationRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 400 * time.Millisecond, MaxRetries: 1, BackoffBase: 5 * time.Second},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "backoff-latency-inflation", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 38: CircuitBreakerOpenWindowRule
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerOpenWindowRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  *CircuitBreakerOpenWindowRule
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "open window beyond configured entry timeout — warns",
+			rule: &CircuitBreakerOpenWindowRule{EntryTimeout: 5 * time.Second},
+			edges: []Edge{
+				{Source: "gw", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db", Timeout: time.Second, HasCircuitBreaker: true, CBOpenTimeout: 30 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "open window within configured entry timeout — clean",
+			rule: &CircuitBreakerOpenWindowRule{EntryTimeout: 5 * time.Second},
+			edges: []Edge{
+				{Source: "gw", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db", Timeout: time.Second, HasCircuitBreaker: true, CBOpenTimeout: 2 * time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "entry timeout taken from the first hop — warns",
+			rule: &CircuitBreakerOpenWindowRule{},
+			edges: []Edge{
+				{Source: "gw", Target: "api", Timeout: 3 * time.Second},
+				{Source: "api", Target: "db", Timeout: time.Second, HasCircuitBreaker: true, CBOpenTimeout: 10 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "no declared open window — clean",
+			rule: &CircuitBreakerOpenWindowRule{EntryTimeout: time.Second},
+			edges: []Edge{
+				{Source: "gw", Target: "api", Timeout: time.Second, HasCircuitBreaker: true},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "cb-open-window-exceeds-entry", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 39: CircuitBreakerCountsPerCallRule
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerCountsPerCallRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *CircuitBreakerCountsPerCallRule
+		edge Edge
+		want bool
+	}{
+		{
+			name: "per-call counting with heavy retries — warns",
+			rule: &CircuitBreakerCountsPerCallRule{},
+			edge: Edge{Source: "api", Target: "db", MaxRetries: 4, HasCircuitBreaker: true, CBCounts: CBCountsPerCall},
+			want: true,
+		},
+		{
+			name: "per-attempt counting — clean",
+			rule: &CircuitBreakerCountsPerCallRule{},
+			edge: Edge{Source: "api", Target: "db", MaxRetries: 4, HasCircuitBreaker: true, CBCounts: CBCountsPerAttempt},
+			want: false,
+		},
+		{
+			name: "per-call counting with a single retry — clean",
+			rule: &CircuitBreakerCountsPerCallRule{},
+			edge: Edge{Source: "api", Target: "db", MaxRetries: 1, HasCircuitBreaker: true, CBCounts: CBCountsPerCall},
+			want: false,
+		},
+		{
+			name: "custom threshold — warns",
+			rule: &CircuitBreakerCountsPerCallRule{MinRetries: 1},
+			edge: Edge{Source: "api", Target: "db", MaxRetries: 1, HasCircuitBreaker: true, CBCounts: CBCountsPerCall},
+			want: true,
+		},
+		{
+			name: "undeclared counting — clean",
+			rule: &CircuitBreakerCountsPerCallRule{},
+			edge: Edge{Source: "api", Target: "db", MaxRetries: 4, HasCircuitBreaker: true},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "cb-counts-per-call", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 40: UnknownTargetEndpointRule
+// ---------------------------------------------------------------------------
+
+func TestUnknownTargetEndpointRule(t *testing.T) {
+	users := Node{Name: "user-svc", Endpoints: []string{"GET /users/{id}", "POST /users", "/health"}}
+	tests := []struct {
+		name string
+		rule *UnknownTargetEndpointRule
+		edge Edge
+		want bool
+	}{
+		{
+			name: "matching method and path — clean",
+			rule: &UnknownTargetEndpointRule{},
+			edge: Edge{Source: "api", Target: "user-svc", Method: "GET", Endpoint: "/users/42"},
+			want: false,
+		},
+		{
+			name: "endpoint declared without a method matches any method — clean",
+			rule: &UnknownTargetEndpointRule{},
+			edge: Edge{Source: "api", Target: "user-svc", Method: "HEAD", Endpoint: "/health/"},
+			want: false,
+		},
+		{
+			name: "nonexistent path — warns",
+			rule: &UnknownTargetEndpointRule{},
+			edge: Edge{Source: "api", Target: "user-svc", Method: "GET", Endpoint: "/accounts/42"},
+			want: true,
+		},
+		{
+			name: "path exists under another method — warns",
+			rule: &UnknownTargetEndpointRule{},
+			edge: Edge{Source: "api", Target: "user-svc", Method: "DELETE", Endpoint: "/users/42"},
+			want: true,
+		},
+		{
+			name: "call without endpoint — clean",
+			rule: &UnknownTargetEndpointRule{},
+			edge: Edge{Source: "api", Target: "user-svc", Method: "GET"},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edge).withNodes(users))
+			if got := hasSeverity(vs, "unknown-target-endpoint", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+func TestUnknownTargetEndpointSkipsUndeclaredTargets(t *testing.T) {
+	g := newMockGraph(Edge{Source: "api", Target: "user-svc", Method: "GET", Endpoint: "/anything"})
+	if vs := (&UnknownTargetEndpointRule{}).Check(g); len(vs) != 0 {
+		t.Errorf("target without declared endpoints should be skipped, got %+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 41: WriteBetweenRetriedReadsRule
+// ---------------------------------------------------------------------------
+
+func TestWriteBetweenRetriedReadsRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "retried read → unretried write → retried read — warns",
+			edges: []Edge{
+				{Source: "gw", Target: "orders", Idempotent: true, MaxRetries: 2},
+				{Source: "orders", Target: "payments", Idempotent: false},
+				{Source: "payments", Target: "ledger", Idempotent: true, MaxRetries: 2},
+			},
+			want: true,
+		},
+		{
+			name: "all idempotent — clean",
+			edges: []Edge{
+				{Source: "gw", Target: "orders", Idempotent: true, MaxRetries: 2},
+				{Source: "orders", Target: "payments", Idempotent: true},
+				{Source: "payments", Target: "ledger", Idempotent: true, MaxRetries: 2},
+			},
+			want: false,
+		},
+		{
+			name: "upstream read not retried — clean",
+			edges: []Edge{
+				{Source: "gw", Target: "orders", Idempotent: true},
+				{Source: "orders", Target: "payments", Idempotent: false},
+				{Source: "payments", Target: "ledger", Idempotent: true, MaxRetries: 2},
+			},
+			want: false,
+		},
+		{
+			name: "write itself retried — left to non-idempotent-retry, clean",
+			edges: []Edge{
+				{Source: "gw", Target: "orders", Idempotent: true, MaxRetries: 2},
+				{Source: "orders", Target: "payments", Idempotent: false, MaxRetries: 1},
+				{Source: "payments", Target: "ledger", Idempotent: true, MaxRetries: 2},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&WriteBetweenRetriedReadsRule{}).Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "write-between-retried-reads", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 42: RetryTailLatencyRule
+// ---------------------------------------------------------------------------
+
+func TestRetryTailLatencyRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *RetryTailLatencyRule
+		edges    []Edge
+		want     bool
+		wantHint string
+	}{
+		{
+			// Single attempts take 200ms + 150ms = 350ms. With retries
+			// A->B takes 2×200ms + 50ms and B->C 3×150ms + 100ms + 200ms,
+			// 1.2s in all; B->C adds the most.
+			name: "retries push the tail over budget — warns",
+			rule: &RetryTailLatencyRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second, MaxRetries: 1, BackoffBase: 50 * time.Millisecond, P99: 200 * time.Millisecond},
+				{Source: "B", Target: "C", Timeout: time.Second, MaxRetries: 2, BackoffBase: 100 * time.Millisecond, P99: 150 * time.Millisecond},
+			},
+			want:     true,
+			wantHint: "edge B->C",
+		},
+		{
+			name: "same percentiles without retries — clean",
+			rule: &RetryTailLatencyRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second, P99: 200 * time.Millisecond},
+				{Source: "B", Target: "C", Timeout: time.Second, P99: 150 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			// 3 attempts × min(p99 900ms, timeout 300ms) = 900ms fits 1s.
+			name: "timeout caps each attempt — clean",
+			rule: &RetryTailLatencyRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 300 * time.Millisecond, MaxRetries: 2, P99: 900 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			name: "tighter budget — warns",
+			rule: &RetryTailLatencyRule{Budget: 500 * time.Millisecond},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second, MaxRetries: 2, BackoffBase: 10 * time.Millisecond, P99: 200 * time.Millisecond},
+			},
+			want:     true,
+			wantHint: "edge A->B",
+		},
+		{
+			name: "no declared p99 — clean",
+			rule: &RetryTailLatencyRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second, MaxRetries: 5, BackoffBase: time.Second},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "retry-tail-amplification", "warning"); got != tc.want {
+				t.Fatalf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if tc.want && vs[0].SourceHint != tc.wantHint {
+				t.Errorf("SourceHint = %q, want %q", vs[0].SourceHint, tc.wantHint)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 43: UntunedUniformTimeoutRule
+// ---------------------------------------------------------------------------
+
+func TestUntunedUniformTimeoutRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "same timeout on every hop of a 3-hop path — notes",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 5 * time.Second},
+				{Source: "C", Target: "D", Timeout: 5 * time.Second},
+			},
+			want: true,
+		},
+		{
+			name: "decrementing timeouts — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 3 * time.Second},
+				{Source: "C", Target: "D", Timeout: time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "uniform but only 2 hops — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 5 * time.Second},
+				{Source: "B", Target: "C", Timeout: 5 * time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "no timeouts at all — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"},
+				{Source: "B", Target: "C"},
+				{Source: "C", Target: "D"},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&UntunedUniformTimeoutRule{}).Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "uniform-path-timeout", "info"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 44: RetryIntoFixedCapacityRule
+// ---------------------------------------------------------------------------
+
+func TestRetryIntoFixedCapacityRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		nodes []Node
+		want  bool
+	}{
+		{
+			name:  "retried call into a fixed-capacity target — warns",
+			edges: []Edge{{Source: "A", Target: "B", MaxRetries: 2}},
+			nodes: []Node{{Name: "B", FixedCapacity: true, Capacity: 500}},
+			want:  true,
+		},
+		{
+			name:  "retried call into an autoscaling target — clean",
+			edges: []Edge{{Source: "A", Target: "B", MaxRetries: 2}},
+			nodes: []Node{{Name: "B"}},
+			want:  false,
+		},
+		{
+			name:  "unretried call into a fixed-capacity target — clean",
+			edges: []Edge{{Source: "A", Target: "B"}},
+			nodes: []Node{{Name: "B", FixedCapacity: true}},
+			want:  false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&RetryIntoFixedCapacityRule{}).Check(newMockGraph(tc.edges...).withNodes(tc.nodes...))
+			if got := hasSeverity(vs, "retry-into-fixed-capacity", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 45: ChattyChainRule
+// ---------------------------------------------------------------------------
+
+func TestChattyChainRule(t *testing.T) {
+	chain := func(hops int, timeout time.Duration) []Edge {
+		var edges []Edge
+		for i := 0; i < hops; i++ {
+			edges = append(edges, Edge{Source: fmt.Sprintf("S%d", i), Target: fmt.Sprintf("S%d", i+1), Timeout: timeout})
+		}
+		return edges
+	}
+	tests := []struct {
+		name  string
+		rule  *ChattyChainRule
+		edges []Edge
+		want  bool
+	}{
+		{name: "8 hops of 20ms — notes", rule: &ChattyChainRule{}, edges: chain(8, 20*time.Millisecond), want: true},
+		{name: "3 hops of 20ms — clean", rule: &ChattyChainRule{}, edges: chain(3, 20*time.Millisecond), want: false},
+		{name: "8 hops of 500ms — clean", rule: &ChattyChainRule{}, edges: chain(8, 500*time.Millisecond), want: false},
+		{name: "3 hops under a lower hop threshold — notes", rule: &ChattyChainRule{MinHops: 3}, edges: chain(3, 20*time.Millisecond), want: true},
+		{name: "8 hops above a tighter mean — clean", rule: &ChattyChainRule{MaxMeanTimeout: 10 * time.Millisecond}, edges: chain(8, 20*time.Millisecond), want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "chatty-chain", "info"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 46: MutualRetryRule
+// ---------------------------------------------------------------------------
+
+func TestMutualRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  int
+	}{
+		{
+			name: "both directions retried — errors once",
+			edges: []Edge{
+				{Source: "A", Target: "B", MaxRetries: 2},
+				{Source: "B", Target: "A", MaxRetries: 1},
+			},
+			want: 1,
+		},
+		{
+			name: "only one direction retried — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", MaxRetries: 2},
+				{Source: "B", Target: "A"},
+			},
+			want: 0,
+		},
+		{
+			name: "mutual calls without retries — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"},
+				{Source: "B", Target: "A"},
+			},
+			want: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&MutualRetryRule{}).Check(newMockGraph(tc.edges...))
+			if len(vs) != tc.want {
+				t.Fatalf("want %d violations, got %+v", tc.want, vs)
+			}
+			if tc.want > 0 && !hasSeverity(vs, "mutual-retry", "error") {
+				t.Errorf("want a mutual-retry error, got %+v", vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 47: WastedLeafRetryRule
+// ---------------------------------------------------------------------------
+
+// walkCounter counts the paths a rule asks for.
+type walkCounter struct {
+	*mockGraph
+	walked int
+}
+
+func (g *walkCounter) WalkPaths(fn func(path []Edge) bool) {
+	g.mockGraph.WalkPaths(func(path []Edge) bool {
+		g.walked++
+		return fn(path)
+	})
+}
+
+func TestWastedLeafRetryStopsOnceEveryLeafIsReported(t *testing.T) {
+	// The one retried leaf is reported on the first path; the other
+	// branches cannot add a finding and are not walked.
+	g := &walkCounter{mockGraph: newMockGraph(
+		Edge{Source: "A", Target: "B", Timeout: time.Second},
+		Edge{Source: "B", Target: "C", Timeout: 900 * time.Millisecond, MaxRetries: 2},
+		Edge{Source: "A", Target: "D", Timeout: time.Second},
+		Edge{Source: "A", Target: "E", Timeout: time.Second},
+	)}
+	vs := (&WastedLeafRetryRule{}).Check(g)
+	if len(vs) != 1 || g.walked != 1 {
+		t.Errorf("want 1 violation after walking 1 path, got %d after %d; %+v", len(vs), g.walked, vs)
+	}
+}
+
+func TestWastedLeafRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  *WastedLeafRetryRule
+		edges []Edge
+		want  bool
+	}{
+		{
+			// Retries start at 200ms and 500ms, inside the 3s budget.
+			name: "leaf retries fit the path budget — clean",
+			rule: &WastedLeafRetryRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second},
+				{Source: "C", Target: "D", Timeout: 100 * time.Millisecond, MaxRetries: 2, BackoffBase: 100 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			// B->C's 1s is the tightest deadline; the second retry starts
+			// at 2×400ms + 100ms + 200ms = 1.1s.
+			name: "tightest upstream timeout cuts off a retry — warns",
+			rule: &WastedLeafRetryRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second},
+				{Source: "C", Target: "D", Timeout: 400 * time.Millisecond, MaxRetries: 2, BackoffBase: 100 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			// 3s less 2.5s of upstream processing leaves 500ms.
+			name: "upstream processing exhausts the budget — warns",
+			rule: &WastedLeafRetryRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second, ProcessingTime: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: 3 * time.Second, ProcessingTime: 500 * time.Millisecond},
+				{Source: "C", Target: "D", Timeout: 400 * time.Millisecond, MaxRetries: 1, BackoffBase: 100 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "entry timeout tighter than the path — warns",
+			rule: &WastedLeafRetryRule{EntryTimeout: 500 * time.Millisecond},
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second},
+				{Source: "B", Target: "C", Timeout: 400 * time.Millisecond, MaxRetries: 1, BackoffBase: 100 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "no upstream deadline — clean",
+			rule: &WastedLeafRetryRule{},
+			edges: []Edge{
+				{Source: "A", Target: "B"},
+				{Source: "B", Target: "C", Timeout: 400 * time.Millisecond, MaxRetries: 3},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "wasted-leaf-retry", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 48: FirstRetryImpossibleRule
+// ---------------------------------------------------------------------------
+
+func TestFirstRetryImpossibleRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "first backoff fits the remaining budget — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second, MaxRetries: 2, BackoffBase: 200 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			// 2s - 1s attempt leaves 1s; the first wait is 1.5s.
+			name: "first backoff exceeds the remaining budget — warns",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second, MaxRetries: 2, BackoffBase: 1500 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "first attempt alone exhausts the budget — left to timeout-inversion",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second, MaxRetries: 1, BackoffBase: 5 * time.Second},
+			},
+			want: false,
+		},
+		{
+			name: "no retries — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 2 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second, BackoffBase: 5 * time.Second},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&FirstRetryImpossibleRule{}).Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "first-retry-impossible", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 49: EffectiveTimeoutInversionRule
+// ---------------------------------------------------------------------------
+
+func TestEffectiveTimeoutInversionRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "nominal timeouts fine, retries invert — warns",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second, MaxRetries: 1},
+			},
+			want: true,
+		},
+		{
+			name: "all attempts fit — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: 3 * time.Second},
+				{Source: "B", Target: "C", Timeout: time.Second, MaxRetries: 2},
+			},
+			want: false,
+		},
+		{
+			name: "nominal inversion — left to timeout-inversion",
+			edges: []Edge{
+				{Source: "A", Target: "B", Timeout: time.Second},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second, MaxRetries: 1},
+			},
+			want: false,
+		},
+		{
+			name: "caller without timeout — clean",
+			edges: []Edge{
+				{Source: "A", Target: "B"},
+				{Source: "B", Target: "C", Timeout: 2 * time.Second, MaxRetries: 3},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGraph(tc.edges...)
+			vs := (&EffectiveTimeoutInversionRule{}).Check(g)
+			if got := hasSeverity(vs, "effective-timeout-inversion", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+			if tc.want && hasRule((&OrphanedRetryRule{}).Check(g), "orphaned-retry") {
+				t.Error("orphaned-retry should not report a call effective-timeout-inversion reports")
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 50: ChokepointRule
+// ---------------------------------------------------------------------------
+
+func TestChokepointRule(t *testing.T) {
+	hub := func(callers, callees int) []Edge {
+		var edges []Edge
+		for i := 0; i < callers; i++ {
+			edges = append(edges, Edge{Source: fmt.Sprintf("caller%d", i), Target: "hub"})
+		}
+		for i := 0; i < callees; i++ {
+			edges = append(edges, Edge{Source: "hub", Target: fmt.Sprintf("dep%d", i)})
+		}
+		return edges
+	}
+	tests := []struct {
+		name  string
+		rule  *ChokepointRule
+		edges []Edge
+		want  bool
+	}{
+		{name: "high fan-in and fan-out — warns", rule: &ChokepointRule{}, edges: hub(4, 5), want: true},
+		{name: "high fan-in only — clean", rule: &ChokepointRule{}, edges: hub(6, 1), want: false},
+		{name: "high fan-out only — clean", rule: &ChokepointRule{}, edges: hub(1, 6), want: false},
+		{name: "low on both — clean", rule: &ChokepointRule{}, edges: hub(2, 2), want: false},
+		{name: "lower thresholds — warns", rule: &ChokepointRule{MinCallers: 2, MinCallees: 2}, edges: hub(2, 2), want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "chokepoint", "warning"); got != tc.want {
+				t.Fatalf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+	vs := (&ChokepointRule{}).Check(newMockGraph(hub(4, 5)...))
+	if len(vs) != 1 || !strings.Contains(vs[0].Message, "4 callers") || !strings.Contains(vs[0].Message, "5 downstreams") {
+		t.Errorf("message should report both degrees: %+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 51: DeterministicErrorRetryRule
+// ---------------------------------------------------------------------------
+
+func TestDeterministicErrorRetryRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		nodes []Node
+		want  bool
+	}{
+		{
+			name:  "retried call into a deterministic-error service — warns",
+			edges: []Edge{{Source: "A", Target: "validator", MaxRetries: 3}},
+			nodes: []Node{{Name: "validator", DeterministicErrors: true}},
+			want:  true,
+		},
+		{
+			name:  "retried call into a normal service — clean",
+			edges: []Edge{{Source: "A", Target: "B", MaxRetries: 3}},
+			nodes: []Node{{Name: "B"}},
+			want:  false,
+		},
+		{
+			name:  "unretried call into a deterministic-error service — clean",
+			edges: []Edge{{Source: "A", Target: "validator"}},
+			nodes: []Node{{Name: "validator", DeterministicErrors: true}},
+			want:  false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&DeterministicErrorRetryRule{}).Check(newMockGraph(tc.edges...).withNodes(tc.nodes...))
+			if got := hasSeverity(vs, "retry-deterministic-errors", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 52: ConflictingIdempotencyRule
+// ---------------------------------------------------------------------------
+
+func TestConflictingIdempotencyRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "callers agree the target is idempotent — clean",
+			edges: []Edge{
+				{Source: "A", Target: "D", Idempotent: true, MaxRetries: 2},
+				{Source: "B", Target: "D", Idempotent: true},
+			},
+			want: false,
+		},
+		{
+			name: "one caller retries as idempotent, another does not — warns",
+			edges: []Edge{
+				{Source: "A", Target: "D", Idempotent: true, MaxRetries: 2},
+				{Source: "B", Target: "D"},
+			},
+			want: true,
+		},
+		{
+			name: "different methods on the target — clean",
+			edges: []Edge{
+				{Source: "A", Target: "D", Method: "GET", Idempotent: true, MaxRetries: 2},
+				{Source: "B", Target: "D", Method: "POST"},
+			},
+			want: false,
+		},
+		{
+			name: "both calls name endpoints — left to inconsistent-idempotency",
+			edges: []Edge{
+				{Source: "A", Target: "D", Endpoint: "/a", Idempotent: true, MaxRetries: 2},
+				{Source: "B", Target: "D", Endpoint: "/b"},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&ConflictingIdempotencyRule{}).Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "conflicting-idempotency", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 53: SelfCallRule
+// ---------------------------------------------------------------------------
+
+func TestSelfCallRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    []Edge
+		severity string
+	}{
+		{
+			name:     "retried self-call — error",
+			edges:    []Edge{{Source: "A", Target: "A", MaxRetries: 2}},
+			severity: "error",
+		},
+		{
+			name:     "unretried self-call — warning",
+			edges:    []Edge{{Source: "A", Target: "A"}},
+			severity: "warning",
+		},
+		{
+			name:  "call to another service — clean",
+			edges: []Edge{{Source: "A", Target: "B", MaxRetries: 2}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&SelfCallRule{}).Check(newMockGraph(tc.edges...))
+			if tc.severity == "" {
+				if len(vs) != 0 {
+					t.Errorf("expected no violations, got %+v", vs)
+				}
+				return
+			}
+			if !hasSeverity(vs, "self-call", tc.severity) {
+				t.Errorf("expected a %s self-call violation, got %+v", tc.severity, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 54: IgnoredBackpressureRetryRule
+// ---------------------------------------------------------------------------
+
+func TestIgnoredBackpressureRetryRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{
+			name: "retries ignoring Retry-After — warns",
+			edge: Edge{Source: "A", Target: "B", MaxRetries: 3, IgnoresRetryAfter: true},
+			want: true,
+		},
+		{
+			name: "retries honoring Retry-After — clean",
+			edge: Edge{Source: "A", Target: "B", MaxRetries: 3},
+			want: false,
+		},
+		{
+			name: "no retries — clean",
+			edge: Edge{Source: "A", Target: "B", IgnoresRetryAfter: true},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&IgnoredBackpressureRetryRule{}).Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "retry-ignores-backpressure", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 55: FanOutMissingTimeoutRule
+// ---------------------------------------------------------------------------
+
+func TestFanOutMissingTimeoutRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  int
+	}{
+		{
+			name: "one sibling without a timeout — errors",
+			edges: []Edge{
+				{Source: "agg", Target: "A", Timeout: time.Second},
+				{Source: "agg", Target: "B"},
+				{Source: "agg", Target: "C", Timeout: 2 * time.Second},
+			},
+			want: 1,
+		},
+		{
+			name: "every sibling has a timeout — clean",
+			edges: []Edge{
+				{Source: "agg", Target: "A", Timeout: time.Second},
+				{Source: "agg", Target: "B", Timeout: time.Second},
+			},
+			want: 0,
+		},
+		{
+			name:  "single untimed call is not a fan-out — clean",
+			edges: []Edge{{Source: "agg", Target: "A"}},
+			want:  0,
+		},
+		{
+			name: "untimed async sibling is not awaited — clean",
+			edges: []Edge{
+				{Source: "agg", Target: "A", Timeout: time.Second},
+				{Source: "agg", Target: "B", Timeout: time.Second},
+				{Source: "agg", Target: "queue", Async: true},
+			},
+			want: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&FanOutMissingTimeoutRule{}).Check(newMockGraph(tc.edges...))
+			if len(vs) != tc.want {
+				t.Fatalf("want %d violations, got %+v", tc.want, vs)
+			}
+			if tc.want > 0 && !hasSeverity(vs, "fan-out-missing-timeout", "error") {
+				t.Errorf("want an error, got %+v", vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 56: UnobservedResilienceRule
+// ---------------------------------------------------------------------------
+
+func TestUnobservedResilienceRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{
+			name: "non-observable retried edge — notes",
+			edge: Edge{Source: "A", Target: "B", MaxRetries: 2, Timeout: time.Second, Unobservable: true},
+			want: true,
+		},
+		{
+			name: "observable retried edge — clean",
+			edge: Edge{Source: "A", Target: "B", MaxRetries: 2, Timeout: time.Second},
+			want: false,
+		},
+		{
+			name: "non-observable edge without retries or timeout — clean",
+			edge: Edge{Source: "A", Target: "B", Unobservable: true},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&UnobservedResilienceRule{}).Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "unobserved-resilience", "info"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 57: BudgetAllocationRule
+// ---------------------------------------------------------------------------
+
+func TestBudgetAllocationRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  BudgetAllocationRule
+		edges []Edge
+		want  bool
+	}{
+		{
+			name: "balanced allocation — clean",
+			edges: []Edge{
+				{Source: "gw", Target: "A", Timeout: 800 * time.Millisecond},
+				{Source: "A", Target: "B", Timeout: 600 * time.Millisecond},
+				{Source: "B", Target: "C", Timeout: 600 * time.Millisecond},
+			},
+			want: false,
+		},
+		{
+			name: "one hop takes 90% of the path — warns",
+			edges: []Edge{
+				{Source: "gw", Target: "A", Timeout: 9 * time.Second},
+				{Source: "A", Target: "B", Timeout: 500 * time.Millisecond},
+				{Source: "B", Target: "C", Timeout: 500 * time.Millisecond},
+			},
+			want: true,
+		},
+		{
+			name: "retries count toward the hop's share — warns",
+			rule: BudgetAllocationRule{EntryTimeout: 10 * time.Second},
+			edges: []Edge{
+				{Source: "gw", Target: "A", Timeout: time.Second},
+				{Source: "A", Target: "B", Timeout: 2 * time.Second, MaxRetries: 3},
+			},
+			want: true,
+		},
+		{
+			name:  "single hop — clean",
+			edges: []Edge{{Source: "gw", Target: "A", Timeout: 9 * time.Second}},
+			want:  false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := tc.rule.Check(newMockGraph(tc.edges...))
+			if got := hasSeverity(vs, "budget-allocation", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+	vs := (&BudgetAllocationRule{}).Check(newMockGraph(
+		Edge{Source: "gw", Target: "A", Timeout: 8 * time.Second},
+		Edge{Source: "A", Target: "B", Timeout: time.Second},
+		Edge{Source: "B", Target: "C", Timeout: time.Second},
+	))
+	if len(vs) != 1 || !strings.Contains(vs[0].Message, "gw->A 80%, A->B 10%, B->C 10%") {
+		t.Errorf("want each hop's share in the message, got %+v", vs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 58: UnreachableCBThresholdRule
+// ---------------------------------------------------------------------------
+
+func TestUnreachableCBThresholdRule(t *testing.T) {
+	tests := []struct {
+		name string
+		edge Edge
+		want bool
+	}{
+		{
+			name: "threshold above the attempts of one request — warns",
+			edge: Edge{Source: "A", Target: "B", HasCircuitBreaker: true, MaxRetries: 3,
+				CBFailureThreshold: 20, CBWindow: CBWindowPerRequest},
+			want: true,
+		},
+		{
+			name: "threshold within the attempts of one request — clean",
+			edge: Edge{Source: "A", Target: "B", HasCircuitBreaker: true, MaxRetries: 3,
+				CBFailureThreshold: 4, CBWindow: CBWindowPerRequest},
+			want: false,
+		},
+		{
+			name: "per-call counting sees one failure per request — warns",
+			edge: Edge{Source: "A", Target: "B", HasCircuitBreaker: true, MaxRetries: 3,
+				CBFailureThreshold: 2, CBWindow: CBWindowPerRequest, CBCounts: CBCountsPerCall},
+			want: true,
+		},
+		{
+			name: "shared window accumulates across requests — clean",
+			edge: Edge{Source: "A", Target: "B", HasCircuitBreaker: true, MaxRetries: 3,
+				CBFailureThreshold: 20, CBWindow: CBWindowShared},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&UnreachableCBThresholdRule{}).Check(newMockGraph(tc.edge))
+			if got := hasSeverity(vs, "cb-threshold-unreachable", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rule 59: MutatingRetryWithoutKeyRule
+// ---------------------------------------------------------------------------
+
+func TestMutatingRetryWithoutKeyRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		edge  Edge
+		nodes []Node
+		want  bool
+	}{
+		{
+			name:  "retried POST to a target without idempotency keys — warns",
+			edge:  Edge{Source: "A", Target: "orders", Method: "POST", Endpoint: "/orders", MaxRetries: 2, Idempotent: true},
+			nodes: []Node{{Name: "orders"}},
+			want:  true,
+		},
+		{
+			name:  "retried POST to a target supporting idempotency keys — clean",
+			edge:  Edge{Source: "A", Target: "orders", Method: "POST", Endpoint: "/orders", MaxRetries: 2},
+			nodes: []Node{{Name: "orders", SupportsIdempotencyKey: true}},
+			want:  false,
+		},
+		{
+			name:  "retried GET — clean",
+			edge:  Edge{Source: "A", Target: "orders", Method: "GET", Endpoint: "/orders", MaxRetries: 2},
+			nodes: []Node{{Name: "orders"}},
+			want:  false,
+		},
+		{
+			name:  "unretried POST — clean",
+			edge:  Edge{Source: "A", Target: "orders", Method: "POST", Endpoint: "/orders"},
+			nodes: []Node{{Name: "orders"}},
+			want:  false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := (&MutatingRetryWithoutKeyRule{}).Check(newMockGraph(tc.edge).withNodes(tc.nodes...))
+			if got := hasSeverity(vs, "unkeyed-mutating-retry", "warning"); got != tc.want {
+				t.Errorf("expected violation=%v, got %v; violations=%+v", tc.want, got, vs)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Verify all rules satisfy the Rule interface at compile time
 // ---------------------------------------------------------------------------
@@ -450,3 +3086,56 @@ var _ Rule = (*NonIdempotentRetryRule)(nil)
 var _ Rule = (*RetryWithoutCircuitBreakerRule)(nil)
 var _ Rule = (*BackoffWithoutJitterRule)(nil)
 var _ Rule = (*EndToEndTimeoutExceedRule)(nil)
+var _ Rule = (*ReadAfterWriteRetryRule)(nil)
+var _ Rule = (*UniformConfigRule)(nil)
+var _ Rule = (*BidirectionalTimeoutRule)(nil)
+var _ Rule = (*RetriesExceedReplicasRule)(nil)
+var _ Rule = (*OrphanedRetryRule)(nil)
+var _ Rule = (*TierViolationRule)(nil)
+var _ Rule = (*IdempotentMethodMismatchRule)(nil)
+var _ Rule = (*SlowDownstreamRetryRule)(nil)
+var _ Rule = (*BackendRequestCapRule)(nil)
+var _ Rule = (*RetryOnHealthCheckRule)(nil)
+var _ Rule = (*JitterWithoutBackoffRule)(nil)
+var _ Rule = (*EntryTimeoutMissingRule)(nil)
+var _ Rule = (*SinglePointOfFailureRule)(nil)
+var _ Rule = (*InsecureBoundaryCallRule)(nil)
+var _ Rule = (*ZeroBackoffRetryRule)(nil)
+var _ Rule = (*UnboundedRetryRule)(nil)
+var _ Rule = (*NonMonotonicBudgetRule)(nil)
+var _ Rule = (*MissingBulkheadRule)(nil)
+var _ Rule = (*AsyncRetryMismatchRule)(nil)
+var _ Rule = (*ScatterGatherTimeoutRule)(nil)
+var _ Rule = (*TimeoutBelowMinProcessingRule)(nil)
+var _ Rule = (*RepeatedServiceOnPathRule)(nil)
+var _ Rule = (*MissingAggregateDeadlineRule)(nil)
+var _ Rule = (*GlobalRequestCapRule)(nil)
+var _ Rule = (*InconsistentIdempotencyRule)(nil)
+var _ Rule = (*TimeoutMatchesProxyRule)(nil)
+var _ Rule = (*InTransactionRetryRule)(nil)
+var _ Rule = (*SuspiciousTimeoutMagnitudeRule)(nil)
+var _ Rule = (*CircuitBreakerHalfOpenRule)(nil)
+var _ Rule = (*TimeoutHeadroomRule)(nil)
+var _ Rule = (*BackoffLatencyInflationRule)(nil)
+var _ Rule = (*CircuitBreakerOpenWindowRule)(nil)
+var _ Rule = (*CircuitBreakerCountsPerCallRule)(nil)
+var _ Rule = (*UnknownTargetEndpointRule)(nil)
+var _ Rule = (*WriteBetweenRetriedReadsRule)(nil)
+var _ Rule = (*RetryTailLatencyRule)(nil)
+var _ Rule = (*UntunedUniformTimeoutRule)(nil)
+var _ Rule = (*RetryIntoFixedCapacityRule)(nil)
+var _ Rule = (*ChattyChainRule)(nil)
+var _ Rule = (*MutualRetryRule)(nil)
+var _ Rule = (*WastedLeafRetryRule)(nil)
+var _ Rule = (*FirstRetryImpossibleRule)(nil)
+var _ Rule = (*EffectiveTimeoutInversionRule)(nil)
+var _ Rule = (*ChokepointRule)(nil)
+var _ Rule = (*DeterministicErrorRetryRule)(nil)
+var _ Rule = (*ConflictingIdempotencyRule)(nil)
+var _ Rule = (*SelfCallRule)(nil)
+var _ Rule = (*IgnoredBackpressureRetryRule)(nil)
+var _ Rule = (*FanOutMissingTimeoutRule)(nil)
+var _ Rule = (*UnobservedResilienceRule)(nil)
+var _ Rule = (*BudgetAllocationRule)(nil)
+var _ Rule = (*UnreachableCBThresholdRule)(nil)
+var _ Rule = (*MutatingRetryWithoutKeyRule)(nil)
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scope restricts the part of the topology a rule inspects.
+type Scope string
+
+const (
+	// ScopeAll leaves the graph unfiltered; it is the default.
+	ScopeAll Scope = "all"
+	// ScopeEntryEdges keeps only calls made by entry services (callers
+	// nothing else calls).
+	ScopeEntryEdges Scope = "entry-edges"
+	// ScopeLeafEdges keeps only calls to leaf services (callees that call
+	// nothing else).
+	ScopeLeafEdges Scope = "leaf-edges"
+)
+
+// ParseScope validates a scope name. The empty string selects ScopeAll.
+func ParseScope(s string) (Scope, error) {
+	switch sc := Scope(s); sc {
+	case "":
+		return ScopeAll, nil
+	case ScopeAll, ScopeEntryEdges, ScopeLeafEdges:
+		return sc, nil
+	}
+	return "", fmt.Errorf("unknown scope %q (want all, entry-edges or leaf-edges)", s)
+}
+
+// ScopedRule runs Rule against the part of the graph selected by Scope.
+type ScopedRule struct {
+	Rule  Rule
+	Scope Scope
+}
+
+func (r *ScopedRule) Check(graph CallGraph) []Violation {
+	return r.Rule.Check(Scoped(graph, r.Scope))
+}
+
+// Scoped returns a view of cg in which AllEdges only reports edges in scope
+// and each path is cut down to its in-scope edges (its first edge for
+// entry-edges, its last for leaf-edges). OutEdges, InEdges and Node are
+// unchanged so rules can still look at the neighbours of an in-scope edge.
+// A fully cyclic graph has no entry edges.
+func Scoped(cg CallGraph, scope Scope) CallGraph {
+	if scope == "" || scope == ScopeAll {
+		return cg
+	}
+	return &scopedGraph{cg: cg, scope: scope}
+}
+
+type scopedGraph struct {
+	cg    CallGraph
+	scope Scope
+}
+
+func (s *scopedGraph) inScope(e Edge) bool {
+	switch s.scope {
+	case ScopeEntryEdges:
+		return len(s.cg.InEdges(e.Source)) == 0
+	case ScopeLeafEdges:
+		return len(s.cg.OutEdges(e.Target)) == 0
+	}
+	return true
+}
+
+func (s *scopedGraph) filter(edges []Edge) []Edge {
+	var out []Edge
+	for _, e := range edges {
+		if s.inScope(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *scopedGraph) AllEdges() []Edge            { return s.filter(s.cg.AllEdges()) }
+func (s *scopedGraph) OutEdges(node string) []Edge { return s.cg.OutEdges(node) }
+func (s *scopedGraph) InEdges(node string) []Edge  { return s.cg.InEdges(node) }
+func (s *scopedGraph) Node(name string) Node       { return s.cg.Node(name) }
+
+// WalkPaths reports each distinct in-scope remainder of the underlying
+// paths once.
+func (s *scopedGraph) WalkPaths(fn func(path []Edge) bool) {
+	seen := make(map[string]bool)
+	s.cg.WalkPaths(func(p []Edge) bool {
+		kept := s.filter(p)
+		if len(kept) == 0 {
+			return true
+		}
+		key := strings.Join(pathNodes(kept), "\x00")
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return fn(kept)
+	})
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseScope(t *testing.T) {
+	for in, want := range map[string]Scope{"": ScopeAll, "all": ScopeAll,
+		"entry-edges": ScopeEntryEdges, "leaf-edges": ScopeLeafEdges} {
+		if got, err := ParseScope(in); err != nil || got != want {
+			t.Errorf("ParseScope(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseScope("roots"); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
+
+// chain is gateway -> api -> db, with retries and no circuit breaker on
+// every call.
+func chain() *mockGraph {
+	return newMockGraph(
+		Edge{Source: "gateway", Target: "api", Timeout: time.Second, MaxRetries: 2},
+		Edge{Source: "api", Target: "db", Timeout: time.Second, MaxRetries: 2},
+	)
+}
+
+func TestScopedRuleLeafEdges(t *testing.T) {
+	rule := &ScopedRule{Rule: &RetryWithoutCircuitBreakerRule{}, Scope: ScopeLeafEdges}
+	vs := rule.Check(chain())
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0].Path, []string{"api", "db"}) {
+		t.Fatalf("want only the api->db leaf edge flagged, got %+v", vs)
+	}
+}
+
+func TestScopedRuleEntryEdges(t *testing.T) {
+	rule := &ScopedRule{Rule: &RetryWithoutCircuitBreakerRule{}, Scope: ScopeEntryEdges}
+	vs := rule.Check(chain())
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0].Path, []string{"gateway", "api"}) {
+		t.Fatalf("want only the gateway->api entry edge flagged, got %+v", vs)
+	}
+}
+
+func TestScopedPaths(t *testing.T) {
+	g := newMockGraph(
+		Edge{Source: "gateway", Target: "api"},
+		Edge{Source: "api", Target: "db"},
+		Edge{Source: "api", Target: "cache"},
+	)
+	got := paths(Scoped(g, ScopeEntryEdges))
+	if len(got) != 1 || len(got[0]) != 1 || got[0][0].Target != "api" {
+		t.Errorf("entry-edges paths: want [[gateway->api]], got %+v", got)
+	}
+	if got := len(paths(Scoped(g, ScopeLeafEdges))); got != 2 {
+		t.Errorf("leaf-edges paths: want 2, got %d", got)
+	}
+	if Scoped(g, ScopeAll) != CallGraph(g) {
+		t.Error("ScopeAll should return the graph unchanged")
+	}
+}
+
+var _ Rule = (*ScopedRule)(nil)
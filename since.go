@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// checkSinceRef rejects a -since ref that git would read as an option, such
+// as --output=<file>.
+func checkSinceRef(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid -since %q: a git ref cannot start with \"-\"", ref)
+	}
+	return nil
+}
+
+// changedFiles lists the files that differ between ref and the working tree,
+// relative to dir, using git diff --name-only.
+func changedFiles(dir, ref string) ([]string, error) {
+	if err := checkSinceRef(ref); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", ref, "--")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("git diff %s: %s", ref, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("git diff %s: %w", ref, err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, filepath.ToSlash(line))
+		}
+	}
+	return files, nil
+}
+
+// servicesForFiles maps changed files to the services that own them. A
+// service owns a file when one of its sources is the file itself, a
+// directory containing it, or a glob (path.Match syntax) matching it.
+// Services without sources own nothing.
+func servicesForFiles(files []string, services map[string]Service) map[string]bool {
+	touched := make(map[string]bool)
+	for name, s := range services {
+		for _, src := range s.Sources {
+			if ownsAny(path.Clean(filepath.ToSlash(src)), files) {
+				touched[name] = true
+				break
+			}
+		}
+	}
+	return touched
+}
+
+func ownsAny(src string, files []string) bool {
+	for _, f := range files {
+		f = path.Clean(f)
+		if f == src || src == "." || strings.HasPrefix(f, src+"/") {
+			return true
+		}
+		if ok, _ := path.Match(src, f); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyTouched keeps the findings that involve a call owned (made) by a
+// touched service. Findings about a single service are kept when that
+// service is touched; findings without a path are dropped.
+func onlyTouched(findings []Finding, touched map[string]bool) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		keep := len(f.Path) == 1 && touched[f.Path[0]]
+		for i := 0; i+1 < len(f.Path) && !keep; i++ {
+			keep = touched[f.Path[i]]
+		}
+		if keep {
+			out = append(out, f)
+		}
+	}
+	return out
+}
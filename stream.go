@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/rules"
+)
+
+// streamRules are the rules-package detectors that judge each call on its
+// own, needing no neighbours, paths or service attributes. Only these run
+// in stream mode, together with timeout inversion and the built-in retry
+// checks; path-based and whole-graph rules are skipped.
+var streamRules = map[string]bool{
+	"idempotent-method-mismatch":   true,
+	"retry-on-health-check":        true,
+	"jitter-no-backoff":            true,
+	"insecure-boundary-call":       true,
+	"unbounded-retry":              true,
+	"async-retry-mismatch":         true,
+	"timeout-matches-proxy":        true,
+	"in-transaction-retry":         true,
+	"suspicious-timeout-magnitude": true,
+	"cb-half-open-probes":          true,
+	"timeout-no-headroom":          true,
+	"cb-counts-per-call":           true,
+	"cb-threshold-unreachable":     true,
+	"zero-backoff-retry":           true,
+	"missing-bulkhead":             true,
+	"retry-ignores-backpressure":   true,
+	"unobserved-resilience":        true,
+}
+
+// timedCall is the part of a call timeout inversion needs to remember.
+type timedCall struct {
+	Source, Target string
+	Timeout        time.Duration
+}
+
+// StreamAnalyzer runs the per-call checks on calls one at a time, for
+// topologies too large to materialize as a Graph. Timeout inversion keeps
+// only each call's endpoints and timeout; every other check sees a single
+// call and keeps nothing. Findings match those the same checks report in
+// Graph.Analyze, but without blast radius.
+type StreamAnalyzer struct {
+	g        *Graph // configuration only; holds no edges
+	rules    []rules.Rule
+	out, in  map[string][]timedCall
+	low      map[[2]string]bool // low-confidence calls
+	findings []Finding
+}
+
+// NewStreamAnalyzer returns a StreamAnalyzer configured by opts. Opt-in
+// rules run when enabled and single-call; rule scopes, plugins and
+// namespace settings do not apply (runStream refuses the last two).
+func NewStreamAnalyzer(opts Options) *StreamAnalyzer {
+	g := &Graph{Config: opts.Rules, Enabled: toSet(opts.Enable), MinConfidence: opts.MinConfidence}
+	byID := g.extraRules()
+	for id := range g.Enabled {
+		if newRule, ok := optInRules[id]; ok {
+			byID[id] = newRule()
+		}
+	}
+	var ids []string
+	for id := range byID {
+		if streamRules[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	s := &StreamAnalyzer{g: g, out: make(map[string][]timedCall), in: make(map[string][]timedCall),
+		low: make(map[[2]string]bool)}
+	for _, id := range ids {
+		s.rules = append(s.rules, byID[id])
+	}
+	return s
+}
+
+// Add checks one call, including for timeout inversion against the calls
+// added before it.
+func (s *StreamAnalyzer) Add(e CallEdge) {
+	t := timedCall{e.Source, e.Target, e.Timeout}
+	for _, d := range s.out[e.Target] {
+		if e.Timeout > 0 && d.Timeout > e.Timeout {
+			s.findings = append(s.findings, inversionFinding(e, CallEdge{Source: d.Source, Target: d.Target, Timeout: d.Timeout}))
+		}
+	}
+	for _, u := range s.in[e.Source] {
+		if u.Timeout > 0 && e.Timeout > u.Timeout {
+			s.findings = append(s.findings, inversionFinding(CallEdge{Source: u.Source, Target: u.Target, Timeout: u.Timeout}, e))
+		}
+	}
+	s.out[e.Source] = append(s.out[e.Source], t)
+	s.in[e.Target] = append(s.in[e.Target], t)
+
+	s.findings = append(s.findings, retryFindings(e)...)
+	one := singleCall(toRuleEdge(e))
+	for _, r := range s.rules {
+		for _, v := range r.Check(one) {
+			s.findings = append(s.findings, Finding{Rule: v.Rule, Severity: v.Severity, Message: v.Message, Path: v.Path})
+		}
+	}
+	if e.Confidence > 0 && e.Confidence < s.g.MinConfidence {
+		s.low[[2]string{e.Source, e.Target}] = true
+	}
+}
+
+// Findings returns the findings so far, with the confidence downgrade
+// applied, in the usual severity order.
+func (s *StreamAnalyzer) Findings() []Finding {
+	f := append([]Finding(nil), s.findings...)
+	if s.g.MinConfidence > 0 {
+		f = s.g.downgradeUncertain(f, s.low)
+	}
+	sortFindings(f)
+	return f
+}
+
+// singleCall is a rules.CallGraph holding one call.
+type singleCall rules.Edge
+
+func (c singleCall) AllEdges() []rules.Edge { return []rules.Edge{rules.Edge(c)} }
+
+func (c singleCall) OutEdges(node string) []rules.Edge {
+	if node == c.Source {
+		return c.AllEdges()
+	}
+	return nil
+}
+
+func (c singleCall) InEdges(node string) []rules.Edge {
+	if node == c.Target {
+		return c.AllEdges()
+	}
+	return nil
+}
+
+func (c singleCall) Node(name string) rules.Node { return rules.Node{Name: name} }
+
+func (c singleCall) WalkPaths(fn func([]rules.Edge) bool) { fn(c.AllEdges()) }
+
+// runStream analyzes the topology at path with a StreamAnalyzer, feeding it
+// each call as it is converted. The outputs that draw the graph (mermaid,
+// svg) are not available, and the text report omits its topology sketch.
+func runStream(path, format string, opts Options, specs []outputSpec, stdout, stderr io.Writer) int {
+	for _, spec := range specs {
+		if spec.Format == "mermaid" || spec.Format == "svg" {
+			fmt.Fprintf(stderr, "error: -stream does not support %s output\n", spec.Format)
+			return 2
+		}
+	}
+	// Plugins need the whole graph and namespace settings need every
+	// service's namespace; stream mode has neither.
+	if len(opts.Plugins) > 0 || len(opts.Namespaces) > 0 {
+		fmt.Fprintln(stderr, "error: -stream cannot be combined with plugins or namespaces settings")
+		return 2
+	}
+	topo, err := loadTopology(path, format)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	s := NewStreamAnalyzer(opts)
+	if _, err := eachTopologyEdge(topo, s.Add); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	findings := s.Findings()
+	tmpls, _ := parseMessageTemplates(opts.Messages, opts.MessageVars) // checked by Validate
+	if err := applyMessageTemplates(findings, tmpls, opts.MessageVars); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if err := writeOutputs(specs, path, nil, nil, findings, stdout); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	return opts.failPolicy().exitCode(findings)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// runSummary is the machine-readable digest written by -summary-json.
+type runSummary struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByRule     map[string]int `json:"by_rule"`
+	Failed     bool           `json:"failed"`
+	ElapsedMs  int64          `json:"elapsed_ms"`
+	// RecommendedEntryTimeoutMs is the critical path's worst-case latency
+	// (see graph.RecommendEntryTimeout).
+	RecommendedEntryTimeoutMs int64 `json:"recommended_entry_timeout_ms"`
+}
+
+func newRunSummary(findings []Finding, failed bool, elapsed time.Duration) runSummary {
+	s := runSummary{
+		Total:      len(findings),
+		BySeverity: make(map[string]int),
+		ByRule:     make(map[string]int),
+		Failed:     failed,
+		ElapsedMs:  elapsed.Milliseconds(),
+	}
+	for _, f := range findings {
+		s.BySeverity[f.Severity]++
+		s.ByRule[f.Rule]++
+	}
+	return s
+}
+
+// writeSummary writes s as a single line of JSON.
+func writeSummary(s runSummary, w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
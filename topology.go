@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cascadeguard/cascadeguard/parser"
+	"github.com/cascadeguard/cascadeguard/rules"
+)
+
+// inputFormats maps -input values to the parser for that file format.
+var inputFormats = map[string]func(io.Reader) (*parser.RawTopology, error){
+	"topology":     parser.ParseTopology,
+	"resilience4j": parser.ParseResilience4j,
+	"openapi":      parser.ParseOpenAPI,
+}
+
+// loadTopology reads and parses the file at path in the given input format,
+// merging in the services of every file it includes.
+func loadTopology(path, format string) (*parser.RawTopology, error) {
+	parse, ok := inputFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown input format %q (want topology, resilience4j or openapi)", format)
+	}
+	l := includeLoader{parse: parse, loaded: make(map[string]bool)}
+	return l.load(path, nil)
+}
+
+// includeLoader resolves includes directives. A file reached twice through
+// different includes is merged once; a file that includes itself, directly
+// or through others, is an error.
+type includeLoader struct {
+	parse  func(io.Reader) (*parser.RawTopology, error)
+	loaded map[string]bool // absolute paths already merged
+}
+
+// load parses path and merges its includes, resolved relative to path.
+// chain holds the files currently being loaded above path.
+func (l *includeLoader) load(path string, chain []string) (*parser.RawTopology, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range chain {
+		if p == abs {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain[i:], abs), " -> "))
+		}
+	}
+	l.loaded[abs] = true
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	topo, err := l.parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	chain = append(chain, abs)
+	for _, inc := range topo.Includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		if incAbs, _ := filepath.Abs(inc); l.loaded[incAbs] && !slices.Contains(chain, incAbs) {
+			continue
+		}
+		sub, err := l.load(inc, chain)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeTopology(topo, sub, inc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	topo.Includes = nil
+	return topo, nil
+}
+
+// mergeTopology adds the services of an included topology to topo. A
+// service may be defined in only one file. The including file's tier
+// policy wins; otherwise the first included one applies.
+func mergeTopology(topo, inc *parser.RawTopology, incPath string) error {
+	if topo.Services == nil {
+		topo.Services = make(map[string]parser.RawService)
+	}
+	for name, svc := range inc.Services {
+		if _, dup := topo.Services[name]; dup {
+			return fmt.Errorf("service %q from %s is already defined", name, incPath)
+		}
+		topo.Services[name] = svc
+	}
+	if topo.TierPolicy == nil {
+		topo.TierPolicy = inc.TierPolicy
+	}
+	return nil
+}
+
+// topologyEdges converts a parsed topology into call edges and per-service
+// attributes, validating values along the way. Services are visited in name
+// order so the edge list is the same on every run.
+func topologyEdges(topo *parser.RawTopology) ([]CallEdge, map[string]Service, error) {
+	var edges []CallEdge
+	services, err := eachTopologyEdge(topo, func(e CallEdge) {
+		edges = append(edges, e)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return edges, services, nil
+}
+
+// eachTopologyEdge is topologyEdges without collecting the edges: it passes
+// each call to fn as soon as it is converted, so a caller that does not
+// need the whole edge list need not hold it.
+func eachTopologyEdge(topo *parser.RawTopology, fn func(CallEdge)) (map[string]Service, error) {
+	services := make(map[string]Service)
+	aliases, err := topo.Aliases()
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range topo.ServiceNames() {
+		sc := topo.Services[svc]
+		if sc.Replicas < 0 {
+			return nil, fmt.Errorf("%s replicas must be non-negative", svc)
+		}
+		if sc.Capacity < 0 {
+			return nil, fmt.Errorf("%s capacity must be non-negative", svc)
+		}
+		var minProc time.Duration
+		if sc.MinProcessingTime != "" {
+			var err error
+			minProc, err = time.ParseDuration(sc.MinProcessingTime)
+			if err != nil {
+				return nil, fmt.Errorf("%s invalid min_processing_time %q: %v", svc, sc.MinProcessingTime, err)
+			}
+		}
+		var aggregate time.Duration
+		if sc.AggregateTimeout != "" {
+			var err error
+			aggregate, err = time.ParseDuration(sc.AggregateTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s invalid aggregate_timeout %q: %v", svc, sc.AggregateTimeout, err)
+			}
+		}
+		services[svc] = Service{Tier: sc.Tier, Namespace: sc.Namespace, Replicas: sc.Replicas, MinProcessingTime: minProc,
+			AggregateTimeout: aggregate, Sources: sc.Sources, Endpoints: sc.Endpoints,
+			Autoscaling: sc.Autoscaling, Capacity: sc.Capacity, DeterministicErrors: sc.DeterministicErrors,
+			SupportsIdempotencyKey: sc.SupportsIdempotencyKey}
+		for _, c := range sc.Calls {
+			var t time.Duration
+			if c.Timeout != "" {
+				var err error
+				t, err = time.ParseDuration(c.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("%s->%s invalid timeout %q: %v", svc, c.Target, c.Timeout, err)
+				}
+			}
+			var backoff time.Duration
+			if c.BackoffBase != "" {
+				var err error
+				backoff, err = time.ParseDuration(c.BackoffBase)
+				if err != nil {
+					return nil, fmt.Errorf("%s->%s invalid backoff_base %q: %v", svc, c.Target, c.BackoffBase, err)
+				}
+			}
+			var processing time.Duration
+			if c.ProcessingTime != "" {
+				var err error
+				processing, err = time.ParseDuration(c.ProcessingTime)
+				if err != nil {
+					return nil, fmt.Errorf("%s->%s invalid processing_time %q: %v", svc, c.Target, c.ProcessingTime, err)
+				}
+			}
+			var p99 time.Duration
+			if c.P99 != "" {
+				var err error
+				p99, err = time.ParseDuration(c.P99)
+				if err != nil {
+					return nil, fmt.Errorf("%s->%s invalid p99 %q: %v", svc, c.Target, c.P99, err)
+				}
+			}
+			var cbOpen time.Duration
+			if c.CBOpenTimeout != "" {
+				var err error
+				cbOpen, err = time.ParseDuration(c.CBOpenTimeout)
+				if err != nil {
+					return nil, fmt.Errorf("%s->%s invalid cb_open_timeout %q: %v", svc, c.Target, c.CBOpenTimeout, err)
+				}
+			}
+			if c.Retries < 0 {
+				return nil, fmt.Errorf("%s->%s retries must be non-negative", svc, c.Target)
+			}
+			if c.CBHalfOpenRequests < 0 {
+				return nil, fmt.Errorf("%s->%s cb_half_open_requests must be non-negative", svc, c.Target)
+			}
+			if c.MaxConcurrency < 0 {
+				return nil, fmt.Errorf("%s->%s max_concurrency must be non-negative", svc, c.Target)
+			}
+			if c.CBCounts != "" && c.CBCounts != rules.CBCountsPerCall && c.CBCounts != rules.CBCountsPerAttempt {
+				return nil, fmt.Errorf("%s->%s invalid cb_counts %q (want %s or %s)", svc, c.Target, c.CBCounts, rules.CBCountsPerCall, rules.CBCountsPerAttempt)
+			}
+			if c.CBFailureThreshold < 0 {
+				return nil, fmt.Errorf("%s->%s cb_failure_threshold must be non-negative", svc, c.Target)
+			}
+			if c.CBWindow != "" && c.CBWindow != rules.CBWindowPerRequest && c.CBWindow != rules.CBWindowShared {
+				return nil, fmt.Errorf("%s->%s invalid cb_window %q (want %s or %s)", svc, c.Target, c.CBWindow, rules.CBWindowPerRequest, rules.CBWindowShared)
+			}
+			if _, ok := criticalityRank[c.Criticality]; !ok {
+				return nil, fmt.Errorf("%s->%s invalid criticality %q (want low, medium or high)", svc, c.Target, c.Criticality)
+			}
+			if c.Confidence < 0 || c.Confidence > 1 {
+				return nil, fmt.Errorf("%s->%s confidence must be between 0 and 1", svc, c.Target)
+			}
+			if c.CallProbability < 0 || c.CallProbability > 1 {
+				return nil, fmt.Errorf("%s->%s call_probability must be between 0 and 1", svc, c.Target)
+			}
+			if c.FailureProbability < 0 || c.FailureProbability > 1 {
+				return nil, fmt.Errorf("%s->%s failure_probability must be between 0 and 1", svc, c.Target)
+			}
+			m := c.Method
+			if m == "" {
+				m = "GET"
+			}
+			target := parser.ResolveAlias(aliases, c.Target)
+			var readsFrom []string
+			for _, r := range c.ReadsFrom {
+				readsFrom = append(readsFrom, parser.ResolveAlias(aliases, r))
+			}
+			fn(CallEdge{Source: svc, Target: target,
+				Timeout: t, Retries: c.Retries, CircuitBreaker: c.CircuitBreaker,
+				CBHalfOpenRequests: c.CBHalfOpenRequests, CBOpenTimeout: cbOpen, CBCounts: c.CBCounts,
+				CBFailureThreshold: c.CBFailureThreshold, CBWindow: c.CBWindow,
+				Method: m, Endpoint: c.Endpoint, Kind: c.Kind, Idempotent: c.Idempotent, BackoffJitter: c.BackoffJitter,
+				BackoffBase: backoff, ProcessingTime: processing, P99: p99, ReadsFrom: readsFrom,
+				Secure: c.Secure, CrossesBoundary: c.CrossesBoundary, MaxConcurrency: c.MaxConcurrency,
+				Async: c.Async, InTransaction: c.InTransaction, Confidence: c.Confidence,
+				Label: c.Label, Criticality: c.Criticality, HonorsRetryAfter: c.HonorsRetryAfter, Observable: c.Observable,
+				CallProbability: c.CallProbability, FailureProbability: c.FailureProbability})
+		}
+	}
+	return services, nil
+}
+
+// tierPolicy returns the topology's tier policy, or the zero policy (rule
+// default) when none is declared.
+func tierPolicy(topo *parser.RawTopology) rules.TierPolicy {
+	if p := topo.TierPolicy; p != nil {
+		return rules.TierPolicy{Allow: p.Allow, RequireCircuitBreaker: p.RequireCircuitBreaker}
+	}
+	return rules.TierPolicy{}
+}